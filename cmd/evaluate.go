@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/sirupsen/logrus"
+)
+
+// runEvaluate 离线评估子命令：切分行为日志，训练各算法引擎并输出评估指标
+func runEvaluate(args []string) int {
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	defaults := evaluation.DefaultConfig()
+
+	behaviorsPath := flags.String("behaviors", "", "行为日志文件（JSON Lines），必填")
+	itemsPath := flags.String("items", "", "物品数据文件（JSON Lines），可选")
+	split := flags.String("split", string(defaults.Split.Method), "切分方式：random、temporal、leave_one_out")
+	testRatio := flags.Float64("test-ratio", defaults.Split.TestRatio, "随机与按时间切分时测试集的比例")
+	seed := flags.Int64("seed", defaults.Split.Seed, "随机切分的随机种子")
+	k := flags.Int("k", defaults.K, "推荐列表长度")
+	algorithms := flags.String("algorithms", "", "逗号分隔的算法列表，为空时评估所有注册的算法")
+	maxUsers := flags.Int("max-users", 0, "测试用户上限，0表示不限制")
+	format := flags.String("format", "table", "输出格式：table、json")
+	output := flags.String("output", "", "输出文件，为空时输出到标准输出")
+	verbose := flags.Bool("verbose", false, "输出训练与评估日志")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *behaviorsPath == "" || (*format != "table" && *format != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	if !*verbose {
+		log.SetLevel(logrus.ErrorLevel)
+	}
+
+	dataset, err := loadEvaluationDataset(*behaviorsPath, *itemsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载评估数据失败: %v\n", err)
+		return 1
+	}
+
+	config := evaluation.Config{
+		Split: evaluation.SplitConfig{
+			Method:    evaluation.SplitMethod(*split),
+			TestRatio: *testRatio,
+			Seed:      *seed,
+		},
+		K:        *k,
+		MaxUsers: *maxUsers,
+	}
+	for _, name := range strings.Split(*algorithms, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.Algorithms = append(config.Algorithms, recommendation.AlgorithmType(name))
+		}
+	}
+
+	results, err := evaluation.NewEvaluator(config, nil, log).Evaluate(context.Background(), dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "离线评估失败: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		err = evaluation.WriteJSON(w, results)
+	} else {
+		err = evaluation.WriteTable(w, results)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// loadEvaluationDataset 读取行为日志与物品数据
+func loadEvaluationDataset(behaviorsPath string, itemsPath string) (evaluation.Dataset, error) {
+	var dataset evaluation.Dataset
+
+	file, err := os.Open(behaviorsPath)
+	if err != nil {
+		return dataset, err
+	}
+	defer file.Close()
+	if dataset.Behaviors, err = evaluation.LoadBehaviors(file); err != nil {
+		return dataset, err
+	}
+
+	if itemsPath == "" {
+		return dataset, nil
+	}
+	items, err := os.Open(itemsPath)
+	if err != nil {
+		return dataset, err
+	}
+	defer items.Close()
+	dataset.Items, err = evaluation.LoadItems(items)
+	return dataset, err
+}
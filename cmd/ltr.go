@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/infra/di"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
+	"github.com/sirupsen/logrus"
+)
+
+// runLTR 排序模型训练子命令：按时间切分行为日志，用训练集训练召回算法，
+// 以混合过滤与热度召回的候选为查询、测试集中的正反馈为标签训练排序模型
+func runLTR(args []string) int {
+	flags := flag.NewFlagSet("ltr", flag.ContinueOnError)
+	defaults := strategy.DefaultLTRConfig()
+
+	behaviorsPath := flags.String("behaviors", "", "行为日志文件（JSON Lines），必填")
+	itemsPath := flags.String("items", "", "物品数据文件（JSON Lines），可选")
+	model := flags.String("model", string(defaults.Model), "排序模型：logistic、lambdamart")
+	candidates := flags.Int("candidates", 50, "每个召回算法为每个用户召回的候选数")
+	testRatio := flags.Float64("test-ratio", 0.2, "按时间切分出的标签行为比例")
+	validation := flags.Float64("validation", 0.2, "留作验证的查询比例")
+	trees := flags.Int("trees", defaults.Trees, "梯度提升树的棵数")
+	depth := flags.Int("depth", defaults.MaxDepth, "回归树的最大深度")
+	minLeaf := flags.Int("min-leaf", defaults.MinLeafSamples, "叶子节点的最少样本数")
+	iterations := flags.Int("iterations", defaults.Iterations, "逻辑回归的梯度下降轮数")
+	learningRate := flags.Float64("learning-rate", defaults.LearningRate, "逻辑回归的步长与梯度提升的收缩系数")
+	k := flags.Int("k", 10, "验证 NDCG 的截断位置")
+	seed := flags.Int64("seed", 42, "划分验证查询的随机种子")
+	output := flags.String("output", "", "排序模型 JSON 文件，可通过 recommendation.ranking.ltr_model 在启动时加载")
+	verbose := flags.Bool("verbose", false, "输出训练日志")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *behaviorsPath == "" {
+		flags.Usage()
+		return 2
+	}
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	if !*verbose {
+		log.SetLevel(logrus.ErrorLevel)
+	}
+
+	dataset, err := loadEvaluationDataset(*behaviorsPath, *itemsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载训练数据失败: %v\n", err)
+		return 1
+	}
+	train, test, err := evaluation.Split(dataset.Behaviors, evaluation.SplitConfig{Method: evaluation.SplitTemporal, TestRatio: *testRatio})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "切分行为日志失败: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	manager := recommendation.NewRecommendationEngineManager(log)
+	if err := manager.UpdateModel(ctx, recommendation.ModelUpdate{Behaviors: train, Items: dataset.Items, Users: dataset.Users}); err != nil {
+		log.WithError(err).Warn("部分算法引擎训练失败")
+	}
+	engine, exists := manager.GetEngine(recommendation.AlgorithmHybridFiltering)
+	hybrid, ok := engine.(*recommendation.HybridFilteringAdapter)
+	if !exists || !ok {
+		fmt.Fprintln(os.Stderr, "混合过滤引擎不可用，无法构造排序特征")
+		return 1
+	}
+
+	queries := buildLTRQueries(ctx, manager, train, test, *candidates)
+	if len(queries) == 0 {
+		fmt.Fprintln(os.Stderr, "没有召回到正反馈物品的用户，无法构造训练样本")
+		return 1
+	}
+	rand.New(rand.NewSource(*seed)).Shuffle(len(queries), func(i, j int) { queries[i], queries[j] = queries[j], queries[i] })
+	holdout := int(float64(len(queries)) * *validation)
+
+	trainSamples, err := strategy.BuildTrainingSamples(ctx, hybrid, queries[holdout:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	validationSamples, err := strategy.BuildTrainingSamples(ctx, hybrid, queries[:holdout])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	config := strategy.LTRConfig{
+		Model:          strategy.ModelType(*model),
+		Iterations:     *iterations,
+		Trees:          *trees,
+		MaxDepth:       *depth,
+		MinLeafSamples: *minLeaf,
+		LearningRate:   *learningRate,
+		L2:             defaults.L2,
+	}
+	ranker, err := strategy.TrainRankingModel(trainSamples, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "训练排序模型失败: %v\n", err)
+		return 1
+	}
+
+	// 以混合过滤得分排序作为基线
+	baseline := func(features []float64) float64 { return features[0] }
+	fmt.Printf("queries: train %d, validation %d; samples: train %d, validation %d\n",
+		len(queries)-holdout, holdout, len(trainSamples), len(validationSamples))
+	fmt.Printf("ndcg@%d  train: %.4f (hybrid %.4f)  validation: %.4f (hybrid %.4f)\n", *k,
+		strategy.EvaluateNDCG(trainSamples, ranker.Score, *k),
+		strategy.EvaluateNDCG(trainSamples, baseline, *k),
+		strategy.EvaluateNDCG(validationSamples, ranker.Score, *k),
+		strategy.EvaluateNDCG(validationSamples, baseline, *k),
+	)
+
+	if *output == "" {
+		return 0
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+	if err := strategy.WriteRankingModel(file, ranker); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// buildLTRQueries 为测试集中有正反馈的用户构造查询：候选为混合过滤与热度召回的并集，
+// 测试集中的正反馈且不在训练集中的物品相关度为1，没有召回到相关物品的用户不参与训练
+func buildLTRQueries(ctx context.Context, manager *recommendation.RecommendationEngineManager, train []datacollection.UserBehavior, test []datacollection.UserBehavior, limit int) []strategy.TrainingQuery {
+	seen := make(map[string]map[string]bool)
+	for _, behavior := range train {
+		if seen[behavior.UserID] == nil {
+			seen[behavior.UserID] = make(map[string]bool)
+		}
+		seen[behavior.UserID][behavior.ItemID] = true
+	}
+	relevance := make(map[string]map[string]float64)
+	for _, behavior := range test {
+		feedback, err := recommendation.ParseFeedback(behavior.UserID, behavior.ItemID, behavior)
+		if err != nil || feedback.Rating() <= 0 || seen[behavior.UserID][behavior.ItemID] {
+			continue
+		}
+		if relevance[behavior.UserID] == nil {
+			relevance[behavior.UserID] = make(map[string]float64)
+		}
+		relevance[behavior.UserID][behavior.ItemID] = 1
+	}
+
+	users := make([]string, 0, len(relevance))
+	for userID := range relevance {
+		users = append(users, userID)
+	}
+	sort.Strings(users)
+
+	queries := make([]strategy.TrainingQuery, 0, len(users))
+	for _, userID := range users {
+		candidates := make([]string, 0, 2*limit)
+		included := make(map[string]bool, 2*limit)
+		hits := 0
+		for _, algorithm := range []recommendation.AlgorithmType{recommendation.AlgorithmHybridFiltering, recommendation.AlgorithmPopularity} {
+			engine, exists := manager.GetEngine(algorithm)
+			if !exists {
+				continue
+			}
+			response, err := engine.Recommend(ctx, recommendation.RecommendationRequest{UserID: userID, Algorithm: algorithm, Limit: limit})
+			if err != nil {
+				continue
+			}
+			for _, result := range response.Recommendations {
+				if included[result.ItemID] || seen[userID][result.ItemID] {
+					continue
+				}
+				included[result.ItemID] = true
+				candidates = append(candidates, result.ItemID)
+				if relevance[userID][result.ItemID] > 0 {
+					hits++
+				}
+			}
+		}
+		if hits > 0 && hits < len(candidates) {
+			queries = append(queries, strategy.TrainingQuery{UserID: userID, Candidates: candidates, Relevance: relevance[userID]})
+		}
+	}
+	return queries
+}
+
+// loadRankingModel 从配置文件读取排序模型，作为推荐流水线的第一个排序策略，未配置时不启用
+func loadRankingModel(app *di.Application) error {
+	path := app.ConfigManager.GetString("recommendation.ranking.ltr_model")
+	if path == "" {
+		return nil
+	}
+
+	pipeline, ok := app.RecommendationSvc.(*recommendation.SimpleRecommendationEngine)
+	if !ok {
+		return fmt.Errorf("推荐服务不支持排序策略")
+	}
+	hybrid, ok := hybridAdapter(app)
+	if !ok {
+		return fmt.Errorf("混合过滤引擎不可用，无法构造排序特征")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	model, err := strategy.ReadRankingModel(file)
+	if err != nil {
+		return err
+	}
+
+	strategies := append([]strategy.RankingStrategy{strategy.NewLTRStrategy(model, hybrid)}, pipeline.GetStrategies()...)
+	pipeline.SetStrategies(strategies)
+	app.Logger.WithFields(logrus.Fields{
+		"path":  path,
+		"model": model.Type(),
+	}).Info("学习排序策略已启用")
+	return nil
+}
@@ -2,17 +2,46 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"github.com/guanguoyintao/luban/internal/api/grpcapi"
+	"github.com/guanguoyintao/luban/internal/api/httpapi"
 	"github.com/guanguoyintao/luban/internal/infra/di"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/bandit"
+	"github.com/guanguoyintao/luban/internal/recommendation/diversity"
+	"github.com/guanguoyintao/luban/internal/recommendation/experiment"
+	"github.com/guanguoyintao/luban/internal/recommendation/exposurelog"
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
+	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	// 子命令：evaluate 离线评估推荐算法
+	if len(os.Args) > 1 && os.Args[1] == "evaluate" {
+		os.Exit(runEvaluate(os.Args[2:]))
+	}
+	// 子命令：tune 搜索算法超参数
+	if len(os.Args) > 1 && os.Args[1] == "tune" {
+		os.Exit(runTune(os.Args[2:]))
+	}
+	// 子命令：replay 基于曝光日志的反事实评估
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		os.Exit(runReplay(os.Args[2:]))
+	}
+	// 子命令：ltr 训练学习排序模型
+	if len(os.Args) > 1 && os.Args[1] == "ltr" {
+		os.Exit(runLTR(os.Args[2:]))
+	}
+
 	fmt.Println("推荐系统框架已启动")
 
 	// 使用Wire初始化应用程序
@@ -48,6 +77,7 @@ func main() {
 	}
 
 	// 优雅关闭
+	cancel()
 	shutdownApp(app)
 	fmt.Println("应用程序已关闭")
 }
@@ -74,27 +104,549 @@ func startApp(ctx context.Context, app *di.Application) error {
 	// 插件管理器将在后续版本中实现
 	app.Logger.Info("插件系统准备就绪")
 
+	// 加载推荐规则
+	if err := loadRecommendationRules(ctx, app); err != nil {
+		return fmt.Errorf("加载推荐规则失败: %w", err)
+	}
+
+	// 加载冷启动配置
+	if err := loadColdStartConfig(ctx, app); err != nil {
+		return fmt.Errorf("加载冷启动配置失败: %w", err)
+	}
+
+	// 加载探索策略
+	if err := loadExplorationConfig(app); err != nil {
+		return fmt.Errorf("加载探索策略失败: %w", err)
+	}
+
+	// 加载A/B实验
+	if err := loadExperimentConfig(app); err != nil {
+		return fmt.Errorf("加载A/B实验失败: %w", err)
+	}
+
+	// 加载交错对比
+	if err := loadInterleavingConfig(app); err != nil {
+		return fmt.Errorf("加载交错对比失败: %w", err)
+	}
+
+	// 打开曝光日志
+	if err := loadExposureLog(app); err != nil {
+		return fmt.Errorf("打开曝光日志失败: %w", err)
+	}
+
+	// 加载混合过滤配置与学到的混合权重
+	if err := loadHybridConfig(ctx, app); err != nil {
+		return fmt.Errorf("加载混合过滤配置失败: %w", err)
+	}
+
+	// 应用超参数搜索得到的最优参数
+	if err := loadTunedParameters(ctx, app); err != nil {
+		return fmt.Errorf("加载超参数搜索结果失败: %w", err)
+	}
+
+	// 用数据源中的全量数据训练算法引擎
+	trainEngines(ctx, app)
+
+	// 加载推荐流水线配置
+	if err := loadPipelineConfig(app); err != nil {
+		return fmt.Errorf("加载推荐流水线配置失败: %w", err)
+	}
+
+	// 加载多样性重排配置
+	if err := loadDiversityConfig(app); err != nil {
+		return fmt.Errorf("加载多样性重排配置失败: %w", err)
+	}
+
+	// 加载学习排序模型
+	if err := loadRankingModel(app); err != nil {
+		return fmt.Errorf("加载排序模型失败: %w", err)
+	}
+
 	// 启动推荐服务
+	app.HTTPServer.SetConfig(loadServerConfig(app))
+	app.GRPCServer.SetConfig(loadGRPCServerConfig(app))
 	app.Logger.Info("推荐系统框架启动完成，等待请求...")
 
-	// 模拟服务运行
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	errChan := make(chan error, 2)
+	go func() {
+		if err := app.HTTPServer.Start(); err != nil {
+			errChan <- fmt.Errorf("HTTP服务运行失败: %w", err)
+		}
+	}()
+	go func() {
+		if err := app.GRPCServer.Start(); err != nil {
+			errChan <- fmt.Errorf("gRPC服务运行失败: %w", err)
+		}
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// loadServerConfig 从配置文件读取HTTP服务配置
+func loadServerConfig(app *di.Application) *httpapi.ServerConfig {
+	serverConfig := httpapi.DefaultServerConfig()
+
+	if port := app.ConfigManager.GetInt("server.port"); port > 0 {
+		serverConfig.Addr = fmt.Sprintf("%s:%d", app.ConfigManager.GetString("server.host"), port)
+	}
+	if timeout := app.ConfigManager.GetDuration("server.read_timeout"); timeout > 0 {
+		serverConfig.ReadTimeout = timeout
+	}
+	if timeout := app.ConfigManager.GetDuration("server.write_timeout"); timeout > 0 {
+		serverConfig.WriteTimeout = timeout
+	}
+	if timeout := app.ConfigManager.GetDuration("server.shutdown_timeout"); timeout > 0 {
+		serverConfig.ShutdownTimeout = timeout
+	}
+
+	return serverConfig
+}
+
+// loadGRPCServerConfig 从配置文件读取gRPC服务配置
+func loadGRPCServerConfig(app *di.Application) *grpcapi.ServerConfig {
+	serverConfig := grpcapi.DefaultServerConfig()
+
+	if port := app.ConfigManager.GetInt("grpc.port"); port > 0 {
+		serverConfig.Addr = fmt.Sprintf("%s:%d", app.ConfigManager.GetString("grpc.host"), port)
+	}
+	if timeout := app.ConfigManager.GetDuration("grpc.shutdown_timeout"); timeout > 0 {
+		serverConfig.ShutdownTimeout = timeout
+	}
+
+	return serverConfig
+}
+
+// loadRecommendationRules 从配置文件读取规则推荐引擎的规则
+func loadRecommendationRules(ctx context.Context, app *di.Application) error {
+	rules := app.ConfigManager.Get("recommendation.rules")
+	if rules == nil {
+		return nil
+	}
+
+	return app.RecommendationEngine.SetAlgorithmParameters(ctx, recommendation.AlgorithmRuleBased, map[string]interface{}{
+		"rules": rules,
+	})
+}
 
-	for {
-		select {
-		case <-ctx.Done():
+// loadColdStartConfig 从配置文件读取冷启动引擎参数，例如预热阈值 warm_up_threshold
+func loadColdStartConfig(ctx context.Context, app *di.Application) error {
+	parameters := app.ConfigManager.GetStringMap("recommendation.cold_start")
+	if len(parameters) == 0 {
+		return nil
+	}
+
+	return app.RecommendationEngine.SetAlgorithmParameters(ctx, recommendation.AlgorithmColdStart, parameters)
+}
+
+// loadExplorationConfig 从配置文件读取探索策略，未配置策略时不探索
+func loadExplorationConfig(app *di.Application) error {
+	policyType := app.ConfigManager.GetString("recommendation.exploration.policy")
+	if policyType == "" {
+		return nil
+	}
+
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return fmt.Errorf("推荐引擎不支持探索策略")
+	}
+
+	policyConfig := bandit.DefaultConfig(bandit.PolicyType(policyType))
+	if epsilon := app.ConfigManager.GetFloat64("recommendation.exploration.epsilon"); epsilon > 0 {
+		policyConfig.Epsilon = epsilon
+	}
+	if scale := app.ConfigManager.GetFloat64("recommendation.exploration.ucb_scale"); scale > 0 {
+		policyConfig.UCBScale = scale
+	}
+	if alpha := app.ConfigManager.GetFloat64("recommendation.exploration.alpha"); alpha > 0 {
+		policyConfig.Alpha = alpha
+	}
+	if weight := app.ConfigManager.GetFloat64("recommendation.exploration.prior_weight"); weight > 0 {
+		policyConfig.PriorWeight = weight
+	}
+
+	policy, err := bandit.NewPolicy(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	explorerConfig := bandit.DefaultExplorerConfig()
+	if ttl := app.ConfigManager.GetDuration("recommendation.exploration.impression_ttl"); ttl > 0 {
+		explorerConfig.ImpressionTTL = ttl
+	}
+	manager.SetExplorer(bandit.NewExplorer(policy, policyConfig.Dimension, explorerConfig))
+	app.Logger.WithField("policy", policyType).Info("探索策略已启用")
+	return nil
+}
+
+// loadExperimentConfig 从配置文件读取A/B实验，未配置实验时不分流
+func loadExperimentConfig(app *di.Application) error {
+	var tests []models.ABTest
+	if raw := app.ConfigManager.Get("recommendation.experiments.tests"); raw != nil {
+		// 配置中的实验按 models.ABTest 的 JSON 字段解析
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &tests); err != nil {
+			return err
+		}
+	}
+	if len(tests) == 0 {
+		return nil
+	}
+
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return fmt.Errorf("推荐引擎不支持A/B实验")
+	}
+
+	config := experiment.DefaultConfig()
+	if method := app.ConfigManager.GetString("recommendation.experiments.method"); method != "" {
+		config.Method = experiment.TestMethod(method)
+	}
+	if alpha := app.ConfigManager.GetFloat64("recommendation.experiments.alpha"); alpha > 0 {
+		config.Alpha = alpha
+	}
+	if samples := app.ConfigManager.GetInt("recommendation.experiments.min_samples"); samples > 0 {
+		config.MinSamples = samples
+	}
+	if window := app.ConfigManager.GetDuration("recommendation.experiments.attribution_window"); window > 0 {
+		config.AttributionWindow = window
+	}
+	config.AutoComplete = app.ConfigManager.GetBool("recommendation.experiments.auto_complete")
+
+	var logger experiment.ExposureLogger
+	if path := app.ConfigManager.GetString("recommendation.experiments.exposure_log"); path != "" {
+		file, err := openLogFile(app, path)
+		if err != nil {
+			return fmt.Errorf("打开曝光日志失败: %w", err)
+		}
+		logger = experiment.NewJSONExposureLogger(file)
+	}
+
+	runner := experiment.NewRunner(config, logger)
+	for _, test := range tests {
+		if err := runner.AddTest(test); err != nil {
+			return err
+		}
+	}
+	manager.SetExperiments(runner)
+	app.Logger.WithField("experiments", len(tests)).Info("A/B实验已启用")
+	return nil
+}
+
+// loadInterleavingConfig 从配置文件读取交错对比的两个算法，未配置时不交错
+func loadInterleavingConfig(app *di.Application) error {
+	algorithms := app.ConfigManager.GetStringSlice("recommendation.interleaving.algorithms")
+	if len(algorithms) == 0 {
+		return nil
+	}
+	if len(algorithms) != 2 {
+		return fmt.Errorf("交错对比需要两个算法: %v", algorithms)
+	}
+
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return fmt.Errorf("推荐引擎不支持交错对比")
+	}
+
+	config := experiment.DefaultInterleavingConfig()
+	config.ID = app.ConfigManager.GetString("recommendation.interleaving.id")
+	config.Algorithms = [2]string{algorithms[0], algorithms[1]}
+	config.Scenario = app.ConfigManager.GetString("recommendation.interleaving.scenario")
+	if traffic := app.ConfigManager.Get("recommendation.interleaving.traffic"); traffic != nil {
+		config.Traffic = app.ConfigManager.GetFloat64("recommendation.interleaving.traffic")
+	}
+	if window := app.ConfigManager.GetDuration("recommendation.interleaving.attribution_window"); window > 0 {
+		config.AttributionWindow = window
+	}
+	if alpha := app.ConfigManager.GetFloat64("recommendation.interleaving.alpha"); alpha > 0 {
+		config.Alpha = alpha
+	}
+	if comparisons := app.ConfigManager.GetInt("recommendation.interleaving.min_comparisons"); comparisons > 0 {
+		config.MinComparisons = comparisons
+	}
+
+	interleaving, err := experiment.NewInterleaving(config)
+	if err != nil {
+		return err
+	}
+	manager.SetInterleaving(interleaving)
+	app.Logger.WithField("algorithms", algorithms).Info("交错对比已启用")
+	return nil
+}
+
+// loadExposureLog 从配置文件读取曝光日志路径，记录每次推荐的候选、展示位置与反馈，未配置时不记录
+func loadExposureLog(app *di.Application) error {
+	path := app.ConfigManager.GetString("recommendation.exposure_log.path")
+	if path == "" {
+		return nil
+	}
+
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return fmt.Errorf("推荐引擎不支持曝光日志")
+	}
+	file, err := openLogFile(app, path)
+	if err != nil {
+		return err
+	}
+	manager.SetExposureLog(exposurelog.NewWriter(file))
+	app.Logger.WithField("path", path).Info("曝光日志已启用")
+	return nil
+}
+
+// trainEngines 用召回数据源的全量数据训练算法引擎，部分引擎训练失败时记录警告，服务照常启动
+func trainEngines(ctx context.Context, app *di.Application) {
+	pipeline, ok := app.RecommendationSvc.(*recommendation.SimpleRecommendationEngine)
+	if !ok {
+		return
+	}
+	behaviors, err := pipeline.TrainFromDataSource(ctx)
+	if err != nil {
+		app.Logger.WithError(err).Warn("部分算法引擎训练失败")
+	}
+	app.Logger.WithField("behaviors", behaviors).Info("已使用数据源训练算法引擎")
+}
+
+// loadPipelineConfig 从配置文件读取推荐流水线的召回类型、打分算法与算法融合权重，未配置的项保持默认值
+func loadPipelineConfig(app *di.Application) error {
+	recallTypes := app.ConfigManager.GetStringSlice("recommendation.pipeline.recall_types")
+	algorithms := app.ConfigManager.GetStringSlice("recommendation.pipeline.algorithms")
+	weights := app.ConfigManager.GetStringMap("recommendation.pipeline.algorithm_weights")
+	if len(recallTypes) == 0 && len(algorithms) == 0 && len(weights) == 0 {
+		return nil
+	}
+
+	pipeline, ok := app.RecommendationSvc.(*recommendation.SimpleRecommendationEngine)
+	if !ok {
+		return fmt.Errorf("推荐服务不支持流水线配置")
+	}
+	config := *pipeline.GetConfig()
+	if len(recallTypes) > 0 {
+		config.RecallTypes = recallTypes
+	}
+	if len(algorithms) > 0 {
+		config.Algorithms = make([]recommendation.AlgorithmType, len(algorithms))
+		for i, algorithm := range algorithms {
+			config.Algorithms[i] = recommendation.AlgorithmType(algorithm)
+		}
+	}
+	if len(weights) > 0 {
+		config.AlgorithmWeights = make(map[recommendation.AlgorithmType]float64, len(weights))
+		for algorithm, value := range weights {
+			var weight float64
+			switch v := value.(type) {
+			case float64:
+				weight = v
+			case int:
+				weight = float64(v)
+			default:
+				return fmt.Errorf("算法 %s 的融合权重无效: %v", algorithm, value)
+			}
+			if weight < 0 {
+				return fmt.Errorf("算法 %s 的融合权重不能为负数: %v", algorithm, value)
+			}
+			config.AlgorithmWeights[recommendation.AlgorithmType(algorithm)] = weight
+		}
+	}
+	pipeline.SetConfig(&config)
+	app.Logger.WithFields(logrus.Fields{
+		"recall_types":      config.RecallTypes,
+		"algorithms":        config.Algorithms,
+		"algorithm_weights": config.AlgorithmWeights,
+	}).Info("推荐流水线配置已加载")
+	return nil
+}
+
+// loadDiversityConfig 从配置文件读取多样性重排方法与权衡系数，以内容特征向量替换推荐流水线的多样性策略，
+// 未配置方法时保持默认的按类别重排
+func loadDiversityConfig(app *di.Application) error {
+	method := diversity.Method(app.ConfigManager.GetString("recommendation.ranking.diversity.method"))
+	if method == "" {
+		return nil
+	}
+	config := diversity.DefaultConfig()
+	config.Method = method
+	if app.ConfigManager.Get("recommendation.ranking.diversity.lambda") != nil {
+		config.Lambda = app.ConfigManager.GetFloat64("recommendation.ranking.diversity.lambda")
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	pipeline, ok := app.RecommendationSvc.(*recommendation.SimpleRecommendationEngine)
+	if !ok {
+		return fmt.Errorf("推荐服务不支持排序策略")
+	}
+	var provider strategy.ItemVectorProvider
+	if manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager); ok {
+		engine, _ := manager.GetEngine(recommendation.AlgorithmContentBasedFiltering)
+		if contentBased, ok := engine.(*recommendation.ContentBasedFilteringAdapter); ok {
+			provider = contentBased
+		}
+	}
+
+	diversified := strategy.NewMMRStrategy(config.Lambda, provider)
+	if method == diversity.MethodDPP {
+		diversified = strategy.NewDPPStrategy(config.Lambda, provider)
+	}
+	diversified.SetLogger(app.Logger)
+	strategies := pipeline.GetStrategies()
+	replaced := make([]strategy.RankingStrategy, 0, len(strategies)+1)
+	found := false
+	for _, rankingStrategy := range strategies {
+		if _, ok := rankingStrategy.(*strategy.DiversityStrategy); ok {
+			rankingStrategy = diversified
+			found = true
+		}
+		replaced = append(replaced, rankingStrategy)
+	}
+	if !found {
+		replaced = append(replaced, diversified)
+	}
+	pipeline.SetStrategies(replaced)
+	app.Logger.WithFields(logrus.Fields{
+		"method": method,
+		"lambda": config.Lambda,
+	}).Info("多样性重排已启用")
+	return nil
+}
+
+// reportExperiments 关闭前输出各实验与交错对比的分析结果
+func reportExperiments(app *di.Application) {
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return
+	}
+	if interleaving := manager.GetInterleaving(); interleaving != nil {
+		result := interleaving.Result()
+		app.Logger.WithFields(logrus.Fields{
+			"interleaving_id": result.ID,
+			"wins":            result.Wins,
+			"ties":            result.Ties,
+			"preference":      result.Preference,
+			"p_value":         result.PValue,
+			"winner":          result.Winner,
+		}).Info("交错对比结果")
+	}
+	if manager.GetExperiments() == nil {
+		return
+	}
+	runner := manager.GetExperiments()
+	for _, test := range runner.Tests() {
+		analysis, err := runner.Analyze(test.ID)
+		if err != nil {
+			app.Logger.WithError(err).WithField("experiment_id", test.ID).Error("分析A/B实验失败")
+			continue
+		}
+		app.Logger.WithFields(logrus.Fields{
+			"experiment_id": analysis.ExperimentID,
+			"arms":          analysis.Arms,
+			"comparisons":   analysis.Comparisons,
+			"winner":        analysis.Winner,
+		}).Info("A/B实验分析结果")
+	}
+}
+
+// loadHybridConfig 从配置文件读取混合过滤引擎参数，并加载上次持久化的场景混合权重
+func loadHybridConfig(ctx context.Context, app *di.Application) error {
+	if parameters := app.ConfigManager.GetStringMap("recommendation.hybrid.parameters"); len(parameters) > 0 {
+		if err := app.RecommendationEngine.SetAlgorithmParameters(ctx, recommendation.AlgorithmHybridFiltering, parameters); err != nil {
+			return err
+		}
+	}
+
+	path := app.ConfigManager.GetString("recommendation.hybrid.blend_weights_path")
+	hybrid, ok := hybridAdapter(app)
+	if path == "" || !ok {
+		return nil
+	}
+	if err := hybrid.LoadBlendWeights(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil
-		case <-ticker.C:
-			app.Logger.Debug("推荐系统运行中...")
+		}
+		return err
+	}
+	app.Logger.WithField("path", path).Info("已加载场景混合权重")
+	return nil
+}
+
+// saveBlendWeights 持久化学到的场景混合权重
+func saveBlendWeights(app *di.Application) {
+	path := app.ConfigManager.GetString("recommendation.hybrid.blend_weights_path")
+	hybrid, ok := hybridAdapter(app)
+	if path == "" || !ok {
+		return
+	}
+	if err := hybrid.SaveBlendWeights(path); err != nil {
+		app.Logger.WithError(err).Error("保存场景混合权重失败")
+	}
+}
+
+// hybridAdapter 获取混合过滤引擎适配器
+func hybridAdapter(app *di.Application) (*recommendation.HybridFilteringAdapter, bool) {
+	manager, ok := app.RecommendationEngine.(*recommendation.RecommendationEngineManager)
+	if !ok {
+		return nil, false
+	}
+	engine, exists := manager.GetEngine(recommendation.AlgorithmHybridFiltering)
+	if !exists {
+		return nil, false
+	}
+	hybrid, ok := engine.(*recommendation.HybridFilteringAdapter)
+	return hybrid, ok
+}
+
+// openLogFile 以追加方式打开日志文件，关闭应用时由 closeLogFiles 同步并关闭
+func openLogFile(app *di.Application, path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	app.LogFiles = append(app.LogFiles, file)
+	return file, nil
+}
+
+// closeLogFiles 同步并关闭运行期间打开的日志文件，需在服务停止接收请求后调用
+func closeLogFiles(app *di.Application) {
+	for _, file := range app.LogFiles {
+		if err := file.Sync(); err != nil {
+			app.Logger.WithError(err).WithField("path", file.Name()).Error("同步日志文件失败")
+		}
+		if err := file.Close(); err != nil {
+			app.Logger.WithError(err).WithField("path", file.Name()).Error("关闭日志文件失败")
 		}
 	}
+	app.LogFiles = nil
 }
 
 // shutdownApp 关闭应用程序
 func shutdownApp(app *di.Application) {
 	app.Logger.Info("开始关闭应用程序")
 
+	// 关闭HTTP服务，等待进行中的请求完成
+	if err := app.HTTPServer.Shutdown(context.Background()); err != nil {
+		app.Logger.WithError(err).Error("HTTP服务关闭失败")
+	}
+	if err := app.GRPCServer.Shutdown(context.Background()); err != nil {
+		app.Logger.WithError(err).Error("gRPC服务关闭失败")
+	}
+
+	saveBlendWeights(app)
+	reportExperiments(app)
+
+	// 服务已停止接收请求，关闭推荐引擎，等待后台训练完成并停止定时任务
+	if err := app.RecommendationEngine.Close(); err != nil {
+		app.Logger.WithError(err).Error("推荐引擎关闭失败")
+	}
+	closeLogFiles(app)
+
 	// 插件关闭将在后续版本中实现
 	app.Logger.Info("插件系统关闭完成")
 
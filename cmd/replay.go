@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/guanguoyintao/luban/internal/recommendation/exposurelog"
+	"github.com/guanguoyintao/luban/internal/recommendation/offpolicy"
+	"github.com/sirupsen/logrus"
+)
+
+// runReplay 反事实评估子命令：读取曝光日志，输出记录策略的观测点击率与各算法的回放、IPS、SNIPS 估计
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	defaults := offpolicy.DefaultConfig()
+
+	exposuresPath := flags.String("exposures", "", "曝光日志文件（recommendation.exposure_log.path 写出的 JSON Lines），必填")
+	behaviorsPath := flags.String("behaviors", "", "训练目标算法的行为日志文件（JSON Lines），可选")
+	itemsPath := flags.String("items", "", "物品数据文件（JSON Lines），可选")
+	algorithms := flags.String("algorithms", "", "逗号分隔的目标算法列表，为空时评估所有注册的算法")
+	window := flags.Duration("window", defaults.AttributionWindow, "展示后该时间内的正反馈计为点击")
+	minPropensity := flags.Float64("min-propensity", defaults.MinPropensity, "前缀倾向性下限")
+	poolSize := flags.Int("pool-size", defaults.PoolSize, "目标算法重新推荐的物品数下限")
+	restrict := flags.Bool("restrict-candidates", defaults.RestrictToCandidates, "目标算法的列表只保留记录时的候选物品")
+	format := flags.String("format", "table", "输出格式：table、json")
+	output := flags.String("output", "", "输出文件，为空时输出到标准输出")
+	verbose := flags.Bool("verbose", false, "输出训练与评估日志")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *exposuresPath == "" || (*format != "table" && *format != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	if !*verbose {
+		log.SetLevel(logrus.ErrorLevel)
+	}
+
+	file, err := os.Open(*exposuresPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开曝光日志失败: %v\n", err)
+		return 1
+	}
+	entries, err := exposurelog.Load(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var dataset evaluation.Dataset
+	if *behaviorsPath != "" {
+		if dataset, err = loadEvaluationDataset(*behaviorsPath, *itemsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "加载训练数据失败: %v\n", err)
+			return 1
+		}
+	}
+
+	config := offpolicy.Config{
+		AttributionWindow:    *window,
+		RestrictToCandidates: *restrict,
+		MinPropensity:        *minPropensity,
+		PoolSize:             *poolSize,
+	}
+	for _, name := range strings.Split(*algorithms, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			config.Algorithms = append(config.Algorithms, recommendation.AlgorithmType(name))
+		}
+	}
+
+	report, err := offpolicy.NewEstimator(config, nil, log).Estimate(context.Background(), entries, dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "反事实评估失败: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		err = offpolicy.WriteJSON(w, report)
+	} else {
+		err = offpolicy.WriteTable(w, report)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/guanguoyintao/luban/internal/infra/di"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/guanguoyintao/luban/internal/recommendation/tuning"
+	"github.com/sirupsen/logrus"
+)
+
+// runTune 超参数搜索子命令：在离线评估指标上搜索算法参数，输出最优参数与全部试验记录
+func runTune(args []string) int {
+	flags := flag.NewFlagSet("tune", flag.ContinueOnError)
+	defaults := tuning.DefaultConfig()
+
+	behaviorsPath := flags.String("behaviors", "", "行为日志文件（JSON Lines），必填")
+	itemsPath := flags.String("items", "", "物品数据文件（JSON Lines），可选")
+	algorithm := flags.String("algorithm", "", "搜索参数的算法：collaborative_filtering、content_based_filtering、hybrid_filtering，必填")
+	method := flags.String("method", string(defaults.Method), "搜索方式：grid、random、bayesian")
+	trials := flags.Int("trials", defaults.Trials, "试验次数，网格搜索时为网格点数上限，0表示不限制")
+	objective := flags.String("objective", defaults.Objective, "最大化的指标：precision、recall、f1、ndcg、map、mrr、hit_rate、coverage、diversity、novelty")
+	gridPoints := flags.Int("grid-points", defaults.GridPoints, "网格搜索时每个连续参数的取值个数")
+	seed := flags.Int64("seed", defaults.Seed, "搜索与随机切分的随机种子")
+	split := flags.String("split", string(defaults.Evaluation.Split.Method), "切分方式：random、temporal、leave_one_out")
+	testRatio := flags.Float64("test-ratio", defaults.Evaluation.Split.TestRatio, "随机与按时间切分时测试集的比例")
+	k := flags.Int("k", defaults.Evaluation.K, "推荐列表长度")
+	maxUsers := flags.Int("max-users", 0, "测试用户上限，0表示不限制")
+	format := flags.String("format", "table", "输出格式：table、json")
+	output := flags.String("output", "", "搜索结果 JSON 文件，可通过 recommendation.tuning.results 在启动时应用")
+	verbose := flags.Bool("verbose", false, "输出训练与评估日志")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *behaviorsPath == "" || *algorithm == "" || (*format != "table" && *format != "json") {
+		flags.Usage()
+		return 2
+	}
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+	if !*verbose {
+		log.SetLevel(logrus.ErrorLevel)
+	}
+
+	dataset, err := loadEvaluationDataset(*behaviorsPath, *itemsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载评估数据失败: %v\n", err)
+		return 1
+	}
+
+	config := defaults
+	config.Algorithm = recommendation.AlgorithmType(*algorithm)
+	config.Method = tuning.SearchMethod(*method)
+	config.Trials = *trials
+	config.Objective = *objective
+	config.GridPoints = *gridPoints
+	config.Seed = *seed
+	config.Evaluation.Split.Method = evaluation.SplitMethod(*split)
+	config.Evaluation.Split.TestRatio = *testRatio
+	config.Evaluation.Split.Seed = *seed
+	config.Evaluation.K = *k
+	config.Evaluation.MaxUsers = *maxUsers
+
+	result, err := tuning.NewTuner(config, nil, log).Run(context.Background(), dataset)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "超参数搜索失败: %v\n", err)
+		return 1
+	}
+
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建输出文件失败: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		if err := tuning.WriteResult(file, result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if *format == "json" {
+		err = tuning.WriteResult(os.Stdout, result)
+	} else {
+		err = writeTuningTable(os.Stdout, result, *objective)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// writeTuningTable 以表格输出每次试验的目标值与参数，并标出最优试验
+func writeTuningTable(w io.Writer, result *tuning.Result, objective string) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "trial\tstatus\t%s\tduration_ms\tparameters\t\n", objective)
+	for _, trial := range result.Trials {
+		marker := ""
+		if trial.ID == result.Best.ID {
+			marker = " *"
+		}
+		fmt.Fprintf(table, "%v%s\t%s\t%v\t%d\t%v\t\n",
+			trial.Metadata["trial"],
+			marker,
+			trial.Status,
+			trial.Metrics[objective],
+			trial.TrainingTime,
+			trial.Hyperparameters,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("输出搜索结果失败: %w", err)
+	}
+	return nil
+}
+
+// loadTunedParameters 应用配置中列出的超参数搜索结果，每个文件的最优参数设置到对应算法
+func loadTunedParameters(ctx context.Context, app *di.Application) error {
+	for _, path := range app.ConfigManager.GetStringSlice("recommendation.tuning.results") {
+		file, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				app.Logger.WithField("path", path).Warn("超参数搜索结果不存在，跳过")
+				continue
+			}
+			return err
+		}
+		result, err := tuning.LoadResult(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := tuning.Apply(ctx, app.RecommendationEngine, *result.Best); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		app.Logger.WithFields(logrus.Fields{
+			"path":      path,
+			"algorithm": result.Best.Algorithm,
+			"trial":     result.Best.ID,
+		}).Info("已应用超参数搜索结果")
+	}
+	return nil
+}
@@ -0,0 +1,997 @@
+// 推荐引擎gRPC服务定义，与 recommendation.RecommendationEngine 接口一一对应
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: recommendation.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 推荐请求
+type RecommendRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 用户ID
+	Scenario      string                 `protobuf:"bytes,2,opt,name=scenario,proto3" json:"scenario,omitempty"`           // 推荐场景
+	Context       *structpb.Struct       `protobuf:"bytes,3,opt,name=context,proto3" json:"context,omitempty"`             // 上下文信息
+	Filters       *structpb.Struct       `protobuf:"bytes,4,opt,name=filters,proto3" json:"filters,omitempty"`             // 过滤条件
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                // 推荐数量限制
+	Algorithm     string                 `protobuf:"bytes,6,opt,name=algorithm,proto3" json:"algorithm,omitempty"`         // 指定算法类型
+	Parameters    *structpb.Struct       `protobuf:"bytes,7,opt,name=parameters,proto3" json:"parameters,omitempty"`       // 算法参数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendRequest) Reset() {
+	*x = RecommendRequest{}
+	mi := &file_recommendation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendRequest) ProtoMessage() {}
+
+func (x *RecommendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendRequest.ProtoReflect.Descriptor instead.
+func (*RecommendRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RecommendRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RecommendRequest) GetScenario() string {
+	if x != nil {
+		return x.Scenario
+	}
+	return ""
+}
+
+func (x *RecommendRequest) GetContext() *structpb.Struct {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *RecommendRequest) GetFilters() *structpb.Struct {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *RecommendRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *RecommendRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *RecommendRequest) GetParameters() *structpb.Struct {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+// 推荐结果
+type RecommendationResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        string                 `protobuf:"bytes,1,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"` // 物品ID
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`               // 推荐得分
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`               // 推荐理由
+	Algorithm     string                 `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`         // 使用的算法
+	Confidence    float64                `protobuf:"fixed64,5,opt,name=confidence,proto3" json:"confidence,omitempty"`     // 置信度
+	Metadata      *structpb.Struct       `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`           // 元数据
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendationResult) Reset() {
+	*x = RecommendationResult{}
+	mi := &file_recommendation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendationResult) ProtoMessage() {}
+
+func (x *RecommendationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendationResult.ProtoReflect.Descriptor instead.
+func (*RecommendationResult) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RecommendationResult) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *RecommendationResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RecommendationResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RecommendationResult) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *RecommendationResult) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *RecommendationResult) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// 推荐响应
+type RecommendResponse struct {
+	state            protoimpl.MessageState  `protogen:"open.v1"`
+	UserId           string                  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                                  // 用户ID
+	Recommendations  []*RecommendationResult `protobuf:"bytes,2,rep,name=recommendations,proto3" json:"recommendations,omitempty"`                              // 推荐结果列表
+	TotalCount       int32                   `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`                     // 总推荐数量
+	Algorithm        string                  `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`                                          // 实际使用的算法
+	ProcessingTimeMs int64                   `protobuf:"varint,5,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"` // 处理时间（毫秒）
+	Metadata         *structpb.Struct        `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`                                            // 元数据
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RecommendResponse) Reset() {
+	*x = RecommendResponse{}
+	mi := &file_recommendation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendResponse) ProtoMessage() {}
+
+func (x *RecommendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendResponse.ProtoReflect.Descriptor instead.
+func (*RecommendResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RecommendResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RecommendResponse) GetRecommendations() []*RecommendationResult {
+	if x != nil {
+		return x.Recommendations
+	}
+	return nil
+}
+
+func (x *RecommendResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *RecommendResponse) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *RecommendResponse) GetProcessingTimeMs() int64 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *RecommendResponse) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// 批量推荐请求
+type RecommendBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Requests      []*RecommendRequest    `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendBatchRequest) Reset() {
+	*x = RecommendBatchRequest{}
+	mi := &file_recommendation_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendBatchRequest) ProtoMessage() {}
+
+func (x *RecommendBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendBatchRequest.ProtoReflect.Descriptor instead.
+func (*RecommendBatchRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RecommendBatchRequest) GetRequests() []*RecommendRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// 批量推荐响应，顺序与请求一致
+type RecommendBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Responses     []*RecommendResponse   `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecommendBatchResponse) Reset() {
+	*x = RecommendBatchResponse{}
+	mi := &file_recommendation_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecommendBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecommendBatchResponse) ProtoMessage() {}
+
+func (x *RecommendBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecommendBatchResponse.ProtoReflect.Descriptor instead.
+func (*RecommendBatchResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RecommendBatchResponse) GetResponses() []*RecommendResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+// 推荐解释请求
+type ExplainRecommendationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ItemId        string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainRecommendationRequest) Reset() {
+	*x = ExplainRecommendationRequest{}
+	mi := &file_recommendation_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainRecommendationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainRecommendationRequest) ProtoMessage() {}
+
+func (x *ExplainRecommendationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainRecommendationRequest.ProtoReflect.Descriptor instead.
+func (*ExplainRecommendationRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExplainRecommendationRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ExplainRecommendationRequest) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+// 推荐解释响应
+type ExplainRecommendationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Explanation   string                 `protobuf:"bytes,1,opt,name=explanation,proto3" json:"explanation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExplainRecommendationResponse) Reset() {
+	*x = ExplainRecommendationResponse{}
+	mi := &file_recommendation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainRecommendationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainRecommendationResponse) ProtoMessage() {}
+
+func (x *ExplainRecommendationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainRecommendationResponse.ProtoReflect.Descriptor instead.
+func (*ExplainRecommendationResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExplainRecommendationResponse) GetExplanation() string {
+	if x != nil {
+		return x.Explanation
+	}
+	return ""
+}
+
+// 用户反馈请求
+type RecordFeedbackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ItemId        string                 `protobuf:"bytes,2,opt,name=item_id,json=itemId,proto3" json:"item_id,omitempty"`
+	Feedback      *structpb.Value        `protobuf:"bytes,3,opt,name=feedback,proto3" json:"feedback,omitempty"` // 反馈内容
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordFeedbackRequest) Reset() {
+	*x = RecordFeedbackRequest{}
+	mi := &file_recommendation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordFeedbackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordFeedbackRequest) ProtoMessage() {}
+
+func (x *RecordFeedbackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordFeedbackRequest.ProtoReflect.Descriptor instead.
+func (*RecordFeedbackRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordFeedbackRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *RecordFeedbackRequest) GetItemId() string {
+	if x != nil {
+		return x.ItemId
+	}
+	return ""
+}
+
+func (x *RecordFeedbackRequest) GetFeedback() *structpb.Value {
+	if x != nil {
+		return x.Feedback
+	}
+	return nil
+}
+
+// 用户反馈响应
+type RecordFeedbackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordFeedbackResponse) Reset() {
+	*x = RecordFeedbackResponse{}
+	mi := &file_recommendation_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordFeedbackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordFeedbackResponse) ProtoMessage() {}
+
+func (x *RecordFeedbackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordFeedbackResponse.ProtoReflect.Descriptor instead.
+func (*RecordFeedbackResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{8}
+}
+
+// 算法列表请求
+type GetAvailableAlgorithmsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailableAlgorithmsRequest) Reset() {
+	*x = GetAvailableAlgorithmsRequest{}
+	mi := &file_recommendation_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailableAlgorithmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableAlgorithmsRequest) ProtoMessage() {}
+
+func (x *GetAvailableAlgorithmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableAlgorithmsRequest.ProtoReflect.Descriptor instead.
+func (*GetAvailableAlgorithmsRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{9}
+}
+
+// 算法列表响应
+type GetAvailableAlgorithmsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Algorithms    []string               `protobuf:"bytes,1,rep,name=algorithms,proto3" json:"algorithms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAvailableAlgorithmsResponse) Reset() {
+	*x = GetAvailableAlgorithmsResponse{}
+	mi := &file_recommendation_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAvailableAlgorithmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAvailableAlgorithmsResponse) ProtoMessage() {}
+
+func (x *GetAvailableAlgorithmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAvailableAlgorithmsResponse.ProtoReflect.Descriptor instead.
+func (*GetAvailableAlgorithmsResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetAvailableAlgorithmsResponse) GetAlgorithms() []string {
+	if x != nil {
+		return x.Algorithms
+	}
+	return nil
+}
+
+// 获取算法参数请求
+type GetAlgorithmParametersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Algorithm     string                 `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlgorithmParametersRequest) Reset() {
+	*x = GetAlgorithmParametersRequest{}
+	mi := &file_recommendation_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlgorithmParametersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlgorithmParametersRequest) ProtoMessage() {}
+
+func (x *GetAlgorithmParametersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlgorithmParametersRequest.ProtoReflect.Descriptor instead.
+func (*GetAlgorithmParametersRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAlgorithmParametersRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+// 获取算法参数响应
+type GetAlgorithmParametersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parameters    *structpb.Struct       `protobuf:"bytes,1,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAlgorithmParametersResponse) Reset() {
+	*x = GetAlgorithmParametersResponse{}
+	mi := &file_recommendation_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAlgorithmParametersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAlgorithmParametersResponse) ProtoMessage() {}
+
+func (x *GetAlgorithmParametersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAlgorithmParametersResponse.ProtoReflect.Descriptor instead.
+func (*GetAlgorithmParametersResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetAlgorithmParametersResponse) GetParameters() *structpb.Struct {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+// 设置算法参数请求
+type SetAlgorithmParametersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Algorithm     string                 `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	Parameters    *structpb.Struct       `protobuf:"bytes,2,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAlgorithmParametersRequest) Reset() {
+	*x = SetAlgorithmParametersRequest{}
+	mi := &file_recommendation_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAlgorithmParametersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAlgorithmParametersRequest) ProtoMessage() {}
+
+func (x *SetAlgorithmParametersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAlgorithmParametersRequest.ProtoReflect.Descriptor instead.
+func (*SetAlgorithmParametersRequest) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetAlgorithmParametersRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *SetAlgorithmParametersRequest) GetParameters() *structpb.Struct {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+// 设置算法参数响应
+type SetAlgorithmParametersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetAlgorithmParametersResponse) Reset() {
+	*x = SetAlgorithmParametersResponse{}
+	mi := &file_recommendation_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetAlgorithmParametersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetAlgorithmParametersResponse) ProtoMessage() {}
+
+func (x *SetAlgorithmParametersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recommendation_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetAlgorithmParametersResponse.ProtoReflect.Descriptor instead.
+func (*SetAlgorithmParametersResponse) Descriptor() ([]byte, []int) {
+	return file_recommendation_proto_rawDescGZIP(), []int{14}
+}
+
+var File_recommendation_proto protoreflect.FileDescriptor
+
+const file_recommendation_proto_rawDesc = "" +
+	"\n" +
+	"\x14recommendation.proto\x12\x17luban.recommendation.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x9a\x02\n" +
+	"\x10RecommendRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bscenario\x18\x02 \x01(\tR\bscenario\x121\n" +
+	"\acontext\x18\x03 \x01(\v2\x17.google.protobuf.StructR\acontext\x121\n" +
+	"\afilters\x18\x04 \x01(\v2\x17.google.protobuf.StructR\afilters\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x1c\n" +
+	"\talgorithm\x18\x06 \x01(\tR\talgorithm\x127\n" +
+	"\n" +
+	"parameters\x18\a \x01(\v2\x17.google.protobuf.StructR\n" +
+	"parameters\"\xd0\x01\n" +
+	"\x14RecommendationResult\x12\x17\n" +
+	"\aitem_id\x18\x01 \x01(\tR\x06itemId\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1c\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x05 \x01(\x01R\n" +
+	"confidence\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\xa7\x02\n" +
+	"\x11RecommendResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12W\n" +
+	"\x0frecommendations\x18\x02 \x03(\v2-.luban.recommendation.v1.RecommendationResultR\x0frecommendations\x12\x1f\n" +
+	"\vtotal_count\x18\x03 \x01(\x05R\n" +
+	"totalCount\x12\x1c\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x12,\n" +
+	"\x12processing_time_ms\x18\x05 \x01(\x03R\x10processingTimeMs\x123\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"^\n" +
+	"\x15RecommendBatchRequest\x12E\n" +
+	"\brequests\x18\x01 \x03(\v2).luban.recommendation.v1.RecommendRequestR\brequests\"b\n" +
+	"\x16RecommendBatchResponse\x12H\n" +
+	"\tresponses\x18\x01 \x03(\v2*.luban.recommendation.v1.RecommendResponseR\tresponses\"P\n" +
+	"\x1cExplainRecommendationRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\"A\n" +
+	"\x1dExplainRecommendationResponse\x12 \n" +
+	"\vexplanation\x18\x01 \x01(\tR\vexplanation\"}\n" +
+	"\x15RecordFeedbackRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\aitem_id\x18\x02 \x01(\tR\x06itemId\x122\n" +
+	"\bfeedback\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\bfeedback\"\x18\n" +
+	"\x16RecordFeedbackResponse\"\x1f\n" +
+	"\x1dGetAvailableAlgorithmsRequest\"@\n" +
+	"\x1eGetAvailableAlgorithmsResponse\x12\x1e\n" +
+	"\n" +
+	"algorithms\x18\x01 \x03(\tR\n" +
+	"algorithms\"=\n" +
+	"\x1dGetAlgorithmParametersRequest\x12\x1c\n" +
+	"\talgorithm\x18\x01 \x01(\tR\talgorithm\"Y\n" +
+	"\x1eGetAlgorithmParametersResponse\x127\n" +
+	"\n" +
+	"parameters\x18\x01 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"parameters\"v\n" +
+	"\x1dSetAlgorithmParametersRequest\x12\x1c\n" +
+	"\talgorithm\x18\x01 \x01(\tR\talgorithm\x127\n" +
+	"\n" +
+	"parameters\x18\x02 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"parameters\" \n" +
+	"\x1eSetAlgorithmParametersResponse2\x8e\a\n" +
+	"\x15RecommendationService\x12b\n" +
+	"\tRecommend\x12).luban.recommendation.v1.RecommendRequest\x1a*.luban.recommendation.v1.RecommendResponse\x12q\n" +
+	"\x0eRecommendBatch\x12..luban.recommendation.v1.RecommendBatchRequest\x1a/.luban.recommendation.v1.RecommendBatchResponse\x12\x86\x01\n" +
+	"\x15ExplainRecommendation\x125.luban.recommendation.v1.ExplainRecommendationRequest\x1a6.luban.recommendation.v1.ExplainRecommendationResponse\x12q\n" +
+	"\x0eRecordFeedback\x12..luban.recommendation.v1.RecordFeedbackRequest\x1a/.luban.recommendation.v1.RecordFeedbackResponse\x12\x89\x01\n" +
+	"\x16GetAvailableAlgorithms\x126.luban.recommendation.v1.GetAvailableAlgorithmsRequest\x1a7.luban.recommendation.v1.GetAvailableAlgorithmsResponse\x12\x89\x01\n" +
+	"\x16GetAlgorithmParameters\x126.luban.recommendation.v1.GetAlgorithmParametersRequest\x1a7.luban.recommendation.v1.GetAlgorithmParametersResponse\x12\x89\x01\n" +
+	"\x16SetAlgorithmParameters\x126.luban.recommendation.v1.SetAlgorithmParametersRequest\x1a7.luban.recommendation.v1.SetAlgorithmParametersResponseB;Z9github.com/guanguoyintao/luban/internal/api/grpcapi/pb;pbb\x06proto3"
+
+var (
+	file_recommendation_proto_rawDescOnce sync.Once
+	file_recommendation_proto_rawDescData []byte
+)
+
+func file_recommendation_proto_rawDescGZIP() []byte {
+	file_recommendation_proto_rawDescOnce.Do(func() {
+		file_recommendation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_recommendation_proto_rawDesc), len(file_recommendation_proto_rawDesc)))
+	})
+	return file_recommendation_proto_rawDescData
+}
+
+var file_recommendation_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_recommendation_proto_goTypes = []any{
+	(*RecommendRequest)(nil),               // 0: luban.recommendation.v1.RecommendRequest
+	(*RecommendationResult)(nil),           // 1: luban.recommendation.v1.RecommendationResult
+	(*RecommendResponse)(nil),              // 2: luban.recommendation.v1.RecommendResponse
+	(*RecommendBatchRequest)(nil),          // 3: luban.recommendation.v1.RecommendBatchRequest
+	(*RecommendBatchResponse)(nil),         // 4: luban.recommendation.v1.RecommendBatchResponse
+	(*ExplainRecommendationRequest)(nil),   // 5: luban.recommendation.v1.ExplainRecommendationRequest
+	(*ExplainRecommendationResponse)(nil),  // 6: luban.recommendation.v1.ExplainRecommendationResponse
+	(*RecordFeedbackRequest)(nil),          // 7: luban.recommendation.v1.RecordFeedbackRequest
+	(*RecordFeedbackResponse)(nil),         // 8: luban.recommendation.v1.RecordFeedbackResponse
+	(*GetAvailableAlgorithmsRequest)(nil),  // 9: luban.recommendation.v1.GetAvailableAlgorithmsRequest
+	(*GetAvailableAlgorithmsResponse)(nil), // 10: luban.recommendation.v1.GetAvailableAlgorithmsResponse
+	(*GetAlgorithmParametersRequest)(nil),  // 11: luban.recommendation.v1.GetAlgorithmParametersRequest
+	(*GetAlgorithmParametersResponse)(nil), // 12: luban.recommendation.v1.GetAlgorithmParametersResponse
+	(*SetAlgorithmParametersRequest)(nil),  // 13: luban.recommendation.v1.SetAlgorithmParametersRequest
+	(*SetAlgorithmParametersResponse)(nil), // 14: luban.recommendation.v1.SetAlgorithmParametersResponse
+	(*structpb.Struct)(nil),                // 15: google.protobuf.Struct
+	(*structpb.Value)(nil),                 // 16: google.protobuf.Value
+}
+var file_recommendation_proto_depIdxs = []int32{
+	15, // 0: luban.recommendation.v1.RecommendRequest.context:type_name -> google.protobuf.Struct
+	15, // 1: luban.recommendation.v1.RecommendRequest.filters:type_name -> google.protobuf.Struct
+	15, // 2: luban.recommendation.v1.RecommendRequest.parameters:type_name -> google.protobuf.Struct
+	15, // 3: luban.recommendation.v1.RecommendationResult.metadata:type_name -> google.protobuf.Struct
+	1,  // 4: luban.recommendation.v1.RecommendResponse.recommendations:type_name -> luban.recommendation.v1.RecommendationResult
+	15, // 5: luban.recommendation.v1.RecommendResponse.metadata:type_name -> google.protobuf.Struct
+	0,  // 6: luban.recommendation.v1.RecommendBatchRequest.requests:type_name -> luban.recommendation.v1.RecommendRequest
+	2,  // 7: luban.recommendation.v1.RecommendBatchResponse.responses:type_name -> luban.recommendation.v1.RecommendResponse
+	16, // 8: luban.recommendation.v1.RecordFeedbackRequest.feedback:type_name -> google.protobuf.Value
+	15, // 9: luban.recommendation.v1.GetAlgorithmParametersResponse.parameters:type_name -> google.protobuf.Struct
+	15, // 10: luban.recommendation.v1.SetAlgorithmParametersRequest.parameters:type_name -> google.protobuf.Struct
+	0,  // 11: luban.recommendation.v1.RecommendationService.Recommend:input_type -> luban.recommendation.v1.RecommendRequest
+	3,  // 12: luban.recommendation.v1.RecommendationService.RecommendBatch:input_type -> luban.recommendation.v1.RecommendBatchRequest
+	5,  // 13: luban.recommendation.v1.RecommendationService.ExplainRecommendation:input_type -> luban.recommendation.v1.ExplainRecommendationRequest
+	7,  // 14: luban.recommendation.v1.RecommendationService.RecordFeedback:input_type -> luban.recommendation.v1.RecordFeedbackRequest
+	9,  // 15: luban.recommendation.v1.RecommendationService.GetAvailableAlgorithms:input_type -> luban.recommendation.v1.GetAvailableAlgorithmsRequest
+	11, // 16: luban.recommendation.v1.RecommendationService.GetAlgorithmParameters:input_type -> luban.recommendation.v1.GetAlgorithmParametersRequest
+	13, // 17: luban.recommendation.v1.RecommendationService.SetAlgorithmParameters:input_type -> luban.recommendation.v1.SetAlgorithmParametersRequest
+	2,  // 18: luban.recommendation.v1.RecommendationService.Recommend:output_type -> luban.recommendation.v1.RecommendResponse
+	4,  // 19: luban.recommendation.v1.RecommendationService.RecommendBatch:output_type -> luban.recommendation.v1.RecommendBatchResponse
+	6,  // 20: luban.recommendation.v1.RecommendationService.ExplainRecommendation:output_type -> luban.recommendation.v1.ExplainRecommendationResponse
+	8,  // 21: luban.recommendation.v1.RecommendationService.RecordFeedback:output_type -> luban.recommendation.v1.RecordFeedbackResponse
+	10, // 22: luban.recommendation.v1.RecommendationService.GetAvailableAlgorithms:output_type -> luban.recommendation.v1.GetAvailableAlgorithmsResponse
+	12, // 23: luban.recommendation.v1.RecommendationService.GetAlgorithmParameters:output_type -> luban.recommendation.v1.GetAlgorithmParametersResponse
+	14, // 24: luban.recommendation.v1.RecommendationService.SetAlgorithmParameters:output_type -> luban.recommendation.v1.SetAlgorithmParametersResponse
+	18, // [18:25] is the sub-list for method output_type
+	11, // [11:18] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_recommendation_proto_init() }
+func file_recommendation_proto_init() {
+	if File_recommendation_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_recommendation_proto_rawDesc), len(file_recommendation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_recommendation_proto_goTypes,
+		DependencyIndexes: file_recommendation_proto_depIdxs,
+		MessageInfos:      file_recommendation_proto_msgTypes,
+	}.Build()
+	File_recommendation_proto = out.File
+	file_recommendation_proto_goTypes = nil
+	file_recommendation_proto_depIdxs = nil
+}
@@ -0,0 +1,369 @@
+// 推荐引擎gRPC服务定义，与 recommendation.RecommendationEngine 接口一一对应
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: recommendation.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RecommendationService_Recommend_FullMethodName              = "/luban.recommendation.v1.RecommendationService/Recommend"
+	RecommendationService_RecommendBatch_FullMethodName         = "/luban.recommendation.v1.RecommendationService/RecommendBatch"
+	RecommendationService_ExplainRecommendation_FullMethodName  = "/luban.recommendation.v1.RecommendationService/ExplainRecommendation"
+	RecommendationService_RecordFeedback_FullMethodName         = "/luban.recommendation.v1.RecommendationService/RecordFeedback"
+	RecommendationService_GetAvailableAlgorithms_FullMethodName = "/luban.recommendation.v1.RecommendationService/GetAvailableAlgorithms"
+	RecommendationService_GetAlgorithmParameters_FullMethodName = "/luban.recommendation.v1.RecommendationService/GetAlgorithmParameters"
+	RecommendationService_SetAlgorithmParameters_FullMethodName = "/luban.recommendation.v1.RecommendationService/SetAlgorithmParameters"
+)
+
+// RecommendationServiceClient is the client API for RecommendationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// 推荐引擎服务
+type RecommendationServiceClient interface {
+	// 生成推荐
+	Recommend(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (*RecommendResponse, error)
+	// 批量生成推荐
+	RecommendBatch(ctx context.Context, in *RecommendBatchRequest, opts ...grpc.CallOption) (*RecommendBatchResponse, error)
+	// 获取推荐解释
+	ExplainRecommendation(ctx context.Context, in *ExplainRecommendationRequest, opts ...grpc.CallOption) (*ExplainRecommendationResponse, error)
+	// 记录用户反馈
+	RecordFeedback(ctx context.Context, in *RecordFeedbackRequest, opts ...grpc.CallOption) (*RecordFeedbackResponse, error)
+	// 获取推荐算法列表
+	GetAvailableAlgorithms(ctx context.Context, in *GetAvailableAlgorithmsRequest, opts ...grpc.CallOption) (*GetAvailableAlgorithmsResponse, error)
+	// 获取算法参数
+	GetAlgorithmParameters(ctx context.Context, in *GetAlgorithmParametersRequest, opts ...grpc.CallOption) (*GetAlgorithmParametersResponse, error)
+	// 设置算法参数
+	SetAlgorithmParameters(ctx context.Context, in *SetAlgorithmParametersRequest, opts ...grpc.CallOption) (*SetAlgorithmParametersResponse, error)
+}
+
+type recommendationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRecommendationServiceClient(cc grpc.ClientConnInterface) RecommendationServiceClient {
+	return &recommendationServiceClient{cc}
+}
+
+func (c *recommendationServiceClient) Recommend(ctx context.Context, in *RecommendRequest, opts ...grpc.CallOption) (*RecommendResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_Recommend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) RecommendBatch(ctx context.Context, in *RecommendBatchRequest, opts ...grpc.CallOption) (*RecommendBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendBatchResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_RecommendBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) ExplainRecommendation(ctx context.Context, in *ExplainRecommendationRequest, opts ...grpc.CallOption) (*ExplainRecommendationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainRecommendationResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_ExplainRecommendation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) RecordFeedback(ctx context.Context, in *RecordFeedbackRequest, opts ...grpc.CallOption) (*RecordFeedbackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordFeedbackResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_RecordFeedback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) GetAvailableAlgorithms(ctx context.Context, in *GetAvailableAlgorithmsRequest, opts ...grpc.CallOption) (*GetAvailableAlgorithmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAvailableAlgorithmsResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_GetAvailableAlgorithms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) GetAlgorithmParameters(ctx context.Context, in *GetAlgorithmParametersRequest, opts ...grpc.CallOption) (*GetAlgorithmParametersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAlgorithmParametersResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_GetAlgorithmParameters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *recommendationServiceClient) SetAlgorithmParameters(ctx context.Context, in *SetAlgorithmParametersRequest, opts ...grpc.CallOption) (*SetAlgorithmParametersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAlgorithmParametersResponse)
+	err := c.cc.Invoke(ctx, RecommendationService_SetAlgorithmParameters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RecommendationServiceServer is the server API for RecommendationService service.
+// All implementations must embed UnimplementedRecommendationServiceServer
+// for forward compatibility.
+//
+// 推荐引擎服务
+type RecommendationServiceServer interface {
+	// 生成推荐
+	Recommend(context.Context, *RecommendRequest) (*RecommendResponse, error)
+	// 批量生成推荐
+	RecommendBatch(context.Context, *RecommendBatchRequest) (*RecommendBatchResponse, error)
+	// 获取推荐解释
+	ExplainRecommendation(context.Context, *ExplainRecommendationRequest) (*ExplainRecommendationResponse, error)
+	// 记录用户反馈
+	RecordFeedback(context.Context, *RecordFeedbackRequest) (*RecordFeedbackResponse, error)
+	// 获取推荐算法列表
+	GetAvailableAlgorithms(context.Context, *GetAvailableAlgorithmsRequest) (*GetAvailableAlgorithmsResponse, error)
+	// 获取算法参数
+	GetAlgorithmParameters(context.Context, *GetAlgorithmParametersRequest) (*GetAlgorithmParametersResponse, error)
+	// 设置算法参数
+	SetAlgorithmParameters(context.Context, *SetAlgorithmParametersRequest) (*SetAlgorithmParametersResponse, error)
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+// UnimplementedRecommendationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRecommendationServiceServer struct{}
+
+func (UnimplementedRecommendationServiceServer) Recommend(context.Context, *RecommendRequest) (*RecommendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Recommend not implemented")
+}
+func (UnimplementedRecommendationServiceServer) RecommendBatch(context.Context, *RecommendBatchRequest) (*RecommendBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecommendBatch not implemented")
+}
+func (UnimplementedRecommendationServiceServer) ExplainRecommendation(context.Context, *ExplainRecommendationRequest) (*ExplainRecommendationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainRecommendation not implemented")
+}
+func (UnimplementedRecommendationServiceServer) RecordFeedback(context.Context, *RecordFeedbackRequest) (*RecordFeedbackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordFeedback not implemented")
+}
+func (UnimplementedRecommendationServiceServer) GetAvailableAlgorithms(context.Context, *GetAvailableAlgorithmsRequest) (*GetAvailableAlgorithmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailableAlgorithms not implemented")
+}
+func (UnimplementedRecommendationServiceServer) GetAlgorithmParameters(context.Context, *GetAlgorithmParametersRequest) (*GetAlgorithmParametersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlgorithmParameters not implemented")
+}
+func (UnimplementedRecommendationServiceServer) SetAlgorithmParameters(context.Context, *SetAlgorithmParametersRequest) (*SetAlgorithmParametersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAlgorithmParameters not implemented")
+}
+func (UnimplementedRecommendationServiceServer) mustEmbedUnimplementedRecommendationServiceServer() {}
+func (UnimplementedRecommendationServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeRecommendationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RecommendationServiceServer will
+// result in compilation errors.
+type UnsafeRecommendationServiceServer interface {
+	mustEmbedUnimplementedRecommendationServiceServer()
+}
+
+func RegisterRecommendationServiceServer(s grpc.ServiceRegistrar, srv RecommendationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedRecommendationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RecommendationService_ServiceDesc, srv)
+}
+
+func _RecommendationService_Recommend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).Recommend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_Recommend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).Recommend(ctx, req.(*RecommendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_RecommendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).RecommendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_RecommendBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).RecommendBatch(ctx, req.(*RecommendBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_ExplainRecommendation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainRecommendationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).ExplainRecommendation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_ExplainRecommendation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).ExplainRecommendation(ctx, req.(*ExplainRecommendationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_RecordFeedback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordFeedbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).RecordFeedback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_RecordFeedback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).RecordFeedback(ctx, req.(*RecordFeedbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_GetAvailableAlgorithms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAvailableAlgorithmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).GetAvailableAlgorithms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_GetAvailableAlgorithms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).GetAvailableAlgorithms(ctx, req.(*GetAvailableAlgorithmsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_GetAlgorithmParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlgorithmParametersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).GetAlgorithmParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_GetAlgorithmParameters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).GetAlgorithmParameters(ctx, req.(*GetAlgorithmParametersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RecommendationService_SetAlgorithmParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAlgorithmParametersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RecommendationServiceServer).SetAlgorithmParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RecommendationService_SetAlgorithmParameters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RecommendationServiceServer).SetAlgorithmParameters(ctx, req.(*SetAlgorithmParametersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RecommendationService_ServiceDesc is the grpc.ServiceDesc for RecommendationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RecommendationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "luban.recommendation.v1.RecommendationService",
+	HandlerType: (*RecommendationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Recommend",
+			Handler:    _RecommendationService_Recommend_Handler,
+		},
+		{
+			MethodName: "RecommendBatch",
+			Handler:    _RecommendationService_RecommendBatch_Handler,
+		},
+		{
+			MethodName: "ExplainRecommendation",
+			Handler:    _RecommendationService_ExplainRecommendation_Handler,
+		},
+		{
+			MethodName: "RecordFeedback",
+			Handler:    _RecommendationService_RecordFeedback_Handler,
+		},
+		{
+			MethodName: "GetAvailableAlgorithms",
+			Handler:    _RecommendationService_GetAvailableAlgorithms_Handler,
+		},
+		{
+			MethodName: "GetAlgorithmParameters",
+			Handler:    _RecommendationService_GetAlgorithmParameters_Handler,
+		},
+		{
+			MethodName: "SetAlgorithmParameters",
+			Handler:    _RecommendationService_SetAlgorithmParameters_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "recommendation.proto",
+}
@@ -0,0 +1,147 @@
+// Package grpcapi 推荐引擎gRPC接入层
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/guanguoyintao/luban/internal/api/grpcapi/pb"
+	apperror "github.com/guanguoyintao/luban/internal/infra/error"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+)
+
+// ServerConfig gRPC服务配置
+type ServerConfig struct {
+	Addr            string        // 监听地址
+	ShutdownTimeout time.Duration // 优雅关闭超时，超时后强制关闭
+}
+
+// DefaultServerConfig 默认gRPC服务配置
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Addr:            ":9090",
+		ShutdownTimeout: 15 * time.Second,
+	}
+}
+
+// Server 推荐引擎gRPC服务器
+type Server struct {
+	mu         sync.Mutex
+	service    *RecommendationService
+	log        *logrus.Logger
+	config     *ServerConfig
+	grpcServer *grpc.Server
+}
+
+// NewServer 创建gRPC服务器
+func NewServer(engine recommendation.RecommendationEngine, log *logrus.Logger) *Server {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &Server{
+		service: NewRecommendationService(engine, log),
+		log:     log,
+		config:  DefaultServerConfig(),
+	}
+}
+
+// SetConfig 设置服务配置，需在Start之前调用
+func (s *Server) SetConfig(config *ServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// Start 启动gRPC服务，阻塞直到服务关闭
+func (s *Server) Start() error {
+	s.mu.Lock()
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	s.grpcServer = grpc.NewServer(grpc.ChainUnaryInterceptor(s.errorInterceptor, s.recoveryInterceptor))
+	pb.RegisterRecommendationServiceServer(s.grpcServer, s.service)
+	grpcServer := s.grpcServer
+	s.mu.Unlock()
+
+	s.log.WithField("addr", listener.Addr().String()).Info("gRPC服务启动")
+
+	if err := grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭gRPC服务，超时后强制关闭
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	grpcServer := s.grpcServer
+	timeout := s.config.ShutdownTimeout
+	s.mu.Unlock()
+
+	if grpcServer == nil {
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		s.log.Info("gRPC服务已关闭")
+		return nil
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+		s.log.Warn("gRPC服务优雅关闭超时，已强制关闭")
+		return shutdownCtx.Err()
+	}
+}
+
+// errorInterceptor 将服务错误转换为gRPC状态
+func (s *Server) errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	startTime := time.Now()
+	resp, err := handler(ctx, req)
+
+	fields := logrus.Fields{
+		"method":  info.FullMethod,
+		"latency": time.Since(startTime).Milliseconds(),
+	}
+	if err == nil {
+		s.log.WithFields(fields).Info("处理gRPC请求")
+		return resp, nil
+	}
+
+	st := apperror.ToGRPCStatus(err)
+	fields["code"] = st.Code().String()
+	s.log.WithError(err).WithFields(fields).Error("gRPC请求处理失败")
+	return nil, st.Err()
+}
+
+// recoveryInterceptor panic恢复拦截器
+func (s *Server) recoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.log.WithFields(logrus.Fields{
+				"method": info.FullMethod,
+				"panic":  recovered,
+			}).Error("gRPC请求处理发生panic")
+			err = apperror.New(apperror.CodeInternalError, "Internal Server Error")
+		}
+	}()
+	return handler(ctx, req)
+}
@@ -0,0 +1,222 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/guanguoyintao/luban/internal/api/grpcapi/pb"
+	"github.com/guanguoyintao/luban/internal/application"
+	apperror "github.com/guanguoyintao/luban/internal/infra/error"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+)
+
+// RecommendationService 推荐引擎gRPC服务，将请求转发到 recommendation.RecommendationEngine
+type RecommendationService struct {
+	pb.UnimplementedRecommendationServiceServer
+
+	engine recommendation.RecommendationEngine
+	log    *logrus.Logger
+}
+
+// NewRecommendationService 创建推荐引擎gRPC服务
+func NewRecommendationService(engine recommendation.RecommendationEngine, log *logrus.Logger) *RecommendationService {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &RecommendationService{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// Recommend 生成推荐
+func (s *RecommendationService) Recommend(ctx context.Context, req *pb.RecommendRequest) (*pb.RecommendResponse, error) {
+	request := toRecommendationRequest(req)
+	if err := application.ValidateRecommendationRequest(ctx, s.engine, request); err != nil {
+		return nil, err
+	}
+
+	response, err := s.engine.Recommend(ctx, request)
+	if err != nil {
+		return nil, application.WrapServiceError(err, "生成推荐失败")
+	}
+
+	return toPBRecommendResponse(response)
+}
+
+// RecommendBatch 批量生成推荐
+func (s *RecommendationService) RecommendBatch(ctx context.Context, req *pb.RecommendBatchRequest) (*pb.RecommendBatchResponse, error) {
+	requests := make([]recommendation.RecommendationRequest, 0, len(req.GetRequests()))
+	for i, item := range req.GetRequests() {
+		request := toRecommendationRequest(item)
+		if err := application.ValidateRecommendationRequest(ctx, s.engine, request); err != nil {
+			if e, ok := apperror.As(err); ok {
+				e.WithDetail("index", i)
+			}
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	responses, err := s.engine.RecommendBatch(ctx, requests)
+	if err != nil {
+		return nil, application.WrapServiceError(err, "批量生成推荐失败")
+	}
+
+	result := &pb.RecommendBatchResponse{
+		Responses: make([]*pb.RecommendResponse, 0, len(responses)),
+	}
+	for _, response := range responses {
+		pbResponse, err := toPBRecommendResponse(response)
+		if err != nil {
+			return nil, err
+		}
+		result.Responses = append(result.Responses, pbResponse)
+	}
+
+	return result, nil
+}
+
+// ExplainRecommendation 获取推荐解释
+func (s *RecommendationService) ExplainRecommendation(ctx context.Context, req *pb.ExplainRecommendationRequest) (*pb.ExplainRecommendationResponse, error) {
+	if req.GetUserId() == "" || req.GetItemId() == "" {
+		return nil, apperror.New(apperror.CodeInvalidParameter, "用户ID和物品ID不能为空")
+	}
+
+	explanation, err := s.engine.ExplainRecommendation(ctx, req.GetUserId(), req.GetItemId())
+	if err != nil {
+		return nil, application.WrapServiceError(err, "获取推荐解释失败")
+	}
+
+	return &pb.ExplainRecommendationResponse{Explanation: explanation}, nil
+}
+
+// RecordFeedback 记录用户反馈
+func (s *RecommendationService) RecordFeedback(ctx context.Context, req *pb.RecordFeedbackRequest) (*pb.RecordFeedbackResponse, error) {
+	if req.GetUserId() == "" || req.GetItemId() == "" {
+		return nil, apperror.New(apperror.CodeInvalidParameter, "用户ID和物品ID不能为空")
+	}
+
+	var feedback interface{}
+	if req.GetFeedback() != nil {
+		feedback = req.GetFeedback().AsInterface()
+	}
+
+	if err := s.engine.RecordFeedback(ctx, req.GetUserId(), req.GetItemId(), feedback); err != nil {
+		return nil, application.WrapServiceError(err, "记录用户反馈失败")
+	}
+
+	return &pb.RecordFeedbackResponse{}, nil
+}
+
+// GetAvailableAlgorithms 获取推荐算法列表
+func (s *RecommendationService) GetAvailableAlgorithms(ctx context.Context, req *pb.GetAvailableAlgorithmsRequest) (*pb.GetAvailableAlgorithmsResponse, error) {
+	algorithms, err := s.engine.GetAvailableAlgorithms(ctx)
+	if err != nil {
+		return nil, application.WrapServiceError(err, "获取推荐算法列表失败")
+	}
+
+	names := make([]string, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		names = append(names, string(algorithm))
+	}
+
+	return &pb.GetAvailableAlgorithmsResponse{Algorithms: names}, nil
+}
+
+// GetAlgorithmParameters 获取算法参数
+func (s *RecommendationService) GetAlgorithmParameters(ctx context.Context, req *pb.GetAlgorithmParametersRequest) (*pb.GetAlgorithmParametersResponse, error) {
+	algorithm := recommendation.AlgorithmType(req.GetAlgorithm())
+	if err := application.ValidateAlgorithm(ctx, s.engine, algorithm); err != nil {
+		return nil, err
+	}
+
+	parameters, err := s.engine.GetAlgorithmParameters(ctx, algorithm)
+	if err != nil {
+		return nil, application.WrapServiceError(err, "获取算法参数失败")
+	}
+
+	pbParameters, err := toStruct(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAlgorithmParametersResponse{Parameters: pbParameters}, nil
+}
+
+// SetAlgorithmParameters 设置算法参数
+func (s *RecommendationService) SetAlgorithmParameters(ctx context.Context, req *pb.SetAlgorithmParametersRequest) (*pb.SetAlgorithmParametersResponse, error) {
+	algorithm := recommendation.AlgorithmType(req.GetAlgorithm())
+	if err := application.ValidateAlgorithm(ctx, s.engine, algorithm); err != nil {
+		return nil, err
+	}
+
+	if err := s.engine.SetAlgorithmParameters(ctx, algorithm, req.GetParameters().AsMap()); err != nil {
+		return nil, application.WrapServiceError(err, "设置算法参数失败")
+	}
+
+	return &pb.SetAlgorithmParametersResponse{}, nil
+}
+
+// toRecommendationRequest 转换为推荐引擎请求
+func toRecommendationRequest(req *pb.RecommendRequest) recommendation.RecommendationRequest {
+	return recommendation.RecommendationRequest{
+		UserID:     req.GetUserId(),
+		Scenario:   recommendation.RecommendationScenario(req.GetScenario()),
+		Context:    req.GetContext().AsMap(),
+		Filters:    req.GetFilters().AsMap(),
+		Limit:      int(req.GetLimit()),
+		Algorithm:  recommendation.AlgorithmType(req.GetAlgorithm()),
+		Parameters: req.GetParameters().AsMap(),
+	}
+}
+
+// toPBRecommendResponse 转换为gRPC推荐响应
+func toPBRecommendResponse(response *recommendation.RecommendationResponse) (*pb.RecommendResponse, error) {
+	metadata, err := toStruct(response.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pb.RecommendResponse{
+		UserId:           response.UserID,
+		Recommendations:  make([]*pb.RecommendationResult, 0, len(response.Recommendations)),
+		TotalCount:       int32(response.TotalCount),
+		Algorithm:        string(response.Algorithm),
+		ProcessingTimeMs: response.ProcessingTime,
+		Metadata:         metadata,
+	}
+
+	for _, rec := range response.Recommendations {
+		recMetadata, err := toStruct(rec.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		result.Recommendations = append(result.Recommendations, &pb.RecommendationResult{
+			ItemId:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  string(rec.Algorithm),
+			Confidence: rec.Confidence,
+			Metadata:   recMetadata,
+		})
+	}
+
+	return result, nil
+}
+
+// toStruct 将任意map转换为protobuf Struct，值先转换为JSON兼容的类型
+func toStruct(values map[string]interface{}) (*structpb.Struct, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	result, err := structpb.NewStruct(apperror.NormalizeValues(values))
+	if err != nil {
+		return nil, apperror.Wrap(err, apperror.CodeInternalError, "转换响应元数据失败")
+	}
+	return result, nil
+}
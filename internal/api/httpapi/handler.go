@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/guanguoyintao/luban/internal/application"
+	apperror "github.com/guanguoyintao/luban/internal/infra/error"
+)
+
+// handleHealth 健康检查
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleGetRecommendations 获取用户推荐
+func (s *Server) handleGetRecommendations(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	count, err := parseCount(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	recommendations, err := s.useCase.GetRecommendations(r.Context(), userID, count)
+	if err != nil {
+		s.writeError(w, application.WrapServiceError(err, "获取推荐失败"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, application.NewRecommendationListDTO(userID, "", recommendations))
+}
+
+// handleGetRecommendationsByCategory 按类别获取用户推荐
+func (s *Server) handleGetRecommendationsByCategory(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	category := r.PathValue("category")
+	count, err := parseCount(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	recommendations, err := s.useCase.GetRecommendationsByCategory(r.Context(), userID, category, count)
+	if err != nil {
+		s.writeError(w, application.WrapServiceError(err, "按类别获取推荐失败"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, application.NewRecommendationListDTO(userID, category, recommendations))
+}
+
+// handleRecommend 按完整推荐请求生成推荐
+func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)
+
+	var body application.RecommendationRequestDTO
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		s.writeError(w, apperror.Wrap(err, apperror.CodeInvalidParameter, "请求体不是有效的JSON"))
+		return
+	}
+
+	request := body.ToRecommendationRequest()
+	if err := application.ValidateRecommendationRequest(r.Context(), s.engine, request); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	response, err := s.engine.Recommend(r.Context(), request)
+	if err != nil {
+		s.writeError(w, application.WrapServiceError(err, "生成推荐失败"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, application.NewRecommendationResponseDTO(response))
+}
+
+// parseCount 解析推荐数量参数
+func parseCount(r *http.Request) (int, error) {
+	raw := r.URL.Query().Get("count")
+	if raw == "" {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(raw)
+	if err != nil || count <= 0 || count > application.MaxRecommendationCount {
+		return 0, apperror.Newf(apperror.CodeInvalidParameter, "推荐数量必须是1-%d之间的整数", application.MaxRecommendationCount).
+			WithDetail("field", "count")
+	}
+	return count, nil
+}
+
+// writeError 输出错误响应
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	httpErr := apperror.ToHTTPError(err)
+	if httpErr.Code >= http.StatusInternalServerError {
+		s.log.WithError(err).Error("HTTP请求处理失败")
+	}
+	writeJSON(w, httpErr.Code, httpErr)
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
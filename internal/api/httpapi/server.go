@@ -0,0 +1,171 @@
+// Package httpapi 推荐服务HTTP/JSON接入层
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/guanguoyintao/luban/internal/application"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+)
+
+// ServerConfig HTTP服务配置
+type ServerConfig struct {
+	Addr            string        // 监听地址
+	ReadTimeout     time.Duration // 读超时
+	WriteTimeout    time.Duration // 写超时
+	IdleTimeout     time.Duration // 空闲连接超时
+	ShutdownTimeout time.Duration // 优雅关闭超时
+	MaxBodyBytes    int64         // 请求体最大字节数
+}
+
+// DefaultServerConfig 默认HTTP服务配置
+func DefaultServerConfig() *ServerConfig {
+	return &ServerConfig{
+		Addr:            ":8080",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		IdleTimeout:     60 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		MaxBodyBytes:    1 << 20,
+	}
+}
+
+// Server 推荐服务HTTP服务器
+type Server struct {
+	mu         sync.Mutex
+	useCase    application.RecommendationUseCase
+	engine     recommendation.RecommendationEngine
+	log        *logrus.Logger
+	config     *ServerConfig
+	httpServer *http.Server
+}
+
+// NewServer 创建HTTP服务器
+func NewServer(useCase application.RecommendationUseCase, engine recommendation.RecommendationEngine, log *logrus.Logger) *Server {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &Server{
+		useCase: useCase,
+		engine:  engine,
+		log:     log,
+		config:  DefaultServerConfig(),
+	}
+}
+
+// SetConfig 设置服务配置，需在Start之前调用
+func (s *Server) SetConfig(config *ServerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// Handler 返回注册了全部路由的处理器
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("GET /api/v1/users/{user_id}/recommendations", s.handleGetRecommendations)
+	mux.HandleFunc("GET /api/v1/users/{user_id}/recommendations/categories/{category}", s.handleGetRecommendationsByCategory)
+	mux.HandleFunc("POST /api/v1/recommendations", s.handleRecommend)
+
+	return s.withRecovery(s.withLogging(mux))
+}
+
+// Start 启动HTTP服务，阻塞直到服务关闭
+func (s *Server) Start() error {
+	s.mu.Lock()
+	s.httpServer = &http.Server{
+		Addr:         s.config.Addr,
+		Handler:      s.Handler(),
+		ReadTimeout:  s.config.ReadTimeout,
+		WriteTimeout: s.config.WriteTimeout,
+		IdleTimeout:  s.config.IdleTimeout,
+	}
+	httpServer := s.httpServer
+	s.mu.Unlock()
+
+	s.log.WithField("addr", httpServer.Addr).Info("HTTP服务启动")
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭HTTP服务，等待进行中的请求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	httpServer := s.httpServer
+	timeout := s.config.ShutdownTimeout
+	s.mu.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		s.log.WithError(err).Error("HTTP服务关闭失败")
+		return err
+	}
+
+	s.log.Info("HTTP服务已关闭")
+	return nil
+}
+
+// withLogging 请求日志中间件
+func (s *Server) withLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		s.log.WithFields(logrus.Fields{
+			"method":  r.Method,
+			"path":    r.URL.Path,
+			"status":  recorder.status,
+			"latency": time.Since(startTime).Milliseconds(),
+		}).Info("处理HTTP请求")
+	})
+}
+
+// withRecovery panic恢复中间件
+func (s *Server) withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				s.log.WithFields(logrus.Fields{
+					"path":  r.URL.Path,
+					"panic": recovered,
+				}).Error("HTTP请求处理发生panic")
+				writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+					"code":    http.StatusInternalServerError,
+					"message": "Internal Server Error",
+				})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
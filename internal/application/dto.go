@@ -1,4 +1,119 @@
 // Package application 定义应用层的用例和DTO
 package application
 
-import ()
\ No newline at end of file
+import (
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/domain"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+)
+
+// RecommendationDTO 推荐结果传输对象
+type RecommendationDTO struct {
+	ItemID     string    `json:"item_id"`
+	Score      float64   `json:"score"`
+	Reason     string    `json:"reason"`
+	Algorithm  string    `json:"algorithm"`
+	Confidence float64   `json:"confidence"`
+	Category   string    `json:"category"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RecommendationListDTO 推荐列表传输对象
+type RecommendationListDTO struct {
+	UserID          string              `json:"user_id"`
+	Category        string              `json:"category,omitempty"`
+	Recommendations []RecommendationDTO `json:"recommendations"`
+	TotalCount      int                 `json:"total_count"`
+}
+
+// NewRecommendationListDTO 将领域推荐结果转换为传输对象
+func NewRecommendationListDTO(userID string, category string, recommendations []domain.Recommendation) RecommendationListDTO {
+	items := make([]RecommendationDTO, 0, len(recommendations))
+	for _, rec := range recommendations {
+		items = append(items, RecommendationDTO{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  rec.Algorithm,
+			Confidence: rec.Confidence,
+			Category:   rec.Category,
+			CreatedAt:  rec.CreatedAt,
+		})
+	}
+
+	return RecommendationListDTO{
+		UserID:          userID,
+		Category:        category,
+		Recommendations: items,
+		TotalCount:      len(items),
+	}
+}
+
+// RecommendationRequestDTO 推荐请求传输对象
+type RecommendationRequestDTO struct {
+	UserID     string                 `json:"user_id"`
+	Scenario   string                 `json:"scenario"`
+	Context    map[string]interface{} `json:"context,omitempty"`
+	Filters    map[string]interface{} `json:"filters,omitempty"`
+	Limit      int                    `json:"limit"`
+	Algorithm  string                 `json:"algorithm,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToRecommendationRequest 转换为推荐引擎请求
+func (d RecommendationRequestDTO) ToRecommendationRequest() recommendation.RecommendationRequest {
+	return recommendation.RecommendationRequest{
+		UserID:     d.UserID,
+		Scenario:   recommendation.RecommendationScenario(d.Scenario),
+		Context:    d.Context,
+		Filters:    d.Filters,
+		Limit:      d.Limit,
+		Algorithm:  recommendation.AlgorithmType(d.Algorithm),
+		Parameters: d.Parameters,
+	}
+}
+
+// RecommendationResultDTO 推荐引擎结果传输对象
+type RecommendationResultDTO struct {
+	ItemID     string                 `json:"item_id"`
+	Score      float64                `json:"score"`
+	Reason     string                 `json:"reason"`
+	Algorithm  string                 `json:"algorithm"`
+	Confidence float64                `json:"confidence"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RecommendationResponseDTO 推荐引擎响应传输对象
+type RecommendationResponseDTO struct {
+	UserID          string                    `json:"user_id"`
+	Recommendations []RecommendationResultDTO `json:"recommendations"`
+	TotalCount      int                       `json:"total_count"`
+	Algorithm       string                    `json:"algorithm"`
+	ProcessingTime  int64                     `json:"processing_time_ms"`
+	Metadata        map[string]interface{}    `json:"metadata,omitempty"`
+}
+
+// NewRecommendationResponseDTO 将推荐引擎响应转换为传输对象
+func NewRecommendationResponseDTO(response *recommendation.RecommendationResponse) RecommendationResponseDTO {
+	results := make([]RecommendationResultDTO, 0, len(response.Recommendations))
+	for _, rec := range response.Recommendations {
+		results = append(results, RecommendationResultDTO{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  string(rec.Algorithm),
+			Confidence: rec.Confidence,
+			Metadata:   rec.Metadata,
+		})
+	}
+
+	return RecommendationResponseDTO{
+		UserID:          response.UserID,
+		Recommendations: results,
+		TotalCount:      response.TotalCount,
+		Algorithm:       string(response.Algorithm),
+		ProcessingTime:  response.ProcessingTime,
+		Metadata:        response.Metadata,
+	}
+}
@@ -0,0 +1,25 @@
+// Package application 服务层错误转换
+package application
+
+import (
+	"context"
+	"errors"
+
+	apperror "github.com/guanguoyintao/luban/internal/infra/error"
+)
+
+// WrapServiceError 将服务层错误包装为带错误代码的错误，已带错误代码的错误原样返回
+func WrapServiceError(err error, message string) error {
+	if _, ok := apperror.As(err); ok {
+		return err
+	}
+
+	code := apperror.CodeInternalError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = apperror.CodeTimeout
+	case errors.Is(err, context.Canceled):
+		code = apperror.CodeServiceUnavailable
+	}
+	return apperror.Wrap(err, code, message).WithDetail("cause", err.Error())
+}
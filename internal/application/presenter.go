@@ -47,19 +47,5 @@ func (p *RecommendationPresenter) GetRecommendationsByCategory(ctx context.Conte
 		count = 10 // 默认推荐数量
 	}
 
-	// 这里可以添加按类别筛选的逻辑
-	recommendations, err := p.recommendationService.GetRecommendations(ctx, userID, count)
-	if err != nil {
-		return nil, err
-	}
-
-	// 按类别筛选（简化实现）
-	var filteredRecommendations []domain.Recommendation
-	for _, rec := range recommendations {
-		if rec.Category == category {
-			filteredRecommendations = append(filteredRecommendations, rec)
-		}
-	}
-
-	return filteredRecommendations, nil
+	return p.recommendationService.GetRecommendationsByCategory(ctx, userID, category, count)
 }
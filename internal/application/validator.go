@@ -0,0 +1,56 @@
+// Package application 推荐请求校验
+package application
+
+import (
+	"context"
+
+	apperror "github.com/guanguoyintao/luban/internal/infra/error"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+)
+
+// MaxRecommendationCount 单次请求允许的最大推荐数量
+const MaxRecommendationCount = 100
+
+// ValidateRecommendationRequest 校验推荐请求，校验失败返回CodeInvalidParameter错误
+func ValidateRecommendationRequest(ctx context.Context, engine recommendation.RecommendationEngine, request recommendation.RecommendationRequest) error {
+	if request.UserID == "" {
+		return apperror.New(apperror.CodeInvalidParameter, "用户ID不能为空").
+			WithDetail("field", "user_id")
+	}
+
+	if request.Scenario != "" && !request.Scenario.IsValid() {
+		return apperror.Newf(apperror.CodeInvalidParameter, "不支持的推荐场景: %s", request.Scenario).
+			WithDetail("field", "scenario")
+	}
+
+	if request.Limit < 0 || request.Limit > MaxRecommendationCount {
+		return apperror.Newf(apperror.CodeInvalidParameter, "推荐数量必须在0-%d之间", MaxRecommendationCount).
+			WithDetail("field", "limit")
+	}
+
+	if request.Algorithm != "" {
+		if err := ValidateAlgorithm(ctx, engine, request.Algorithm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateAlgorithm 校验算法是否已注册
+func ValidateAlgorithm(ctx context.Context, engine recommendation.RecommendationEngine, algorithm recommendation.AlgorithmType) error {
+	algorithms, err := engine.GetAvailableAlgorithms(ctx)
+	if err != nil {
+		return apperror.Wrap(err, apperror.CodeInternalError, "获取可用算法失败")
+	}
+
+	for _, available := range algorithms {
+		if available == algorithm {
+			return nil
+		}
+	}
+
+	return apperror.Newf(apperror.CodeInvalidParameter, "不支持的推荐算法: %s", algorithm).
+		WithDetail("field", "algorithm").
+		WithDetail("available", algorithms)
+}
@@ -33,6 +33,18 @@ type DataSource interface {
 	Close() error
 }
 
+// Exporter 可导出全量数据的数据源，启动时用于训练推荐算法引擎
+type Exporter interface {
+	Export(ctx context.Context) (*Snapshot, error)
+}
+
+// Snapshot 数据源的全量数据
+type Snapshot struct {
+	Behaviors []UserBehaviorRecord
+	Items     []ItemRecord
+	Users     []UserRecord
+}
+
 // UserBehaviorRecord 用户行为记录
 type UserBehaviorRecord struct {
 	UserID    string
@@ -4,6 +4,7 @@ package datasource
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -300,15 +301,14 @@ func (m *MemoryDataSource) GetSimilarUsers(ctx context.Context, userID string, l
 	defer m.mu.RUnlock()
 	
 	// 简单的相似用户计算（基于用户行为数量）
-	targetUser, exists := m.users[userID]
-	if !exists {
+	if _, exists := m.users[userID]; !exists {
 		return []SimilarUserRecord{}, fmt.Errorf("用户不存在: %s", userID)
 	}
 	
 	targetBehaviors := len(m.userBehaviors[userID])
 	
 	var similarUsers []SimilarUserRecord
-	for uid, user := range m.users {
+	for uid := range m.users {
 		if uid == userID {
 			continue
 		}
@@ -339,6 +339,42 @@ func (m *MemoryDataSource) GetSimilarUsers(ctx context.Context, userID string, l
 	return similarUsers, nil
 }
 
+// Export 导出全部用户行为、物品与用户数据，按ID排序保证顺序稳定
+func (m *MemoryDataSource) Export(ctx context.Context) (*Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	snapshot := &Snapshot{
+		Items: make([]ItemRecord, 0, len(m.items)),
+		Users: make([]UserRecord, 0, len(m.users)),
+	}
+	
+	userIDs := make([]string, 0, len(m.userBehaviors))
+	for userID := range m.userBehaviors {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	for _, userID := range userIDs {
+		snapshot.Behaviors = append(snapshot.Behaviors, m.userBehaviors[userID]...)
+	}
+	
+	for _, item := range m.items {
+		snapshot.Items = append(snapshot.Items, item)
+	}
+	sort.Slice(snapshot.Items, func(i, j int) bool {
+		return snapshot.Items[i].ItemID < snapshot.Items[j].ItemID
+	})
+	
+	for _, user := range m.users {
+		snapshot.Users = append(snapshot.Users, user)
+	}
+	sort.Slice(snapshot.Users, func(i, j int) bool {
+		return snapshot.Users[i].UserID < snapshot.Users[j].UserID
+	})
+	
+	return snapshot, nil
+}
+
 // HealthCheck 健康检查
 func (m *MemoryDataSource) HealthCheck(ctx context.Context) error {
 	// 内存数据源总是健康的
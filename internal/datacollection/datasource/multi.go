@@ -4,17 +4,24 @@ package datasource
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 	
 	"github.com/sirupsen/logrus"
+
+	"github.com/guanguoyintao/luban/internal/infra/ann"
 )
 
+// 物品特征及用户偏好中存放向量的字段
+var embeddingKeys = []string{"embedding", "feature_vector"}
+
 // MultiDataSource 多数据源适配器
 type MultiDataSource struct {
-	sources []DataSource
-	log     *logrus.Logger
-	mu      sync.RWMutex
+	sources        []DataSource
+	embeddingIndex ann.Index
+	log            *logrus.Logger
+	mu             sync.RWMutex
 }
 
 // NewMultiDataSource 创建多数据源适配器
@@ -63,101 +70,118 @@ func (m *MultiRecall) GetResults() map[string]RecallResult {
 }
 
 // MergeResults 合并召回结果
+// 同一物品被多路召回时取最高召回分数，并记录所有命中的召回通道
 func (m *MultiRecall) MergeResults() []ItemRecord {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
+	// 按键排序，保证合并结果稳定
+	keys := make([]string, 0, len(m.results))
+	for key := range m.results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	
 	itemMap := make(map[string]ItemRecord)
+	order := make([]string, 0)
 	
-	for source, result := range m.results {
+	for _, key := range keys {
+		result := m.results[key]
+		source := result.Source
+		if source == "" {
+			source = key
+		}
+		
 		for _, item := range result.Items {
-			if existing, exists := itemMap[item.ItemID]; exists {
-				// 如果物品已存在，合并分数（取最大值）
-				if result.Score > existing.Popularity {
-					item.Popularity = result.Score
+			existing, exists := itemMap[item.ItemID]
+			if !exists {
+				// 复制元数据，避免修改数据源中的共享数据
+				metadata := make(map[string]interface{}, len(item.Metadata)+2)
+				for k, v := range item.Metadata {
+					metadata[k] = v
 				}
-				// 添加数据源信息
-				if item.Metadata == nil {
-					item.Metadata = make(map[string]interface{})
-				}
-				item.Metadata["sources"] = append(item.Metadata["sources"].([]string), source)
-			} else {
+				metadata["sources"] = []string{source}
+				// 保留物品原始热度，Popularity 字段改为记录召回分数
+				metadata["popularity"] = item.Popularity
+				item.Metadata = metadata
 				item.Popularity = result.Score
-				if item.Metadata == nil {
-					item.Metadata = make(map[string]interface{})
-				}
-				item.Metadata["sources"] = []string{source}
 				itemMap[item.ItemID] = item
+				order = append(order, item.ItemID)
+				continue
+			}
+			
+			if result.Score > existing.Popularity {
+				existing.Popularity = result.Score
+			}
+			sources := existing.Metadata["sources"].([]string)
+			if !containsString(sources, source) {
+				existing.Metadata["sources"] = append(sources, source)
 			}
+			itemMap[item.ItemID] = existing
 		}
 	}
 	
 	// 转换为切片
 	result := make([]ItemRecord, 0, len(itemMap))
-	for _, item := range itemMap {
-		result = append(result, item)
+	for _, itemID := range order {
+		result = append(result, itemMap[itemID])
 	}
 	
 	return result
 }
 
 // ParallelRecall 并行多路召回
+// 每个数据源的每种召回类型独立执行，单路失败不影响其他通道，全部失败时返回错误
 func (m *MultiDataSource) ParallelRecall(ctx context.Context, userID string, recallTypes []string) (*MultiRecall, error) {
+	m.mu.RLock()
+	sources := make([]DataSource, len(m.sources))
+	copy(sources, m.sources)
+	m.mu.RUnlock()
+	
 	m.log.WithFields(logrus.Fields{
 		"user_id":      userID,
 		"recall_types": recallTypes,
-		"source_count": len(m.sources),
+		"source_count": len(sources),
 	}).Info("开始并行多路召回")
 	
 	multiRecall := NewMultiRecall()
 	
-	// 创建错误通道和完成信号
-	errChan := make(chan error, len(m.sources))
-	doneChan := make(chan bool, len(m.sources))
+	var (
+		wg           sync.WaitGroup
+		countMu      sync.Mutex
+		successCount int
+		errorCount   int
+		lastErr      error
+	)
 	
 	// 并行执行各路召回
-	var wg sync.WaitGroup
-	for i, source := range m.sources {
-		wg.Add(1)
-		go func(idx int, src DataSource) {
-			defer wg.Done()
-			
-			result, err := m.executeRecall(ctx, src, userID, recallTypes)
-			if err != nil {
-				m.log.WithError(err).WithField("source", src.GetName()).Error("召回失败")
-				errChan <- err
-				return
-			}
-			
-			if result != nil {
-				multiRecall.AddResult(src.GetName(), *result)
-			}
-			doneChan <- true
-		}(i, source)
-	}
-	
-	// 等待所有召回完成
-	go func() {
-		wg.Wait()
-		close(errChan)
-		close(doneChan)
-	}()
-	
-	// 收集结果
-	successCount := 0
-	errorCount := 0
-	
-	for done := range doneChan {
-		if done {
-			successCount++
+	for _, source := range sources {
+		for _, recallType := range recallTypes {
+			wg.Add(1)
+			go func(src DataSource, recallType string) {
+				defer wg.Done()
+				
+				result, err := m.executeRecall(ctx, src, userID, recallType)
+				
+				countMu.Lock()
+				defer countMu.Unlock()
+				if err != nil {
+					m.log.WithError(err).WithFields(logrus.Fields{
+						"source":      src.GetName(),
+						"recall_type": recallType,
+					}).Error("召回失败")
+					errorCount++
+					lastErr = err
+					return
+				}
+				
+				multiRecall.AddResult(src.GetName()+":"+recallType, *result)
+				successCount++
+			}(source, recallType)
 		}
 	}
 	
-	for err := range errChan {
-		if err != nil {
-			errorCount++
-		}
-	}
+	wg.Wait()
 	
 	m.log.WithFields(logrus.Fields{
 		"success_count": successCount,
@@ -165,47 +189,45 @@ func (m *MultiDataSource) ParallelRecall(ctx context.Context, userID string, rec
 		"total_results": len(multiRecall.GetResults()),
 	}).Info("并行多路召回完成")
 	
+	if successCount == 0 && errorCount > 0 {
+		return nil, fmt.Errorf("所有召回通道均失败: %w", lastErr)
+	}
+	
 	return multiRecall, nil
 }
 
 // executeRecall 执行单路召回
-func (m *MultiDataSource) executeRecall(ctx context.Context, source DataSource, userID string, recallTypes []string) (*RecallResult, error) {
-	sourceName := source.GetName()
+func (m *MultiDataSource) executeRecall(ctx context.Context, source DataSource, userID string, recallType string) (*RecallResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	
 	// 根据召回类型执行不同的召回策略
-	for _, recallType := range recallTypes {
-		switch recallType {
-		case "popular":
-			return m.recallPopularItems(ctx, source, userID)
-		case "similar_users":
-			return m.recallSimilarUsersItems(ctx, source, userID)
-		case "recent_behavior":
-			return m.recallRecentBehaviorItems(ctx, source, userID)
-		case "category_preference":
-			return m.recallCategoryPreferenceItems(ctx, source, userID)
-		default:
-			m.log.WithFields(logrus.Fields{
-				"source":      sourceName,
-				"recall_type": recallType,
-			}).Warn("不支持的召回类型")
-		}
+	switch recallType {
+	case "popular":
+		return m.recallPopularItems(ctx, source, userID)
+	case "similar_users":
+		return m.recallSimilarUsersItems(ctx, source, userID)
+	case "recent_behavior":
+		return m.recallRecentBehaviorItems(ctx, source, userID)
+	case "category_preference":
+		return m.recallCategoryPreferenceItems(ctx, source, userID)
+	case "embedding":
+		return m.recallEmbeddingItems(ctx, source, userID)
+	default:
+		return nil, fmt.Errorf("不支持的召回类型: %s", recallType)
 	}
-	
-	return nil, fmt.Errorf("没有可用的召回策略")
 }
 
 // recallPopularItems 热门物品召回
 func (m *MultiDataSource) recallPopularItems(ctx context.Context, source DataSource, userID string) (*RecallResult, error) {
 	// 获取用户数据以了解用户偏好
-	userData, err := source.GetUserData(ctx, userID)
-	if err != nil {
-		return nil, fmt.Errorf("获取用户数据失败: %w", err)
-	}
-	
-	// 获取用户偏好的类别
+	// 用户数据缺失时退化为全局热门
 	var categories []string
-	if prefs, ok := userData.Preferences["categories"].([]string); ok {
-		categories = prefs
+	if userData, err := source.GetUserData(ctx, userID); err == nil {
+		if prefs, ok := userData.Preferences["categories"].([]string); ok {
+			categories = prefs
+		}
 	}
 	
 	var items []ItemRecord
@@ -383,6 +405,152 @@ func (m *MultiDataSource) recallCategoryPreferenceItems(ctx context.Context, sou
 	}, nil
 }
 
+// recallEmbeddingItems 向量召回
+// 用户向量取自偏好中的 embedding，缺失时使用近期交互物品向量的均值
+func (m *MultiDataSource) recallEmbeddingItems(ctx context.Context, source DataSource, userID string) (*RecallResult, error) {
+	index := m.GetEmbeddingIndex()
+	if index == nil || index.Len() == 0 {
+		return &RecallResult{
+			Items:    []ItemRecord{},
+			Score:    0.0,
+			Source:   "embedding",
+			Metadata: map[string]interface{}{
+				"strategy":  "embedding",
+				"reason":    "no_embedding_index",
+				"timestamp": time.Now(),
+			},
+		}, nil
+	}
+	
+	var userVector []float64
+	if userData, err := source.GetUserData(ctx, userID); err == nil {
+		userVector, _ = embeddingFromMap(userData.Preferences)
+	}
+	
+	// 已交互物品不再召回
+	interacted := make(map[string]bool)
+	behaviors, err := source.GetUserBehaviorData(ctx, userID, time.Now().Add(-30*24*time.Hour), time.Now())
+	if err != nil {
+		m.log.WithError(err).WithField("user_id", userID).Warn("获取用户行为数据失败")
+	}
+	var sum []float64
+	count := 0
+	for _, behavior := range behaviors {
+		if interacted[behavior.ItemID] {
+			continue
+		}
+		interacted[behavior.ItemID] = true
+		if vector, ok := index.Get(behavior.ItemID); ok {
+			if sum == nil {
+				sum = make([]float64, len(vector))
+			}
+			for i, value := range vector {
+				sum[i] += value
+			}
+			count++
+		}
+	}
+	if userVector == nil && count > 0 {
+		userVector = make([]float64, len(sum))
+		for i, value := range sum {
+			userVector[i] = value / float64(count)
+		}
+	}
+	
+	if userVector == nil {
+		return &RecallResult{
+			Items:    []ItemRecord{},
+			Score:    0.0,
+			Source:   "embedding",
+			Metadata: map[string]interface{}{
+				"strategy":  "embedding",
+				"reason":    "no_user_embedding",
+				"timestamp": time.Now(),
+			},
+		}, nil
+	}
+	
+	neighbors, err := index.Search(userVector, 20+len(interacted), ann.SearchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("向量检索失败: %w", err)
+	}
+	
+	similarities := make(map[string]float64, len(neighbors))
+	itemIDs := make([]string, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		if interacted[neighbor.ID] {
+			continue
+		}
+		similarities[neighbor.ID] = neighbor.Score
+		itemIDs = append(itemIDs, neighbor.ID)
+		if len(itemIDs) == 20 {
+			break
+		}
+	}
+	
+	var items []ItemRecord
+	if len(itemIDs) > 0 {
+		items, err = source.GetItemData(ctx, itemIDs)
+		if err != nil {
+			return nil, fmt.Errorf("获取物品数据失败: %w", err)
+		}
+	}
+	for i, item := range items {
+		metadata := make(map[string]interface{}, len(item.Metadata)+1)
+		for k, v := range item.Metadata {
+			metadata[k] = v
+		}
+		metadata["embedding_similarity"] = similarities[item.ItemID]
+		items[i].Metadata = metadata
+	}
+	
+	return &RecallResult{
+		Items:    items,
+		Score:    0.7, // 向量召回的基础分数
+		Source:   "embedding",
+		Metadata: map[string]interface{}{
+			"strategy":  "embedding",
+			"neighbors": len(itemIDs),
+			"timestamp": time.Now(),
+		},
+	}, nil
+}
+
+// SetEmbeddingIndex 设置向量召回使用的索引
+func (m *MultiDataSource) SetEmbeddingIndex(index ann.Index) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.embeddingIndex = index
+}
+
+// GetEmbeddingIndex 获取向量召回使用的索引
+func (m *MultiDataSource) GetEmbeddingIndex() ann.Index {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	return m.embeddingIndex
+}
+
+// Export 合并各数据源导出的全量数据，不支持导出的数据源跳过
+func (m *MultiDataSource) Export(ctx context.Context) (*Snapshot, error) {
+	merged := &Snapshot{}
+	for _, source := range m.sources {
+		exporter, ok := source.(Exporter)
+		if !ok {
+			continue
+		}
+		snapshot, err := exporter.Export(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("数据源 %s 导出数据失败: %w", source.GetName(), err)
+		}
+		merged.Behaviors = append(merged.Behaviors, snapshot.Behaviors...)
+		merged.Items = append(merged.Items, snapshot.Items...)
+		merged.Users = append(merged.Users, snapshot.Users...)
+	}
+	return merged, nil
+}
+
 // GetName 获取数据源名称
 func (m *MultiDataSource) GetName() string {
 	return "multi_data_source"
@@ -408,4 +576,47 @@ func (m *MultiDataSource) Close() error {
 		}
 	}
 	return lastErr
-}
\ No newline at end of file
+}
+
+// embeddingFromMap 从特征或偏好中提取向量
+func embeddingFromMap(values map[string]interface{}) ([]float64, bool) {
+	for _, key := range embeddingKeys {
+		switch v := values[key].(type) {
+		case []float64:
+			if len(v) > 0 {
+				return v, true
+			}
+		case []float32:
+			if len(v) > 0 {
+				vector := make([]float64, len(v))
+				for i, value := range v {
+					vector[i] = float64(value)
+				}
+				return vector, true
+			}
+		case []interface{}:
+			vector := make([]float64, 0, len(v))
+			for _, value := range v {
+				number, ok := value.(float64)
+				if !ok {
+					return nil, false
+				}
+				vector = append(vector, number)
+			}
+			if len(vector) > 0 {
+				return vector, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,642 @@
+package ann
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// 删除节点达到该数量且多于有效节点时自动压缩
+const compactMinDeleted = 128
+
+// HNSWConfig HNSW索引配置
+type HNSWConfig struct {
+	Dimension      int    // 向量维度，为0时由第一个向量确定
+	Metric         Metric // 相似度度量
+	M              int    // 每层最大连接数，第0层为2M
+	EfConstruction int    // 构建时的检索宽度
+	EfSearch       int    // 查询时的检索宽度
+	Seed           int64  // 随机种子
+}
+
+// DefaultHNSWConfig 默认HNSW索引配置
+func DefaultHNSWConfig() HNSWConfig {
+	return HNSWConfig{
+		Metric:         MetricCosine,
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+		Seed:           42,
+	}
+}
+
+// HNSWIndex 分层可导航小世界图索引
+// 删除采用标记方式，被删除的节点保留在图中用于导航但不会出现在结果中
+type HNSWIndex struct {
+	mu         sync.RWMutex
+	config     HNSWConfig
+	nodes      []*hnswNode
+	ids        map[string]int              // 向量ID到节点的映射，仅包含有效节点
+	categories map[string]map[int]struct{} // 类别到有效节点的映射
+	entryPoint int
+	maxLevel   int
+	deleted    int
+	levelMult  float64
+	rng        *rand.Rand
+}
+
+// 图节点
+type hnswNode struct {
+	ID       string
+	Category string
+	Vector   []float64
+	Friends  [][]int // 每层的邻居节点
+	Deleted  bool
+}
+
+// 检索候选
+type candidate struct {
+	node     int
+	distance float64
+}
+
+// NewHNSWIndex 创建HNSW索引
+func NewHNSWIndex(config HNSWConfig) (*HNSWIndex, error) {
+	defaults := DefaultHNSWConfig()
+	if config.Metric == "" {
+		config.Metric = defaults.Metric
+	}
+	if config.Metric != MetricCosine && config.Metric != MetricInnerProduct {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMetric, config.Metric)
+	}
+	if config.M <= 1 {
+		config.M = defaults.M
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = defaults.EfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = defaults.EfSearch
+	}
+
+	return &HNSWIndex{
+		config:     config,
+		ids:        make(map[string]int),
+		categories: make(map[string]map[int]struct{}),
+		entryPoint: -1,
+		levelMult:  1 / math.Log(float64(config.M)),
+		rng:        rand.New(rand.NewSource(config.Seed)),
+	}, nil
+}
+
+// Insert 插入向量
+func (h *HNSWIndex) Insert(id string, vector []float64, category string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.ids[id]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateID, id)
+	}
+	return h.insert(id, vector, category)
+}
+
+// Upsert 插入或替换向量，ID已存在时原地更新节点，不产生删除标记
+func (h *HNSWIndex) Upsert(id string, vector []float64, category string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.checkVector(vector); err != nil {
+		return err
+	}
+	if index, exists := h.ids[id]; exists {
+		h.update(index, vector, category)
+		return nil
+	}
+	return h.insert(id, vector, category)
+}
+
+// Delete 删除向量
+func (h *HNSWIndex) Delete(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.ids[id]; !exists {
+		return false
+	}
+	h.delete(id)
+
+	if h.deleted >= compactMinDeleted && h.deleted > len(h.ids) {
+		h.compact()
+	}
+	return true
+}
+
+// Search 检索最相近的k个向量
+func (h *HNSWIndex) Search(query []float64, k int, options SearchOptions) ([]SearchResult, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if k <= 0 || len(h.ids) == 0 {
+		return []SearchResult{}, nil
+	}
+	if err := h.checkVector(query); err != nil {
+		return nil, err
+	}
+	query = h.prepare(query)
+
+	ef := options.Ef
+	if ef <= 0 {
+		ef = h.config.EfSearch
+	}
+	if ef < k {
+		ef = k
+	}
+
+	accept := func(node *hnswNode) bool {
+		return !node.Deleted && (options.Category == "" || node.Category == options.Category)
+	}
+
+	// 过滤后的候选较少时直接精确检索
+	available := len(h.ids)
+	if options.Category != "" {
+		available = len(h.categories[options.Category])
+		if available == 0 {
+			return []SearchResult{}, nil
+		}
+		if available <= ef*8 {
+			return h.exactSearch(query, k, accept), nil
+		}
+	}
+
+	entry := h.descend(query, 0)
+	found := h.searchLayer(query, []candidate{entry}, ef, 0, accept)
+
+	// 图检索结果不足时退化为精确检索
+	if len(found) < k && len(found) < available {
+		return h.exactSearch(query, k, accept), nil
+	}
+
+	if len(found) > k {
+		found = found[:k]
+	}
+	return h.toResults(found), nil
+}
+
+// Get 获取已索引的向量，余弦度量下返回归一化后的向量
+func (h *HNSWIndex) Get(id string) ([]float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	index, exists := h.ids[id]
+	if !exists {
+		return nil, false
+	}
+	return append([]float64(nil), h.nodes[index].Vector...), true
+}
+
+// Len 获取有效向量数量
+func (h *HNSWIndex) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.ids)
+}
+
+// Reset 清空全部向量，向量维度重新由下一个插入的向量确定
+func (h *HNSWIndex) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nodes = nil
+	h.ids = make(map[string]int)
+	h.categories = make(map[string]map[int]struct{})
+	h.entryPoint = -1
+	h.maxLevel = 0
+	h.deleted = 0
+	h.config.Dimension = 0
+}
+
+// Compact 压缩索引，重建图并移除已删除的节点
+func (h *HNSWIndex) Compact() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.compact()
+}
+
+// GetConfig 获取配置
+func (h *HNSWIndex) GetConfig() HNSWConfig {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.config
+}
+
+// GetStats 获取索引统计信息
+func (h *HNSWIndex) GetStats() map[string]interface{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return map[string]interface{}{
+		"type":       "hnsw",
+		"metric":     string(h.config.Metric),
+		"dimension":  h.config.Dimension,
+		"size":       len(h.ids),
+		"deleted":    h.deleted,
+		"categories": len(h.categories),
+		"max_level":  h.maxLevel,
+	}
+}
+
+// 插入节点，调用方需持有写锁
+func (h *HNSWIndex) insert(id string, vector []float64, category string) error {
+	if err := h.checkVector(vector); err != nil {
+		return err
+	}
+	if h.config.Dimension == 0 {
+		h.config.Dimension = len(vector)
+	}
+
+	level := int(-math.Log(1-h.rng.Float64()) * h.levelMult)
+	node := &hnswNode{
+		ID:       id,
+		Category: category,
+		Vector:   h.prepare(vector),
+		Friends:  make([][]int, level+1),
+	}
+	index := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = index
+	if h.categories[category] == nil {
+		h.categories[category] = make(map[int]struct{})
+	}
+	h.categories[category][index] = struct{}{}
+
+	if h.entryPoint < 0 {
+		h.entryPoint = index
+		h.maxLevel = level
+		return nil
+	}
+
+	// 自顶层贪心下降到新节点所在的最高层
+	entries := []candidate{h.descend(node.Vector, level)}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		found := h.searchLayer(node.Vector, entries, h.config.EfConstruction, l, nil)
+		neighbors := h.selectNeighbors(found, h.config.M)
+
+		node.Friends[l] = make([]int, 0, len(neighbors))
+		for _, neighbor := range neighbors {
+			node.Friends[l] = append(node.Friends[l], neighbor.node)
+			h.connect(neighbor.node, index, l)
+		}
+		entries = found
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entryPoint = index
+	}
+	return nil
+}
+
+// 原地更新节点的向量和类别，并在节点所在各层重新选择邻居，调用方需持有写锁
+// 其他节点指向该节点的旧边保留，仍可用于导航
+func (h *HNSWIndex) update(index int, vector []float64, category string) {
+	node := h.nodes[index]
+	if node.Category != category {
+		delete(h.categories[node.Category], index)
+		if len(h.categories[node.Category]) == 0 {
+			delete(h.categories, node.Category)
+		}
+		if h.categories[category] == nil {
+			h.categories[category] = make(map[int]struct{})
+		}
+		h.categories[category][index] = struct{}{}
+		node.Category = category
+	}
+	node.Vector = h.prepare(vector)
+
+	level := len(node.Friends) - 1
+	entries := []candidate{h.descend(node.Vector, level)}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		found := h.searchLayer(node.Vector, entries, h.config.EfConstruction+1, l, nil)
+		others := make([]candidate, 0, len(found))
+		for _, c := range found {
+			if c.node != index {
+				others = append(others, c)
+			}
+		}
+		neighbors := h.selectNeighbors(others, h.config.M)
+
+		node.Friends[l] = make([]int, 0, len(neighbors))
+		for _, neighbor := range neighbors {
+			node.Friends[l] = append(node.Friends[l], neighbor.node)
+			if !containsNode(h.friends(neighbor.node, l), index) {
+				h.connect(neighbor.node, index, l)
+			}
+		}
+		entries = found
+	}
+}
+
+// 标记删除节点，调用方需持有写锁
+func (h *HNSWIndex) delete(id string) {
+	index := h.ids[id]
+	node := h.nodes[index]
+	node.Deleted = true
+	delete(h.ids, id)
+	delete(h.categories[node.Category], index)
+	if len(h.categories[node.Category]) == 0 {
+		delete(h.categories, node.Category)
+	}
+	h.deleted++
+}
+
+// 用有效节点重建索引，调用方需持有写锁
+func (h *HNSWIndex) compact() {
+	nodes := h.nodes
+	h.nodes = nil
+	h.ids = make(map[string]int)
+	h.categories = make(map[string]map[int]struct{})
+	h.entryPoint = -1
+	h.maxLevel = 0
+	h.deleted = 0
+
+	for _, node := range nodes {
+		if node.Deleted {
+			continue
+		}
+		// 节点向量已预处理，重复归一化结果不变
+		_ = h.insert(node.ID, node.Vector, node.Category)
+	}
+}
+
+// 为节点增加一条边，超出连接上限时重新选择邻居
+func (h *HNSWIndex) connect(from int, to int, level int) {
+	node := h.nodes[from]
+	node.Friends[level] = append(node.Friends[level], to)
+
+	limit := h.config.M
+	if level == 0 {
+		limit = 2 * h.config.M
+	}
+	if len(node.Friends[level]) <= limit {
+		return
+	}
+
+	candidates := make([]candidate, 0, len(node.Friends[level]))
+	for _, friend := range node.Friends[level] {
+		candidates = append(candidates, candidate{
+			node:     friend,
+			distance: h.distance(node.Vector, h.nodes[friend].Vector),
+		})
+	}
+	sortCandidates(candidates)
+
+	selected := h.selectNeighbors(candidates, limit)
+	node.Friends[level] = node.Friends[level][:0]
+	for _, neighbor := range selected {
+		node.Friends[level] = append(node.Friends[level], neighbor.node)
+	}
+}
+
+// 启发式选择邻居：优先保留彼此分散的候选，不足时用剩余最近候选补齐
+// candidates 需按距离升序排列
+func (h *HNSWIndex) selectNeighbors(candidates []candidate, limit int) []candidate {
+	if len(candidates) <= limit {
+		return candidates
+	}
+
+	selected := make([]candidate, 0, limit)
+	skipped := make([]candidate, 0)
+	for _, c := range candidates {
+		if len(selected) >= limit {
+			break
+		}
+		diverse := true
+		for _, s := range selected {
+			if h.distance(h.nodes[c.node].Vector, h.nodes[s.node].Vector) < c.distance {
+				diverse = false
+				break
+			}
+		}
+		if diverse {
+			selected = append(selected, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+
+	for _, c := range skipped {
+		if len(selected) >= limit {
+			break
+		}
+		selected = append(selected, c)
+	}
+	return selected
+}
+
+// 从入口节点贪心下降到指定层，返回该层的入口候选
+func (h *HNSWIndex) descend(query []float64, target int) candidate {
+	current := candidate{
+		node:     h.entryPoint,
+		distance: h.distance(query, h.nodes[h.entryPoint].Vector),
+	}
+	for l := h.maxLevel; l > target; l-- {
+		changed := true
+		for changed {
+			changed = false
+			for _, friend := range h.friends(current.node, l) {
+				distance := h.distance(query, h.nodes[friend].Vector)
+				if distance < current.distance {
+					current = candidate{node: friend, distance: distance}
+					changed = true
+				}
+			}
+		}
+	}
+	return current
+}
+
+// 在指定层做宽度为ef的最佳优先检索，返回满足accept的节点（按距离升序）
+// accept 为空时接受所有节点
+func (h *HNSWIndex) searchLayer(query []float64, entries []candidate, ef int, level int, accept func(*hnswNode) bool) []candidate {
+	visited := make(map[int]bool, ef*4)
+	pending := &minHeap{}
+	nearest := &maxHeap{}
+	matched := &maxHeap{}
+
+	consider := func(c candidate) {
+		if accept == nil || accept(h.nodes[c.node]) {
+			if matched.Len() < ef || c.distance < (*matched)[0].distance {
+				heap.Push(matched, c)
+				if matched.Len() > ef {
+					heap.Pop(matched)
+				}
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if visited[entry.node] {
+			continue
+		}
+		visited[entry.node] = true
+		heap.Push(pending, entry)
+		heap.Push(nearest, entry)
+		consider(entry)
+	}
+	for nearest.Len() > ef {
+		heap.Pop(nearest)
+	}
+
+	for pending.Len() > 0 {
+		current := heap.Pop(pending).(candidate)
+		if nearest.Len() >= ef && current.distance > (*nearest)[0].distance {
+			break
+		}
+
+		for _, friend := range h.friends(current.node, level) {
+			if visited[friend] {
+				continue
+			}
+			visited[friend] = true
+
+			next := candidate{node: friend, distance: h.distance(query, h.nodes[friend].Vector)}
+			if nearest.Len() < ef || next.distance < (*nearest)[0].distance {
+				heap.Push(pending, next)
+				heap.Push(nearest, next)
+				if nearest.Len() > ef {
+					heap.Pop(nearest)
+				}
+			}
+			consider(next)
+		}
+	}
+
+	result := make([]candidate, matched.Len())
+	copy(result, *matched)
+	sortCandidates(result)
+	return result
+}
+
+// 遍历全部有效节点的精确检索
+func (h *HNSWIndex) exactSearch(query []float64, k int, accept func(*hnswNode) bool) []SearchResult {
+	found := make([]candidate, 0)
+	for index, node := range h.nodes {
+		if accept(node) {
+			found = append(found, candidate{node: index, distance: h.distance(query, node.Vector)})
+		}
+	}
+	sortCandidates(found)
+	if len(found) > k {
+		found = found[:k]
+	}
+	return h.toResults(found)
+}
+
+// 获取节点在指定层的邻居
+func (h *HNSWIndex) friends(node int, level int) []int {
+	friends := h.nodes[node].Friends
+	if level >= len(friends) {
+		return nil
+	}
+	return friends[level]
+}
+
+// 距离：余弦度量下向量已归一化，距离为1-cos；内积度量下为负内积
+func (h *HNSWIndex) distance(a []float64, b []float64) float64 {
+	if h.config.Metric == MetricInnerProduct {
+		return -dot(a, b)
+	}
+	return 1 - dot(a, b)
+}
+
+// 将距离转换为相似度
+func (h *HNSWIndex) similarity(distance float64) float64 {
+	if h.config.Metric == MetricInnerProduct {
+		return -distance
+	}
+	return 1 - distance
+}
+
+// 预处理向量，余弦度量下归一化
+func (h *HNSWIndex) prepare(vector []float64) []float64 {
+	if h.config.Metric == MetricCosine {
+		return normalize(vector)
+	}
+	return append([]float64(nil), vector...)
+}
+
+// 校验向量
+func (h *HNSWIndex) checkVector(vector []float64) error {
+	if len(vector) == 0 {
+		return ErrEmptyVector
+	}
+	if h.config.Dimension > 0 && len(vector) != h.config.Dimension {
+		return fmt.Errorf("%w: 期望%d，实际%d", ErrDimensionMismatch, h.config.Dimension, len(vector))
+	}
+	return nil
+}
+
+// 转换为检索结果
+func (h *HNSWIndex) toResults(found []candidate) []SearchResult {
+	results := make([]SearchResult, 0, len(found))
+	for _, c := range found {
+		node := h.nodes[c.node]
+		results = append(results, SearchResult{
+			ID:       node.ID,
+			Category: node.Category,
+			Score:    h.similarity(c.distance),
+		})
+	}
+	return results
+}
+
+// 判断邻居列表是否包含指定节点
+func containsNode(friends []int, node int) bool {
+	for _, friend := range friends {
+		if friend == node {
+			return true
+		}
+	}
+	return false
+}
+
+// 按距离升序排序
+func sortCandidates(candidates []candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+}
+
+// 按距离排序的最小堆
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// 按距离排序的最大堆
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].distance > h[j].distance }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,88 @@
+// Package ann 近似最近邻向量索引
+package ann
+
+import (
+	"errors"
+	"math"
+)
+
+// Metric 向量相似度度量
+type Metric string
+
+const (
+	MetricCosine       Metric = "cosine"        // 余弦相似度
+	MetricInnerProduct Metric = "inner_product" // 内积
+)
+
+var (
+	ErrEmptyVector       = errors.New("向量为空")
+	ErrDimensionMismatch = errors.New("向量维度不匹配")
+	ErrDuplicateID       = errors.New("向量ID已存在")
+	ErrUnsupportedMetric = errors.New("不支持的相似度度量")
+)
+
+// SearchOptions 检索选项
+type SearchOptions struct {
+	Category string // 仅返回该类别的向量，为空时不过滤
+	Ef       int    // 检索宽度，为0时使用索引默认值
+}
+
+// SearchResult 检索结果
+type SearchResult struct {
+	ID       string
+	Category string
+	Score    float64 // 相似度，越大越相近
+}
+
+// Index 向量索引接口
+type Index interface {
+	// 插入向量，ID已存在时返回 ErrDuplicateID
+	Insert(id string, vector []float64, category string) error
+
+	// 插入或替换向量
+	Upsert(id string, vector []float64, category string) error
+
+	// 删除向量，返回是否存在
+	Delete(id string) bool
+
+	// 检索最相近的k个向量
+	Search(query []float64, k int, options SearchOptions) ([]SearchResult, error)
+
+	// 获取已索引的向量
+	Get(id string) ([]float64, bool)
+
+	// 获取向量数量
+	Len() int
+
+	// 清空全部向量，向量维度变化时使用
+	Reset()
+
+	// 持久化到文件
+	Save(path string) error
+
+	// 获取索引统计信息
+	GetStats() map[string]interface{}
+}
+
+// 点积
+func dot(a []float64, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// 归一化为单位向量，零向量原样返回
+func normalize(vector []float64) []float64 {
+	norm := math.Sqrt(dot(vector, vector))
+	normalized := make([]float64, len(vector))
+	if norm == 0 {
+		copy(normalized, vector)
+		return normalized
+	}
+	for i, value := range vector {
+		normalized[i] = value / norm
+	}
+	return normalized
+}
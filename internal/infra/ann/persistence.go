@@ -0,0 +1,127 @@
+package ann
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// 持久化格式版本
+const snapshotVersion = 1
+
+// 索引快照
+type hnswSnapshot struct {
+	Version    int
+	Config     HNSWConfig
+	Nodes      []*hnswNode
+	EntryPoint int
+	MaxLevel   int
+}
+
+// Save 持久化索引到文件，先写临时文件再原子替换
+func (h *HNSWIndex) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建索引目录失败: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建索引临时文件失败: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	if err := h.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入索引文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("替换索引文件失败: %w", err)
+	}
+	return nil
+}
+
+// Encode 序列化索引
+func (h *HNSWIndex) Encode(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshot := hnswSnapshot{
+		Version:    snapshotVersion,
+		Config:     h.config,
+		Nodes:      h.nodes,
+		EntryPoint: h.entryPoint,
+		MaxLevel:   h.maxLevel,
+	}
+	if err := gob.NewEncoder(w).Encode(&snapshot); err != nil {
+		return fmt.Errorf("序列化索引失败: %w", err)
+	}
+	return nil
+}
+
+// LoadHNSWIndex 从文件加载索引
+func LoadHNSWIndex(path string) (*HNSWIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开索引文件失败: %w", err)
+	}
+	defer file.Close()
+
+	return DecodeHNSWIndex(file)
+}
+
+// DecodeHNSWIndex 反序列化索引
+func DecodeHNSWIndex(r io.Reader) (*HNSWIndex, error) {
+	var snapshot hnswSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("反序列化索引失败: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("不支持的索引版本: %d", snapshot.Version)
+	}
+
+	index, err := NewHNSWIndex(snapshot.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, node := range snapshot.Nodes {
+		if node == nil {
+			return nil, fmt.Errorf("索引文件损坏: 节点 %d 为空", i)
+		}
+		for _, friends := range node.Friends {
+			for _, friend := range friends {
+				if friend < 0 || friend >= len(snapshot.Nodes) {
+					return nil, fmt.Errorf("索引文件损坏: 节点 %s 的邻居越界", node.ID)
+				}
+			}
+		}
+		if node.Deleted {
+			index.deleted++
+			continue
+		}
+		index.ids[node.ID] = i
+		if index.categories[node.Category] == nil {
+			index.categories[node.Category] = make(map[int]struct{})
+		}
+		index.categories[node.Category][i] = struct{}{}
+	}
+
+	if len(snapshot.Nodes) > 0 && (snapshot.EntryPoint < 0 || snapshot.EntryPoint >= len(snapshot.Nodes)) {
+		return nil, fmt.Errorf("索引文件损坏: 入口节点越界")
+	}
+
+	index.nodes = snapshot.Nodes
+	index.entryPoint = snapshot.EntryPoint
+	index.maxLevel = snapshot.MaxLevel
+	// 加载后继续插入时使用不同的随机序列
+	index.rng = rand.New(rand.NewSource(snapshot.Config.Seed + int64(len(snapshot.Nodes))))
+	return index, nil
+}
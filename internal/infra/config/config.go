@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -21,6 +22,7 @@ type ConfigManager interface {
 	GetInt(key string) int
 	GetBool(key string) bool
 	GetFloat64(key string) float64
+	GetDuration(key string) time.Duration
 	GetStringSlice(key string) []string
 	GetStringMap(key string) map[string]interface{}
 	GetStringMapString(key string) map[string]string
@@ -118,6 +120,11 @@ func (m *ViperConfigManager) GetFloat64(key string) float64 {
 	return m.viper.GetFloat64(key)
 }
 
+// GetDuration 获取时长配置
+func (m *ViperConfigManager) GetDuration(key string) time.Duration {
+	return m.viper.GetDuration(key)
+}
+
 // GetStringSlice 获取字符串切片配置
 func (m *ViperConfigManager) GetStringSlice(key string) []string {
 	return m.viper.GetStringSlice(key)
@@ -301,7 +308,7 @@ func validateAlgorithmList(value interface{}) error {
 		return fmt.Errorf("至少需要启用一个算法")
 	}
 	
-	validAlgorithms := []string{"collaborative_filtering", "content_based_filtering", "hybrid_filtering", "deep_learning", "popularity", "rule_based"}
+	validAlgorithms := []string{"collaborative_filtering", "content_based_filtering", "hybrid_filtering", "deep_learning", "popularity", "rule_based", "matrix_factorization", "bpr", "session_based", "association_rules", "cold_start"}
 	
 	for _, algorithm := range algorithms {
 		algStr, ok := algorithm.(string)
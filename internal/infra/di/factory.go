@@ -1,4 +1,79 @@
 // Package di 工厂模式实现
 package di
 
-import ()
\ No newline at end of file
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/guanguoyintao/luban/internal/datacollection/datasource"
+	"github.com/guanguoyintao/luban/internal/dataprocessing"
+	"github.com/guanguoyintao/luban/internal/infra/ann"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
+)
+
+// NewDataSourceFactory 创建数据源工厂
+func NewDataSourceFactory(logger *logrus.Logger) *datasource.DataSourceFactory {
+	return datasource.NewDataSourceFactory(logger)
+}
+
+// NewMemoryDataSourceConfig 创建内存数据源配置
+func NewMemoryDataSourceConfig() datasource.DataSourceConfig {
+	return datasource.DataSourceConfig{
+		Type: datasource.DataSourceTypeMemory,
+		Name: "memory_data_source",
+		Options: map[string]interface{}{
+			"max_items": 10000,
+			"ttl":       3600,
+		},
+	}
+}
+
+// NewMultiDataSource 创建多数据源适配器
+func NewMultiDataSource(factory *datasource.DataSourceFactory, config datasource.DataSourceConfig, logger *logrus.Logger) (*datasource.MultiDataSource, error) {
+	// 创建内存数据源
+	memorySource, err := factory.CreateDataSource(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建多数据源适配器，并挂载向量召回索引
+	multiDataSource := datasource.NewMultiDataSource([]datasource.DataSource{memorySource}, logger)
+	embeddingIndex, err := ann.NewHNSWIndex(ann.DefaultHNSWConfig())
+	if err != nil {
+		return nil, err
+	}
+	multiDataSource.SetEmbeddingIndex(embeddingIndex)
+
+	return multiDataSource, nil
+}
+
+// NewRankingStrategies 创建排序策略
+func NewRankingStrategies() []strategy.RankingStrategy {
+	return strategy.BuildDefaultStrategies()
+}
+
+// NewRecommendationEngine 创建推荐引擎
+func NewRecommendationEngine(
+	logger *logrus.Logger,
+	dataSource *datasource.MultiDataSource,
+	dataProcessor dataprocessing.DataProcessor,
+	engine recommendation.RecommendationEngine,
+	rankingStrategies []strategy.RankingStrategy,
+) *recommendation.SimpleRecommendationEngine {
+	// 双塔模型训练后将物品向量写入向量召回索引
+	if manager, ok := engine.(*recommendation.RecommendationEngineManager); ok {
+		if deepLearning, exists := manager.GetEngine(recommendation.AlgorithmDeepLearning); exists {
+			if twoTower, ok := deepLearning.(*recommendation.TwoTowerAdapter); ok && dataSource.GetEmbeddingIndex() != nil {
+				twoTower.SetEmbeddingIndex(dataSource.GetEmbeddingIndex())
+			}
+		}
+	}
+
+	return recommendation.NewSimpleRecommendationEngine(
+		logger,
+		dataSource,
+		dataProcessor,
+		engine,
+		rankingStrategies,
+	)
+}
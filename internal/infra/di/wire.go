@@ -4,11 +4,14 @@
 package di
 
 import (
+	"os"
+
 	"github.com/google/wire"
 	"github.com/sirupsen/logrus"
 
+	"github.com/guanguoyintao/luban/internal/api/grpcapi"
+	"github.com/guanguoyintao/luban/internal/api/httpapi"
 	"github.com/guanguoyintao/luban/internal/application"
-	"github.com/guanguoyintao/luban/internal/datacollection"
 	"github.com/guanguoyintao/luban/internal/datacollection/datasource"
 	"github.com/guanguoyintao/luban/internal/dataprocessing"
 	"github.com/guanguoyintao/luban/internal/dataprocessing/chain"
@@ -32,7 +35,6 @@ var ProviderSet = wire.NewSet(
 	NewDataSourceFactory,
 	NewMemoryDataSourceConfig,
 	NewMultiDataSource,
-	wire.Bind(new(datasource.DataSource), new(*datasource.MultiDataSource)),
 
 	// 数据处理层 - 责任链模式
 	dataprocessing.NewMemoryDataProcessor,
@@ -45,6 +47,10 @@ var ProviderSet = wire.NewSet(
 	NewRecommendationEngine,
 	wire.Bind(new(domain.RecommendationService), new(*recommendation.SimpleRecommendationEngine)),
 
+	// 推荐引擎管理器 - 多算法引擎
+	recommendation.NewRecommendationEngineManager,
+	wire.Bind(new(recommendation.RecommendationEngine), new(*recommendation.RecommendationEngineManager)),
+
 	// 排序策略
 	NewRankingStrategies,
 
@@ -55,6 +61,10 @@ var ProviderSet = wire.NewSet(
 	application.NewRecommendationPresenter,
 	wire.Bind(new(application.RecommendationUseCase), new(*application.RecommendationPresenter)),
 
+	// 接入层
+	httpapi.NewServer,
+	grpcapi.NewServer,
+
 	// 插件管理
 	NewPluginManager,
 	wire.Bind(new(plugin.PluginManager), new(*plugin.PluginManager)),
@@ -70,35 +80,6 @@ func NewLogger() *logrus.Logger {
 	return logger
 }
 
-// NewDataSourceFactory 创建数据源工厂
-func NewDataSourceFactory(logger *logrus.Logger) *datasource.DataSourceFactory {
-	return datasource.NewDataSourceFactory(logger)
-}
-
-// NewMemoryDataSourceConfig 创建内存数据源配置
-func NewMemoryDataSourceConfig() datasource.DataSourceConfig {
-	return datasource.DataSourceConfig{
-		Type: datasource.DataSourceTypeMemory,
-		Name: "memory_data_source",
-		Options: map[string]interface{}{
-			"max_items": 10000,
-			"ttl":       3600,
-		},
-	}
-}
-
-// NewMultiDataSource 创建多数据源适配器
-func NewMultiDataSource(factory *datasource.DataSourceFactory, config datasource.DataSourceConfig, logger *logrus.Logger) (*datasource.MultiDataSource, error) {
-	// 创建内存数据源
-	memorySource, err := factory.CreateDataSource(config)
-	if err != nil {
-		return nil, err
-	}
-
-	// 创建多数据源适配器
-	return datasource.NewMultiDataSource([]datasource.DataSource{memorySource}, logger), nil
-}
-
 // NewProcessingChainBuilder 创建责任链构建器
 func NewProcessingChainBuilder() *chain.ChainBuilder {
 	return chain.NewChainBuilder()
@@ -114,26 +95,6 @@ func NewProcessingChain(builder *chain.ChainBuilder) *chain.ProcessingChain {
 		Build()
 }
 
-// NewRankingStrategies 创建排序策略
-func NewRankingStrategies() []strategy.RankingStrategy {
-	return strategy.BuildDefaultStrategies()
-}
-
-// NewRecommendationEngine 创建推荐引擎
-func NewRecommendationEngine(
-	logger *logrus.Logger,
-	dataSource datasource.DataSource,
-	dataProcessor dataprocessing.DataProcessor,
-	rankingStrategies []strategy.RankingStrategy,
-) *recommendation.SimpleRecommendationEngine {
-	return recommendation.NewSimpleRecommendationEngine(
-		logger,
-		dataSource,
-		dataProcessor,
-		rankingStrategies,
-	)
-}
-
 // NewPluginManager 创建插件管理器
 func NewPluginManager(logger *logrus.Logger) *plugin.PluginManager {
 	return plugin.NewPluginManager(logger)
@@ -147,13 +108,18 @@ func InitializeApp() (*Application, error) {
 
 // Application 应用程序容器
 type Application struct {
-	ConfigManager     config.ConfigManager
-	DataSourceFactory *datasource.DataSourceFactory
-	ProcessingChain   *chain.ProcessingChain
-	RankingStrategies []strategy.RankingStrategy
-	RecommendationSvc domain.RecommendationService
-	PluginManager     *plugin.PluginManager
-	Logger            *logrus.Logger
+	ConfigManager         config.ConfigManager
+	DataSourceFactory     *datasource.DataSourceFactory
+	ProcessingChain       *chain.ProcessingChain
+	RankingStrategies     []strategy.RankingStrategy
+	RecommendationSvc     domain.RecommendationService
+	RecommendationUseCase application.RecommendationUseCase
+	RecommendationEngine  recommendation.RecommendationEngine
+	HTTPServer            *httpapi.Server
+	GRPCServer            *grpcapi.Server
+	PluginManager         *plugin.PluginManager
+	Logger                *logrus.Logger
+	LogFiles              []*os.File // 运行期间打开的日志文件，关闭应用时同步并关闭
 }
 
 // NewApplication 创建应用程序
@@ -163,16 +129,24 @@ func NewApplication(
 	processingChain *chain.ProcessingChain,
 	rankingStrategies []strategy.RankingStrategy,
 	recommendationSvc domain.RecommendationService,
+	recommendationUseCase application.RecommendationUseCase,
+	recommendationEngine recommendation.RecommendationEngine,
+	httpServer *httpapi.Server,
+	grpcServer *grpcapi.Server,
 	pluginManager *plugin.PluginManager,
 	logger *logrus.Logger,
 ) *Application {
 	return &Application{
-		ConfigManager:     configManager,
-		DataSourceFactory: dataSourceFactory,
-		ProcessingChain:   processingChain,
-		RankingStrategies: rankingStrategies,
-		RecommendationSvc: recommendationSvc,
-		PluginManager:     pluginManager,
-		Logger:            logger,
+		ConfigManager:         configManager,
+		DataSourceFactory:     dataSourceFactory,
+		ProcessingChain:       processingChain,
+		RankingStrategies:     rankingStrategies,
+		RecommendationSvc:     recommendationSvc,
+		RecommendationUseCase: recommendationUseCase,
+		RecommendationEngine:  recommendationEngine,
+		HTTPServer:            httpServer,
+		GRPCServer:            grpcServer,
+		PluginManager:         pluginManager,
+		Logger:                logger,
 	}
 }
@@ -7,13 +7,19 @@
 package di
 
 import (
+	"os"
+
 	"github.com/sirupsen/logrus"
 	
-	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/api/grpcapi"
+	"github.com/guanguoyintao/luban/internal/api/httpapi"
+	"github.com/guanguoyintao/luban/internal/application"
+	"github.com/guanguoyintao/luban/internal/datacollection/datasource"
 	"github.com/guanguoyintao/luban/internal/dataprocessing"
 	"github.com/guanguoyintao/luban/internal/domain"
 	"github.com/guanguoyintao/luban/internal/infra/config"
 	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
 )
 
 // NewLogger 创建日志记录器
@@ -25,22 +31,45 @@ func NewLogger() *logrus.Logger {
 
 // Application 应用程序容器
 type Application struct {
-	ConfigManager      config.ConfigManager
-	RecommendationSvc  domain.RecommendationService
-	Logger            *logrus.Logger
+	ConfigManager         config.ConfigManager
+	DataSourceFactory     *datasource.DataSourceFactory
+	RankingStrategies     []strategy.RankingStrategy
+	RecommendationSvc     domain.RecommendationService
+	RecommendationUseCase application.RecommendationUseCase
+	RecommendationEngine  recommendation.RecommendationEngine
+	HTTPServer            *httpapi.Server
+	GRPCServer            *grpcapi.Server
+	Logger                *logrus.Logger
+	LogFiles              []*os.File // 运行期间打开的日志文件，关闭应用时同步并关闭
 }
 
 // InitializeApp 初始化应用程序
 func InitializeApp() (*Application, error) {
 	logger := NewLogger()
 	configManager := config.NewViperConfigManager()
-	dataCollector := datacollection.NewMemoryDataCollector(logger)
+	dataSourceFactory := NewDataSourceFactory(logger)
+	dataSourceConfig := NewMemoryDataSourceConfig()
+	multiDataSource, err := NewMultiDataSource(dataSourceFactory, dataSourceConfig, logger)
+	if err != nil {
+		return nil, err
+	}
 	dataProcessor := dataprocessing.NewMemoryDataProcessor(logger)
-	recommendationEngine := recommendation.NewRecommendationEngine(logger, dataCollector, dataProcessor, nil, nil, nil)
+	recommendationEngineManager := recommendation.NewRecommendationEngineManager(logger)
+	rankingStrategies := NewRankingStrategies()
+	recommendationEngine := NewRecommendationEngine(logger, multiDataSource, dataProcessor, recommendationEngineManager, rankingStrategies)
+	recommendationPresenter := application.NewRecommendationPresenter(recommendationEngine)
+	httpServer := httpapi.NewServer(recommendationPresenter, recommendationEngineManager, logger)
+	grpcServer := grpcapi.NewServer(recommendationEngineManager, logger)
 	app := &Application{
-		ConfigManager:     configManager,
-		RecommendationSvc: recommendationEngine,
-		Logger:           logger,
+		ConfigManager:         configManager,
+		DataSourceFactory:     dataSourceFactory,
+		RankingStrategies:     rankingStrategies,
+		RecommendationSvc:     recommendationEngine,
+		RecommendationUseCase: recommendationPresenter,
+		RecommendationEngine:  recommendationEngineManager,
+		HTTPServer:            httpServer,
+		GRPCServer:            grpcServer,
+		Logger:                logger,
 	}
 	return app, nil
 }
\ No newline at end of file
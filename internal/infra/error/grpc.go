@@ -0,0 +1,71 @@
+// Package error gRPC错误转换
+package error
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ToGRPCStatus 转换为gRPC状态
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	if e, ok := As(err); ok {
+		st := status.New(getGRPCCode(e.Code), e.Message)
+		if len(e.Details) > 0 {
+			if details, convErr := structpb.NewStruct(NormalizeValues(e.Details)); convErr == nil {
+				if withDetails, detailErr := st.WithDetails(details); detailErr == nil {
+					return withDetails
+				}
+			}
+		}
+		return st
+	}
+
+	return status.New(codes.Internal, err.Error())
+}
+
+// NormalizeValues 经JSON序列化往返将值转换为JSON兼容的类型，供 structpb 表示错误详情与响应元数据；
+// structpb 不支持自定义类型的切片、映射等值，直接转换会失败；无法序列化为JSON的值转换为字符串
+func NormalizeValues(values map[string]interface{}) map[string]interface{} {
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		var converted interface{}
+		if data, err := json.Marshal(value); err == nil && json.Unmarshal(data, &converted) == nil {
+			normalized[key] = converted
+		} else {
+			normalized[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return normalized
+}
+
+// getGRPCCode 获取gRPC状态码
+func getGRPCCode(code ErrorCode) codes.Code {
+	switch code {
+	case CodeInvalidParameter:
+		return codes.InvalidArgument
+	case CodeNotFound:
+		return codes.NotFound
+	case CodeAlreadyExists:
+		return codes.AlreadyExists
+	case CodeUnauthorized:
+		return codes.Unauthenticated
+	case CodeForbidden:
+		return codes.PermissionDenied
+	case CodeTimeout:
+		return codes.DeadlineExceeded
+	case CodeRateLimited:
+		return codes.ResourceExhausted
+	case CodeServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
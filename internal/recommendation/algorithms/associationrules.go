@@ -0,0 +1,520 @@
+package algorithms
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 关联规则推荐算法：基于购买篮使用FP-Growth挖掘频繁项集，
+// 生成"经常一起购买"的关联规则并按支持度、置信度、提升度过滤
+type AssociationRuleEngine struct {
+	mu           sync.RWMutex
+	transactions []*basket                    // 购买篮，按创建顺序
+	openBaskets  map[string]*basket           // 用户 -> 未结束的购买篮
+	orders       map[string]*basket           // 订单号 -> 购买篮
+	rules        map[string][]AssociationRule // 前件键 -> 规则
+	itemSupport  map[string]float64           // 单个物品的支持度
+	dirty        bool                         // 上次挖掘后是否有新的购买
+	mineStats    map[string]interface{}
+	stop         chan struct{}
+	log          *logrus.Logger
+	config       *AssociationRuleConfig
+}
+
+// 关联规则配置
+type AssociationRuleConfig struct {
+	MinSupport      float64       // 最小支持度（占购买篮的比例）
+	MinSupportCount int           // 最小支持计数，与最小支持度取较大者
+	MinConfidence   float64       // 最小置信度
+	MinLift         float64       // 最小提升度
+	MaxItemsetSize  int           // 最大频繁项集大小
+	BasketWindow    time.Duration // 没有订单号时，同一用户在该时间内的购买视为同一购买篮
+	MaxTransactions int           // 保留的购买篮上限
+	RefreshInterval time.Duration // 定时重新挖掘的间隔
+}
+
+// 关联规则：购买了前件的用户也会购买后件
+type AssociationRule struct {
+	Antecedent []string
+	Consequent string
+	Support    float64 // 前件与后件同时出现的购买篮比例
+	Confidence float64 // P(后件|前件)
+	Lift       float64 // 置信度 / 后件支持度
+}
+
+// 购买篮
+type basket struct {
+	items        map[string]struct{}
+	lastPurchase time.Time
+}
+
+// FP树节点
+type fpNode struct {
+	item     string
+	count    int
+	parent   *fpNode
+	children map[string]*fpNode
+	next     *fpNode // 同一物品的下一个节点
+}
+
+// FP树
+type fpTree struct {
+	root    *fpNode
+	headers map[string]*fpNode
+	counts  map[string]int
+}
+
+// 加权事务，用于构建条件FP树
+type weightedTransaction struct {
+	items []string
+	count int
+}
+
+// 创建新的关联规则引擎
+func NewAssociationRuleEngine(log *logrus.Logger) *AssociationRuleEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &AssociationRuleConfig{
+		MinSupport:      0.001,
+		MinSupportCount: 2,
+		MinConfidence:   0.1,
+		MinLift:         1.0,
+		MaxItemsetSize:  3,
+		BasketWindow:    time.Hour,
+		MaxTransactions: 100000,
+		RefreshInterval: 10 * time.Minute,
+	}
+
+	return &AssociationRuleEngine{
+		transactions: make([]*basket, 0),
+		openBaskets:  make(map[string]*basket),
+		orders:       make(map[string]*basket),
+		rules:        make(map[string][]AssociationRule),
+		itemSupport:  make(map[string]float64),
+		mineStats:    make(map[string]interface{}),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 记录购买，orderID 不为空时按订单归入购买篮，否则按用户和时间窗口归入
+func (a *AssociationRuleEngine) AddPurchase(userID string, orderID string, itemID string, timestamp time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var current *basket
+	if orderID != "" {
+		current = a.orders[orderID]
+		if current == nil {
+			current = a.newBasket()
+			a.orders[orderID] = current
+		}
+	} else {
+		current = a.openBaskets[userID]
+		if current == nil || timestamp.Sub(current.lastPurchase) > a.config.BasketWindow {
+			current = a.newBasket()
+			a.openBaskets[userID] = current
+		}
+	}
+
+	current.items[itemID] = struct{}{}
+	if timestamp.After(current.lastPurchase) {
+		current.lastPurchase = timestamp
+	}
+	a.dirty = true
+}
+
+// 添加完整的购买篮
+func (a *AssociationRuleEngine) AddTransaction(items []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	current := a.newBasket()
+	for _, itemID := range items {
+		current.items[itemID] = struct{}{}
+	}
+	current.lastPurchase = time.Now()
+	a.dirty = true
+}
+
+// 挖掘频繁项集并生成关联规则
+func (a *AssociationRuleEngine) Mine() {
+	startTime := time.Now()
+
+	a.mu.Lock()
+	config := *a.config
+	transactions := make([]weightedTransaction, 0, len(a.transactions))
+	for _, b := range a.transactions {
+		if len(b.items) == 0 {
+			continue
+		}
+		items := make([]string, 0, len(b.items))
+		for itemID := range b.items {
+			items = append(items, itemID)
+		}
+		transactions = append(transactions, weightedTransaction{items: items, count: 1})
+	}
+	a.dirty = false
+	a.mu.Unlock()
+
+	total := len(transactions)
+	if total == 0 {
+		return
+	}
+
+	minCount := max(config.MinSupportCount, int(math.Ceil(config.MinSupport*float64(total))), 1)
+	supports := make(map[string]int)
+	mineFPTree(buildFPTree(transactions, minCount), nil, minCount, config.MaxItemsetSize, supports)
+
+	itemSupport := make(map[string]float64)
+	for key, count := range supports {
+		if !strings.Contains(key, itemsetSeparator) {
+			itemSupport[key] = float64(count) / float64(total)
+		}
+	}
+
+	rules := make(map[string][]AssociationRule)
+	ruleCount := 0
+	for key, count := range supports {
+		items := strings.Split(key, itemsetSeparator)
+		if len(items) < 2 {
+			continue
+		}
+		for i, consequent := range items {
+			antecedent := append(append([]string(nil), items[:i]...), items[i+1:]...)
+			antecedentCount := supports[itemsetKey(antecedent)]
+			if antecedentCount == 0 {
+				continue
+			}
+
+			confidence := float64(count) / float64(antecedentCount)
+			lift := confidence / itemSupport[consequent]
+			if confidence < config.MinConfidence || lift < config.MinLift {
+				continue
+			}
+
+			antecedentKey := itemsetKey(antecedent)
+			rules[antecedentKey] = append(rules[antecedentKey], AssociationRule{
+				Antecedent: antecedent,
+				Consequent: consequent,
+				Support:    float64(count) / float64(total),
+				Confidence: confidence,
+				Lift:       lift,
+			})
+			ruleCount++
+		}
+	}
+	for _, candidates := range rules {
+		sortRules(candidates)
+	}
+
+	stats := map[string]interface{}{
+		"transactions":      total,
+		"frequent_itemsets": len(supports),
+		"rule_count":        ruleCount,
+		"min_support_count": minCount,
+		"duration_ms":       time.Since(startTime).Milliseconds(),
+		"mined_at":          time.Now(),
+	}
+
+	a.mu.Lock()
+	a.rules = rules
+	a.itemSupport = itemSupport
+	a.mineStats = stats
+	a.mu.Unlock()
+
+	a.log.WithFields(logrus.Fields(stats)).Info("关联规则挖掘完成")
+}
+
+// 根据当前物品（购物车或正在浏览的商品）推荐经常一起购买的物品
+func (a *AssociationRuleEngine) Recommend(items []string, topN int) []AssociationRule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	current := make(map[string]struct{}, len(items))
+	unique := make([]string, 0, len(items))
+	for _, itemID := range items {
+		if _, exists := current[itemID]; !exists {
+			current[itemID] = struct{}{}
+			unique = append(unique, itemID)
+		}
+	}
+	sort.Strings(unique)
+
+	// 每个后件取命中规则中置信度最高的一条
+	best := make(map[string]AssociationRule)
+	forEachSubset(unique, a.config.MaxItemsetSize-1, func(antecedent []string) {
+		for _, rule := range a.rules[itemsetKey(antecedent)] {
+			if _, inCart := current[rule.Consequent]; inCart {
+				continue
+			}
+			existing, exists := best[rule.Consequent]
+			if !exists || ruleLess(rule, existing) {
+				best[rule.Consequent] = rule
+			}
+		}
+	})
+
+	results := make([]AssociationRule, 0, len(best))
+	for _, rule := range best {
+		results = append(results, rule)
+	}
+	sortRules(results)
+
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results
+}
+
+// 获取以指定物品为前件的规则
+func (a *AssociationRuleEngine) GetRules(antecedent []string) []AssociationRule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return append([]AssociationRule(nil), a.rules[itemsetKey(antecedent)]...)
+}
+
+// 启动定时挖掘，仅在有新的购买时重新挖掘
+func (a *AssociationRuleEngine) StartRefresh() {
+	a.mu.Lock()
+	if a.stop != nil {
+		a.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	a.stop = stop
+	interval := a.config.RefreshInterval
+	a.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				a.mu.RLock()
+				dirty := a.dirty
+				a.mu.RUnlock()
+				if dirty {
+					a.Mine()
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// 停止定时挖掘
+func (a *AssociationRuleEngine) StopRefresh() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.stop != nil {
+		close(a.stop)
+		a.stop = nil
+	}
+}
+
+// 设置配置，刷新间隔变化时重启定时挖掘
+func (a *AssociationRuleEngine) SetConfig(config *AssociationRuleConfig) {
+	a.mu.Lock()
+	restart := a.stop != nil && config.RefreshInterval != a.config.RefreshInterval
+	a.config = config
+	a.dirty = true
+	a.mu.Unlock()
+
+	if restart {
+		a.StopRefresh()
+		a.StartRefresh()
+	}
+	a.log.Info("更新关联规则配置")
+}
+
+// 获取配置
+func (a *AssociationRuleEngine) GetConfig() *AssociationRuleConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.config
+}
+
+// 获取算法统计信息
+func (a *AssociationRuleEngine) GetStats() map[string]interface{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	stats := map[string]interface{}{
+		"basket_count": len(a.transactions),
+		"pending":      a.dirty,
+	}
+	for key, value := range a.mineStats {
+		stats[key] = value
+	}
+	return stats
+}
+
+// 创建购买篮并淘汰最早的购买篮，调用方需持有写锁
+func (a *AssociationRuleEngine) newBasket() *basket {
+	b := &basket{items: make(map[string]struct{})}
+	a.transactions = append(a.transactions, b)
+
+	if overflow := len(a.transactions) - a.config.MaxTransactions; overflow > 0 {
+		evicted := make(map[*basket]struct{}, overflow)
+		for _, old := range a.transactions[:overflow] {
+			evicted[old] = struct{}{}
+		}
+		a.transactions = append([]*basket(nil), a.transactions[overflow:]...)
+		for key, old := range a.orders {
+			if _, gone := evicted[old]; gone {
+				delete(a.orders, key)
+			}
+		}
+		for key, old := range a.openBaskets {
+			if _, gone := evicted[old]; gone {
+				delete(a.openBaskets, key)
+			}
+		}
+	}
+	return b
+}
+
+// 项集键的分隔符
+const itemsetSeparator = "\x00"
+
+// 项集键：排序后拼接
+func itemsetKey(items []string) string {
+	sorted := append([]string(nil), items...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, itemsetSeparator)
+}
+
+// 构建FP树，只保留频繁物品，事务内按频次降序插入
+func buildFPTree(transactions []weightedTransaction, minCount int) *fpTree {
+	counts := make(map[string]int)
+	for _, transaction := range transactions {
+		for _, itemID := range transaction.items {
+			counts[itemID] += transaction.count
+		}
+	}
+	for itemID, count := range counts {
+		if count < minCount {
+			delete(counts, itemID)
+		}
+	}
+
+	tree := &fpTree{
+		root:    &fpNode{children: make(map[string]*fpNode)},
+		headers: make(map[string]*fpNode),
+		counts:  counts,
+	}
+	for _, transaction := range transactions {
+		items := make([]string, 0, len(transaction.items))
+		for _, itemID := range transaction.items {
+			if _, frequent := counts[itemID]; frequent {
+				items = append(items, itemID)
+			}
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if counts[items[i]] != counts[items[j]] {
+				return counts[items[i]] > counts[items[j]]
+			}
+			return items[i] < items[j]
+		})
+
+		node := tree.root
+		for _, itemID := range items {
+			child, exists := node.children[itemID]
+			if !exists {
+				child = &fpNode{
+					item:     itemID,
+					parent:   node,
+					children: make(map[string]*fpNode),
+					next:     tree.headers[itemID],
+				}
+				node.children[itemID] = child
+				tree.headers[itemID] = child
+			}
+			child.count += transaction.count
+			node = child
+		}
+	}
+	return tree
+}
+
+// 递归挖掘频繁项集，结果写入 supports
+func mineFPTree(tree *fpTree, suffix []string, minCount int, maxSize int, supports map[string]int) {
+	for itemID, count := range tree.counts {
+		itemset := append(append([]string(nil), suffix...), itemID)
+		supports[itemsetKey(itemset)] = count
+		if len(itemset) >= maxSize {
+			continue
+		}
+
+		// 条件模式基：该物品每个节点到根的路径
+		base := make([]weightedTransaction, 0)
+		for node := tree.headers[itemID]; node != nil; node = node.next {
+			path := make([]string, 0)
+			for parent := node.parent; parent != nil && parent.parent != nil; parent = parent.parent {
+				path = append(path, parent.item)
+			}
+			if len(path) > 0 {
+				base = append(base, weightedTransaction{items: path, count: node.count})
+			}
+		}
+		if len(base) > 0 {
+			mineFPTree(buildFPTree(base, minCount), itemset, minCount, maxSize, supports)
+		}
+	}
+}
+
+// 遍历大小不超过 maxSize 的非空子集
+func forEachSubset(items []string, maxSize int, visit func([]string)) {
+	var walk func(start int, current []string)
+	walk = func(start int, current []string) {
+		if len(current) > 0 {
+			visit(current)
+		}
+		if len(current) >= maxSize {
+			return
+		}
+		for i := start; i < len(items); i++ {
+			walk(i+1, append(current, items[i]))
+		}
+	}
+	walk(0, make([]string, 0, maxSize))
+}
+
+// 规则排序：置信度优先，其次提升度、支持度
+func ruleLess(a AssociationRule, b AssociationRule) bool {
+	if a.Confidence != b.Confidence {
+		return a.Confidence > b.Confidence
+	}
+	if a.Lift != b.Lift {
+		return a.Lift > b.Lift
+	}
+	if a.Support != b.Support {
+		return a.Support > b.Support
+	}
+	return a.Consequent < b.Consequent
+}
+
+// 按规则优先级排序
+func sortRules(rules []AssociationRule) {
+	sort.Slice(rules, func(i, j int) bool {
+		return ruleLess(rules[i], rules[j])
+	})
+}
@@ -0,0 +1,530 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 贝叶斯个性化排序推荐算法（BPR，成对排序的隐式反馈模型）
+type BPREngine struct {
+	mu           sync.RWMutex
+	interactions map[string]map[string]float64 // 用户正反馈物品
+	userFactors  map[string][]float64          // 用户隐向量
+	itemFactors  map[string][]float64          // 物品隐向量
+	itemBias     map[string]float64            // 物品偏置
+	trainStats   map[string]interface{}        // 最近一次训练统计
+	log          *logrus.Logger
+	config       *BPRConfig
+}
+
+// BPR配置
+type BPRConfig struct {
+	Factors            int     // 隐向量维度
+	Epochs             int     // 最大训练轮数
+	LearningRate       float64 // 学习率
+	Regularization     float64 // 隐向量正则化系数
+	BiasRegularization float64 // 物品偏置正则化系数
+	NegativeSamples    int     // 每个正样本采样的负样本数
+	ValidationRatio    float64 // 留出验证集比例
+	Patience           int     // 验证集AUC连续未提升的容忍轮数
+	FoldInSteps        int     // 折叠新用户的SGD步数
+	InitStdDev         float64 // 隐向量初始化标准差
+	Seed               int64   // 随机种子
+}
+
+// 训练样本
+type bprSample struct {
+	userID string
+	itemID string
+}
+
+// 创建新的BPR引擎
+func NewBPREngine(log *logrus.Logger) *BPREngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &BPRConfig{
+		Factors:            16,
+		Epochs:             50,
+		LearningRate:       0.05,
+		Regularization:     0.01,
+		BiasRegularization: 0.01,
+		NegativeSamples:    1,
+		ValidationRatio:    0.1,
+		Patience:           3,
+		FoldInSteps:        50,
+		InitStdDev:         0.1,
+		Seed:               42,
+	}
+
+	return &BPREngine{
+		interactions: make(map[string]map[string]float64),
+		userFactors:  make(map[string][]float64),
+		itemFactors:  make(map[string][]float64),
+		itemBias:     make(map[string]float64),
+		trainStats:   make(map[string]interface{}),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 添加用户正反馈
+func (b *BPREngine) AddInteraction(userID string, itemID string, value float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.interactions[userID] == nil {
+		b.interactions[userID] = make(map[string]float64)
+	}
+	b.interactions[userID][itemID] += value
+
+	b.log.WithFields(logrus.Fields{
+		"user_id": userID,
+		"item_id": itemID,
+	}).Debug("添加BPR正反馈")
+}
+
+// 训练模型：SGD + 负采样，按留出集AUC提前停止并保留最优参数
+func (b *BPREngine) Train() {
+	startTime := time.Now()
+
+	b.mu.RLock()
+	config := *b.config
+	interactions := make(map[string]map[string]float64, len(b.interactions))
+	for userID, items := range b.interactions {
+		copied := make(map[string]float64, len(items))
+		for itemID, value := range items {
+			copied[itemID] = value
+		}
+		interactions[userID] = copied
+	}
+	b.mu.RUnlock()
+
+	if len(interactions) == 0 {
+		return
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	train, validation := splitValidation(rng, interactions, config.ValidationRatio)
+
+	// 物品全集，用于负采样
+	itemSet := make(map[string]bool)
+	for _, items := range interactions {
+		for itemID := range items {
+			itemSet[itemID] = true
+		}
+	}
+	items := make([]string, 0, len(itemSet))
+	for itemID := range itemSet {
+		items = append(items, itemID)
+	}
+	sort.Strings(items)
+
+	samples := make([]bprSample, 0)
+	for _, userID := range sortedKeys(train) {
+		for itemID := range train[userID] {
+			samples = append(samples, bprSample{userID: userID, itemID: itemID})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].userID != samples[j].userID {
+			return samples[i].userID < samples[j].userID
+		}
+		return samples[i].itemID < samples[j].itemID
+	})
+
+	userFactors := make(map[string][]float64, len(interactions))
+	for _, userID := range sortedKeys(interactions) {
+		userFactors[userID] = randomVector(rng, config.Factors, config.InitStdDev)
+	}
+	itemFactors := make(map[string][]float64, len(items))
+	itemBias := make(map[string]float64, len(items))
+	for _, itemID := range items {
+		itemFactors[itemID] = randomVector(rng, config.Factors, config.InitStdDev)
+	}
+
+	bestAUC := -1.0
+	bestEpoch := 0
+	epochs := 0
+	var bestUsers, bestItems map[string][]float64
+	var bestBias map[string]float64
+
+	for epoch := 1; epoch <= config.Epochs; epoch++ {
+		epochs = epoch
+		rng.Shuffle(len(samples), func(i, j int) {
+			samples[i], samples[j] = samples[j], samples[i]
+		})
+
+		for _, sample := range samples {
+			for n := 0; n < config.NegativeSamples; n++ {
+				negative, ok := sampleNegative(rng, items, train[sample.userID])
+				if !ok {
+					break
+				}
+				bprStep(userFactors[sample.userID], itemFactors[sample.itemID], itemFactors[negative],
+					itemBias, sample.itemID, negative, &config)
+			}
+		}
+
+		// 没有验证集时训练满轮数
+		if len(validation) == 0 {
+			continue
+		}
+
+		auc := validationAUC(rng, validation, interactions, items, userFactors, itemFactors, itemBias)
+		if auc > bestAUC {
+			bestAUC = auc
+			bestEpoch = epoch
+			bestUsers = copyFactors(userFactors)
+			bestItems = copyFactors(itemFactors)
+			bestBias = copyBias(itemBias)
+		} else if epoch-bestEpoch >= config.Patience {
+			break
+		}
+	}
+
+	if bestUsers != nil {
+		userFactors, itemFactors, itemBias = bestUsers, bestItems, bestBias
+	}
+
+	stats := map[string]interface{}{
+		"epochs":           epochs,
+		"best_epoch":       bestEpoch,
+		"training_samples": len(samples),
+		"validation_size":  len(validation),
+		"duration_ms":      time.Since(startTime).Milliseconds(),
+		"trained_at":       time.Now(),
+	}
+	if bestAUC >= 0 {
+		stats["validation_auc"] = bestAUC
+	}
+
+	b.mu.Lock()
+	if b.config.Factors != config.Factors {
+		// 训练期间隐向量维度已变更，丢弃本次结果
+		b.mu.Unlock()
+		b.log.Warn("训练期间配置变更，丢弃BPR训练结果")
+		return
+	}
+	b.userFactors = userFactors
+	b.itemFactors = itemFactors
+	b.itemBias = itemBias
+	b.trainStats = stats
+	// 训练期间新出现的用户不在快照中，替换隐向量后重新折叠
+	for userID := range b.interactions {
+		if _, exists := b.userFactors[userID]; !exists {
+			b.foldInUser(userID)
+		}
+	}
+	b.mu.Unlock()
+
+	b.log.WithFields(logrus.Fields(stats)).Info("BPR模型训练完成")
+}
+
+// 增量折叠用户：固定物品参数，仅对该用户隐向量做SGD
+func (b *BPREngine) FoldInUser(userID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.foldInUser(userID)
+}
+
+// 折叠用户，调用方需持有写锁
+func (b *BPREngine) foldInUser(userID string) bool {
+	positives := make([]string, 0)
+	for itemID := range b.interactions[userID] {
+		if _, exists := b.itemFactors[itemID]; exists {
+			positives = append(positives, itemID)
+		}
+	}
+	if len(positives) == 0 {
+		return false
+	}
+	sort.Strings(positives)
+
+	items := make([]string, 0, len(b.itemFactors))
+	for itemID := range b.itemFactors {
+		items = append(items, itemID)
+	}
+	sort.Strings(items)
+
+	rng := rand.New(rand.NewSource(b.config.Seed))
+	userVector, exists := b.userFactors[userID]
+	if !exists {
+		userVector = randomVector(rng, b.config.Factors, b.config.InitStdDev)
+	}
+
+	for step := 0; step < b.config.FoldInSteps; step++ {
+		positive := positives[rng.Intn(len(positives))]
+		negative, ok := sampleNegative(rng, items, b.interactions[userID])
+		if !ok {
+			break
+		}
+		diff := b.itemBias[positive] - b.itemBias[negative] +
+			dot(userVector, b.itemFactors[positive]) - dot(userVector, b.itemFactors[negative])
+		sig := 1.0 / (1.0 + math.Exp(diff))
+		for f := range userVector {
+			gradient := b.itemFactors[positive][f] - b.itemFactors[negative][f]
+			userVector[f] += b.config.LearningRate * (sig*gradient - b.config.Regularization*userVector[f])
+		}
+	}
+
+	b.userFactors[userID] = userVector
+	return true
+}
+
+// 生成推荐
+func (b *BPREngine) Recommend(userID string, topN int) []Recommendation {
+	b.mu.RLock()
+	_, trained := b.userFactors[userID]
+	b.mu.RUnlock()
+
+	if !trained && !b.FoldInUser(userID) {
+		return []Recommendation{}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	userVector := b.userFactors[userID]
+	seen := b.interactions[userID]
+
+	recommendations := make([]Recommendation, 0, len(b.itemFactors))
+	for itemID, itemVector := range b.itemFactors {
+		if _, exists := seen[itemID]; exists {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{
+			ItemID: itemID,
+			Score:  b.itemBias[itemID] + dot(userVector, itemVector),
+		})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+
+	if len(recommendations) > topN {
+		recommendations = recommendations[:topN]
+	}
+
+	return recommendations
+}
+
+// 预测用户对物品的排序得分
+func (b *BPREngine) Predict(userID string, itemID string) (float64, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	userVector, userExists := b.userFactors[userID]
+	itemVector, itemExists := b.itemFactors[itemID]
+	if !userExists || !itemExists {
+		return 0, false
+	}
+	return b.itemBias[itemID] + dot(userVector, itemVector), true
+}
+
+// 预测用户对一组物品的排序得分，未参与训练的用户先折叠进模型，没有隐向量的物品不返回
+func (b *BPREngine) PredictItems(userID string, itemIDs []string) map[string]float64 {
+	b.mu.RLock()
+	_, trained := b.userFactors[userID]
+	b.mu.RUnlock()
+
+	scores := make(map[string]float64, len(itemIDs))
+	if !trained && !b.FoldInUser(userID) {
+		return scores
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	userVector := b.userFactors[userID]
+	for _, itemID := range itemIDs {
+		if itemVector, exists := b.itemFactors[itemID]; exists {
+			scores[itemID] = b.itemBias[itemID] + dot(userVector, itemVector)
+		}
+	}
+	return scores
+}
+
+// 获取用户正反馈物品
+func (b *BPREngine) GetUserInteractions(userID string) map[string]float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	interactions := make(map[string]float64, len(b.interactions[userID]))
+	for itemID, value := range b.interactions[userID] {
+		interactions[itemID] = value
+	}
+	return interactions
+}
+
+// 设置配置，隐向量维度变化后需要重新训练
+func (b *BPREngine) SetConfig(config *BPRConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if config.Factors != b.config.Factors {
+		b.userFactors = make(map[string][]float64)
+		b.itemFactors = make(map[string][]float64)
+		b.itemBias = make(map[string]float64)
+	}
+	b.config = config
+	b.log.Info("更新BPR配置")
+}
+
+// 获取配置
+func (b *BPREngine) GetConfig() *BPRConfig {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.config
+}
+
+// 获取算法统计信息
+func (b *BPREngine) GetStats() map[string]interface{} {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	interactionCount := 0
+	for _, items := range b.interactions {
+		interactionCount += len(items)
+	}
+
+	stats := map[string]interface{}{
+		"user_count":        len(b.interactions),
+		"interaction_count": interactionCount,
+		"trained_users":     len(b.userFactors),
+		"trained_items":     len(b.itemFactors),
+	}
+	for key, value := range b.trainStats {
+		stats[key] = value
+	}
+	return stats
+}
+
+// 单步SGD更新：最大化 ln σ(x_ui - x_uj)
+func bprStep(userVector []float64, positive []float64, negative []float64, bias map[string]float64, positiveID string, negativeID string, config *BPRConfig) {
+	diff := bias[positiveID] - bias[negativeID] + dot(userVector, positive) - dot(userVector, negative)
+	sig := 1.0 / (1.0 + math.Exp(diff))
+	lr := config.LearningRate
+	reg := config.Regularization
+
+	for f := range userVector {
+		wu := userVector[f]
+		hi := positive[f]
+		hj := negative[f]
+		userVector[f] += lr * (sig*(hi-hj) - reg*wu)
+		positive[f] += lr * (sig*wu - reg*hi)
+		negative[f] += lr * (-sig*wu - reg*hj)
+	}
+
+	bias[positiveID] += lr * (sig - config.BiasRegularization*bias[positiveID])
+	bias[negativeID] += lr * (-sig - config.BiasRegularization*bias[negativeID])
+}
+
+// 均匀采样用户未交互的物品
+func sampleNegative(rng *rand.Rand, items []string, positives map[string]float64) (string, bool) {
+	if len(positives) >= len(items) {
+		return "", false
+	}
+	for attempt := 0; attempt < 20; attempt++ {
+		candidate := items[rng.Intn(len(items))]
+		if _, exists := positives[candidate]; !exists {
+			return candidate, true
+		}
+	}
+	// 正反馈占比过高时退化为顺序查找
+	for _, candidate := range items {
+		if _, exists := positives[candidate]; !exists {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// 按比例为每个用户留出验证样本，交互少于两个的用户全部用于训练
+func splitValidation(rng *rand.Rand, interactions map[string]map[string]float64, ratio float64) (map[string]map[string]float64, []bprSample) {
+	train := make(map[string]map[string]float64, len(interactions))
+	validation := make([]bprSample, 0)
+
+	for _, userID := range sortedKeys(interactions) {
+		items := make([]string, 0, len(interactions[userID]))
+		for itemID := range interactions[userID] {
+			items = append(items, itemID)
+		}
+		sort.Strings(items)
+
+		held := 0
+		if ratio > 0 && len(items) >= 2 {
+			held = int(math.Max(1, math.Floor(ratio*float64(len(items)))))
+		}
+		rng.Shuffle(len(items), func(i, j int) {
+			items[i], items[j] = items[j], items[i]
+		})
+
+		train[userID] = make(map[string]float64, len(items)-held)
+		for i, itemID := range items {
+			if i < held {
+				validation = append(validation, bprSample{userID: userID, itemID: itemID})
+				continue
+			}
+			train[userID][itemID] = interactions[userID][itemID]
+		}
+	}
+
+	return train, validation
+}
+
+// 计算留出集AUC：正样本得分高于随机负样本的比例
+func validationAUC(rng *rand.Rand, validation []bprSample, interactions map[string]map[string]float64, items []string, userFactors map[string][]float64, itemFactors map[string][]float64, bias map[string]float64) float64 {
+	const negativesPerSample = 20
+
+	var correct, total float64
+	for _, sample := range validation {
+		userVector := userFactors[sample.userID]
+		positiveScore := bias[sample.itemID] + dot(userVector, itemFactors[sample.itemID])
+		for n := 0; n < negativesPerSample; n++ {
+			negative, ok := sampleNegative(rng, items, interactions[sample.userID])
+			if !ok {
+				break
+			}
+			negativeScore := bias[negative] + dot(userVector, itemFactors[negative])
+			if positiveScore > negativeScore {
+				correct++
+			} else if positiveScore == negativeScore {
+				correct += 0.5
+			}
+			total++
+		}
+	}
+
+	if total == 0 {
+		return 0.0
+	}
+	return correct / total
+}
+
+// 深拷贝隐向量
+func copyFactors(factors map[string][]float64) map[string][]float64 {
+	copied := make(map[string][]float64, len(factors))
+	for id, vector := range factors {
+		copied[id] = append([]float64(nil), vector...)
+	}
+	return copied
+}
+
+// 拷贝偏置
+func copyBias(bias map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(bias))
+	for id, value := range bias {
+		copied[id] = value
+	}
+	return copied
+}
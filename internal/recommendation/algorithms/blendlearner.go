@@ -0,0 +1,212 @@
+package algorithms
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// 混合权重名称，也是混合权重学习的特征顺序，对应 HybridRecommendation 的组件得分
+var BlendComponents = []string{"collaborative", "content_based", "diversity", "popularity", "recency"}
+
+// 混合权重快照格式版本
+const blendSnapshotVersion = 1
+
+// 混合权重学习器：每个场景一个逻辑回归模型，以组件得分为特征、用户反馈为标签在线训练，
+// 非负系数按比例换算为该场景的混合权重
+type BlendLearner struct {
+	mu     sync.RWMutex
+	models map[string]*BlendModel // 场景-模型
+}
+
+// 场景的逻辑回归模型
+type BlendModel struct {
+	Bias         float64
+	Coefficients []float64 // 与 BlendComponents 一一对应
+	Samples      int
+	Positives    int
+}
+
+// 混合权重快照
+type blendSnapshot struct {
+	Version int
+	Models  map[string]*BlendModel
+}
+
+// 创建混合权重学习器
+func NewBlendLearner() *BlendLearner {
+	return &BlendLearner{models: make(map[string]*BlendModel)}
+}
+
+// 记录一次反馈：features 为推荐时的组件得分，positive 表示正反馈，按随机梯度下降更新模型
+func (l *BlendLearner) Observe(scenario string, features []float64, positive bool, learningRate float64, l2 float64) {
+	if len(features) != len(BlendComponents) || learningRate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	model, exists := l.models[scenario]
+	if !exists {
+		model = &BlendModel{Coefficients: make([]float64, len(BlendComponents))}
+		l.models[scenario] = model
+	}
+
+	label := 0.0
+	if positive {
+		label = 1
+		model.Positives++
+	}
+	model.Samples++
+
+	gradient := model.predict(features) - label
+	model.Bias -= learningRate * gradient
+	for i, x := range features {
+		model.Coefficients[i] -= learningRate * (gradient*x + l2*model.Coefficients[i])
+	}
+}
+
+// 获取场景学到的混合权重，总和缩放为 total；样本数不足 minSamples 或没有正系数时返回false
+func (l *BlendLearner) Weights(scenario string, total float64, minSamples int) (map[string]float64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	model, exists := l.models[scenario]
+	if !exists || model.Samples < minSamples {
+		return nil, false
+	}
+
+	sum := 0.0
+	for _, coefficient := range model.Coefficients {
+		sum += math.Max(coefficient, 0)
+	}
+	if sum <= 0 {
+		return nil, false
+	}
+
+	weights := make(map[string]float64, len(BlendComponents))
+	for i, name := range BlendComponents {
+		weights[name] = total * math.Max(model.Coefficients[i], 0) / sum
+	}
+	return weights, true
+}
+
+// 点击概率
+func (m *BlendModel) predict(features []float64) float64 {
+	z := m.Bias
+	for i, x := range features {
+		z += m.Coefficients[i] * x
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// 清空场景的模型，scenario 为空时清空全部
+func (l *BlendLearner) Reset(scenario string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if scenario == "" {
+		l.models = make(map[string]*BlendModel)
+		return
+	}
+	delete(l.models, scenario)
+}
+
+// 获取统计信息
+func (l *BlendLearner) GetStats() map[string]interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	scenarios := make([]string, 0, len(l.models))
+	samples := make(map[string]int, len(l.models))
+	for scenario, model := range l.models {
+		scenarios = append(scenarios, scenario)
+		samples[scenario] = model.Samples
+	}
+	sort.Strings(scenarios)
+
+	return map[string]interface{}{
+		"scenarios": scenarios,
+		"samples":   samples,
+	}
+}
+
+// 持久化学到的模型到文件，先写临时文件再原子替换
+func (l *BlendLearner) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建混合权重目录失败: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建混合权重临时文件失败: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	if err := l.Encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入混合权重文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("替换混合权重文件失败: %w", err)
+	}
+	return nil
+}
+
+// 序列化学到的模型
+func (l *BlendLearner) Encode(w io.Writer) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	snapshot := blendSnapshot{Version: blendSnapshotVersion, Models: l.models}
+	if err := gob.NewEncoder(w).Encode(&snapshot); err != nil {
+		return fmt.Errorf("序列化混合权重失败: %w", err)
+	}
+	return nil
+}
+
+// 从文件加载模型，替换当前学到的模型
+func (l *BlendLearner) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开混合权重文件失败: %w", err)
+	}
+	defer file.Close()
+
+	return l.Decode(file)
+}
+
+// 反序列化模型，替换当前学到的模型
+func (l *BlendLearner) Decode(r io.Reader) error {
+	var snapshot blendSnapshot
+	if err := gob.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("反序列化混合权重失败: %w", err)
+	}
+	if snapshot.Version != blendSnapshotVersion {
+		return fmt.Errorf("不支持的混合权重版本: %d", snapshot.Version)
+	}
+
+	models := make(map[string]*BlendModel, len(snapshot.Models))
+	for scenario, model := range snapshot.Models {
+		if model == nil || len(model.Coefficients) != len(BlendComponents) {
+			return fmt.Errorf("混合权重文件损坏: 场景 %q 的模型无效", scenario)
+		}
+		models[scenario] = model
+	}
+
+	l.mu.Lock()
+	l.models = models
+	l.mu.Unlock()
+	return nil
+}
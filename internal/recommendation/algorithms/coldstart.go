@@ -0,0 +1,515 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 冷启动推荐来源
+const (
+	ColdStartSourceDemographic = "demographic" // 同人群偏好
+	ColdStartSourcePreference  = "preference"  // 注册偏好与内容匹配
+	ColdStartSourcePopularity  = "popularity"  // 全站热度
+	ColdStartSourceNewItem     = "new_item"    // 新物品内容冷启动
+)
+
+// 冷启动推荐算法：新用户按人口统计学分群的群体偏好、注册偏好与内容特征的匹配度
+// 以及全站热度推荐；新物品在获得足够交互之前按内容特征与用户兴趣的相似度获得曝光
+type ColdStartEngine struct {
+	mu           sync.RWMutex
+	contentBased *ContentBasedFilteringEngine
+	trending     *TrendingEngine
+	userSegments map[string][]string           // 用户所属人群
+	segmentUsers map[string]int                // 人群用户数
+	segmentItems map[string]map[string]float64 // 人群-物品累计评分
+	userItems    map[string]map[string]float64 // 用户-物品评分
+	itemUsers    map[string]int                // 物品交互用户数
+	catalog      map[string]time.Time          // 物品首次出现时间
+	log          *logrus.Logger
+	config       *ColdStartConfig
+}
+
+// 冷启动配置
+type ColdStartConfig struct {
+	WarmUpThreshold   int           // 用户交互物品数达到该值后转为协同过滤等模型
+	NewItemThreshold  int           // 物品交互用户数低于该值时视为新物品
+	NewItemRatio      float64       // 推荐结果中为新物品保留的比例
+	NewItemHalfLife   time.Duration // 新物品新鲜度的半衰期
+	MinSegmentUsers   int           // 人群用户数低于该值时不使用该人群
+	NumericBucketSize float64       // 数值型人口统计学特征的分桶宽度
+	DemographicWeight float64       // 人群偏好权重
+	PreferenceWeight  float64       // 注册偏好权重
+	PopularityWeight  float64       // 全站热度权重
+}
+
+// 冷启动推荐结果
+type ColdStartRecommendation struct {
+	ItemID string
+	Score  float64
+	Source string // 主要贡献的推荐来源
+}
+
+// 创建新的冷启动引擎，内容过滤引擎提供物品内容特征，趋势热度引擎提供全站热度
+func NewColdStartEngine(contentBased *ContentBasedFilteringEngine, trending *TrendingEngine, log *logrus.Logger) *ColdStartEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+	if contentBased == nil {
+		contentBased = NewContentBasedFilteringEngine(log)
+	}
+	if trending == nil {
+		trending = NewTrendingEngine(log)
+	}
+
+	config := &ColdStartConfig{
+		WarmUpThreshold:   5,
+		NewItemThreshold:  3,
+		NewItemRatio:      0.2,
+		NewItemHalfLife:   72 * time.Hour,
+		MinSegmentUsers:   3,
+		NumericBucketSize: 10,
+		DemographicWeight: 0.5,
+		PreferenceWeight:  0.3,
+		PopularityWeight:  0.2,
+	}
+
+	return &ColdStartEngine{
+		contentBased: contentBased,
+		trending:     trending,
+		userSegments: make(map[string][]string),
+		segmentUsers: make(map[string]int),
+		segmentItems: make(map[string]map[string]float64),
+		userItems:    make(map[string]map[string]float64),
+		itemUsers:    make(map[string]int),
+		catalog:      make(map[string]time.Time),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 设置用户画像：人口统计学信息划分人群，偏好写入内容过滤引擎的用户画像
+func (c *ColdStartEngine) SetUserProfile(userID string, demographics map[string]interface{}, preferences map[string]interface{}) {
+	c.mu.Lock()
+	segments := c.segmentsFor(demographics)
+
+	// 用户已有的交互从原人群移到新人群
+	for _, segment := range c.userSegments[userID] {
+		c.segmentUsers[segment]--
+		for itemID, rating := range c.userItems[userID] {
+			c.segmentItems[segment][itemID] -= rating
+		}
+	}
+	for _, segment := range segments {
+		c.segmentUsers[segment]++
+		if c.segmentItems[segment] == nil {
+			c.segmentItems[segment] = make(map[string]float64)
+		}
+		for itemID, rating := range c.userItems[userID] {
+			c.segmentItems[segment][itemID] += rating
+		}
+	}
+	c.userSegments[userID] = segments
+	c.mu.Unlock()
+
+	for preference, weight := range preferenceWeights(preferences) {
+		c.contentBased.UpdateUserPreference(userID, preference, weight)
+	}
+}
+
+// 添加物品到物品库
+func (c *ColdStartEngine) AddItem(itemID string, addedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if addedAt.IsZero() {
+		addedAt = time.Now()
+	}
+	if _, exists := c.catalog[itemID]; !exists {
+		c.catalog[itemID] = addedAt
+	}
+}
+
+// 添加用户交互，同一物品重复交互时评分取最新值
+func (c *ColdStartEngine) AddInteraction(userID string, itemID string, rating float64, timestamp time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	if _, exists := c.catalog[itemID]; !exists {
+		c.catalog[itemID] = timestamp
+	}
+
+	if c.userItems[userID] == nil {
+		c.userItems[userID] = make(map[string]float64)
+	}
+	previous, exists := c.userItems[userID][itemID]
+	if !exists {
+		c.itemUsers[itemID]++
+	}
+	c.userItems[userID][itemID] = rating
+
+	for _, segment := range c.userSegments[userID] {
+		c.segmentItems[segment][itemID] += rating - previous
+	}
+}
+
+// 获取用户交互物品数
+func (c *ColdStartEngine) InteractionCount(userID string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.userItems[userID])
+}
+
+// 判断用户是否已完成冷启动
+func (c *ColdStartEngine) IsWarm(userID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.userItems[userID]) >= c.config.WarmUpThreshold
+}
+
+// 判断物品是否为新物品
+func (c *ColdStartEngine) IsNewItem(itemID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, exists := c.catalog[itemID]
+	return exists && c.itemUsers[itemID] < c.config.NewItemThreshold
+}
+
+// 获取用户所属人群
+func (c *ColdStartEngine) GetUserSegments(userID string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]string(nil), c.userSegments[userID]...)
+}
+
+// 生成冷启动推荐，按 NewItemRatio 为新物品保留位置，新物品不足时由已有物品补齐
+func (c *ColdStartEngine) Recommend(userID string, topN int, now time.Time) []ColdStartRecommendation {
+	newSlots := int(math.Round(float64(topN) * c.GetConfig().NewItemRatio))
+	newItems := c.OnboardItems(userID, newSlots, now)
+
+	exclude := make(map[string]bool, len(newItems))
+	for _, rec := range newItems {
+		exclude[rec.ItemID] = true
+	}
+	results := append(c.bootstrap(userID, topN-len(newItems), exclude, now), newItems...)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// 为用户挑选新物品：按内容特征与用户兴趣的相似度和物品新鲜度排序
+func (c *ColdStartEngine) OnboardItems(userID string, topN int, now time.Time) []ColdStartRecommendation {
+	if topN <= 0 {
+		return []ColdStartRecommendation{}
+	}
+
+	c.mu.RLock()
+	config := c.config
+	history := c.userItems[userID]
+	candidates := make([]string, 0)
+	for itemID := range c.catalog {
+		if _, seen := history[itemID]; seen {
+			continue
+		}
+		if c.itemUsers[itemID] < config.NewItemThreshold {
+			candidates = append(candidates, itemID)
+		}
+	}
+	sort.Strings(candidates)
+	freshness := make(map[string]float64, len(candidates))
+	decayRate := math.Ln2 / config.NewItemHalfLife.Seconds()
+	for _, itemID := range candidates {
+		age := math.Max(now.Sub(c.catalog[itemID]).Seconds(), 0)
+		freshness[itemID] = math.Exp(-decayRate * age)
+	}
+	anchors := c.interestAnchors(userID)
+	c.mu.RUnlock()
+
+	// 兴趣相似度取用户画像匹配度与锚点物品内容相似度中的较大值
+	profileScores := c.contentBased.ScoreItems(userID, candidates)
+	results := make([]ColdStartRecommendation, 0, len(candidates))
+	for _, itemID := range candidates {
+		affinity := profileScores[itemID]
+		for _, anchor := range anchors {
+			affinity = math.Max(affinity, c.contentBased.ItemContentSimilarity(itemID, anchor))
+		}
+		results = append(results, ColdStartRecommendation{
+			ItemID: itemID,
+			Score:  0.5*math.Min(affinity, 1) + 0.5*freshness[itemID],
+			Source: ColdStartSourceNewItem,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ItemID < results[j].ItemID
+	})
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results
+}
+
+// 已有物品的冷启动推荐：人群偏好、注册偏好与全站热度加权，缺少的来源不参与加权
+func (c *ColdStartEngine) bootstrap(userID string, topN int, exclude map[string]bool, now time.Time) []ColdStartRecommendation {
+	if topN <= 0 {
+		return []ColdStartRecommendation{}
+	}
+
+	c.mu.RLock()
+	config := c.config
+	history := c.userItems[userID]
+	demographic := c.segmentScores(userID)
+	candidates := make([]string, 0, len(c.catalog))
+	for itemID := range c.catalog {
+		if _, seen := history[itemID]; !seen && !exclude[itemID] {
+			candidates = append(candidates, itemID)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Strings(candidates)
+	preference := c.contentBased.ScoreItems(userID, candidates)
+
+	results := make([]ColdStartRecommendation, 0, len(candidates))
+	for _, itemID := range candidates {
+		sources := map[string]float64{
+			ColdStartSourcePopularity: config.PopularityWeight * c.trending.PopularityScore(itemID),
+		}
+		totalWeight := config.PopularityWeight
+		if len(demographic) > 0 {
+			sources[ColdStartSourceDemographic] = config.DemographicWeight * demographic[itemID]
+			totalWeight += config.DemographicWeight
+		}
+		if len(preference) > 0 {
+			sources[ColdStartSourcePreference] = config.PreferenceWeight * math.Min(preference[itemID], 1)
+			totalWeight += config.PreferenceWeight
+		}
+
+		var score, best float64
+		source := ColdStartSourcePopularity
+		for name, value := range sources {
+			score += value
+			if value > best || (value == best && name < source) {
+				best, source = value, name
+			}
+		}
+		if score <= 0 || totalWeight <= 0 {
+			continue
+		}
+		results = append(results, ColdStartRecommendation{ItemID: itemID, Score: score / totalWeight, Source: source})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ItemID < results[j].ItemID
+	})
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results
+}
+
+// 用户所属人群中物品的偏好得分：各人群内按最高累计评分归一化后取平均，调用方需持有锁
+func (c *ColdStartEngine) segmentScores(userID string) map[string]float64 {
+	scores := make(map[string]float64)
+	segments := 0
+	for _, segment := range c.userSegments[userID] {
+		if c.segmentUsers[segment] < c.config.MinSegmentUsers {
+			continue
+		}
+		var maxRating float64
+		for _, rating := range c.segmentItems[segment] {
+			maxRating = math.Max(maxRating, rating)
+		}
+		if maxRating <= 0 {
+			continue
+		}
+		segments++
+		for itemID, rating := range c.segmentItems[segment] {
+			if rating > 0 {
+				scores[itemID] += rating / maxRating
+			}
+		}
+	}
+	for itemID := range scores {
+		scores[itemID] /= float64(segments)
+	}
+	return scores
+}
+
+// 衡量新物品兴趣的锚点物品：用户评分最高的交互物品，没有交互时取所属人群最受欢迎的物品，调用方需持有锁
+func (c *ColdStartEngine) interestAnchors(userID string) []string {
+	const maxAnchors = 10
+
+	anchors := sortedByValue(c.userItems[userID])
+	if len(anchors) == 0 {
+		for itemID, score := range c.segmentScores(userID) {
+			if score >= 0.5 {
+				anchors = append(anchors, itemID)
+			}
+		}
+		sort.Strings(anchors)
+	}
+	if len(anchors) > maxAnchors {
+		anchors = anchors[:maxAnchors]
+	}
+	return anchors
+}
+
+// 人口统计学信息转换为人群标识：字符串和布尔值按取值划分，数值按 NumericBucketSize 分桶，调用方需持有锁
+func (c *ColdStartEngine) segmentsFor(demographics map[string]interface{}) []string {
+	segments := make([]string, 0, len(demographics))
+	for key, value := range demographics {
+		var bucket string
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			bucket = strings.ToLower(v)
+		case bool:
+			bucket = fmt.Sprintf("%t", v)
+		case int:
+			bucket = c.numericBucket(float64(v))
+		case int64:
+			bucket = c.numericBucket(float64(v))
+		case float64:
+			bucket = c.numericBucket(v)
+		default:
+			continue
+		}
+		segments = append(segments, key+"="+bucket)
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// 数值分桶，调用方需持有锁
+func (c *ColdStartEngine) numericBucket(value float64) string {
+	size := c.config.NumericBucketSize
+	if size <= 0 {
+		return fmt.Sprintf("%g", value)
+	}
+	lower := math.Floor(value/size) * size
+	return fmt.Sprintf("%g-%g", lower, lower+size)
+}
+
+// 设置配置
+func (c *ColdStartEngine) SetConfig(config *ColdStartConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.config = config
+	c.log.Info("更新冷启动配置")
+}
+
+// 获取配置
+func (c *ColdStartEngine) GetConfig() *ColdStartConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.config
+}
+
+// 获取算法统计信息
+func (c *ColdStartEngine) GetStats() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	warmUsers := 0
+	for _, items := range c.userItems {
+		if len(items) >= c.config.WarmUpThreshold {
+			warmUsers++
+		}
+	}
+	newItems := 0
+	for itemID := range c.catalog {
+		if c.itemUsers[itemID] < c.config.NewItemThreshold {
+			newItems++
+		}
+	}
+
+	return map[string]interface{}{
+		"user_count":        len(c.userItems),
+		"warm_user_count":   warmUsers,
+		"profiled_users":    len(c.userSegments),
+		"segment_count":     len(c.segmentUsers),
+		"item_count":        len(c.catalog),
+		"new_item_count":    newItems,
+		"warm_up_threshold": c.config.WarmUpThreshold,
+	}
+}
+
+// 用户偏好转换为内容过滤引擎的偏好权重：字符串及字符串列表的取值权重为1，
+// 数值以字段名为偏好、数值为权重，布尔值为真时以字段名为偏好
+func preferenceWeights(preferences map[string]interface{}) map[string]float64 {
+	weights := make(map[string]float64)
+	add := func(preference string, weight float64) {
+		if preference == "" {
+			return
+		}
+		// 类别匹配区分大小写，关键词统一为小写，两种形式都写入
+		weights[preference] = weight
+		weights[strings.ToLower(preference)] = weight
+	}
+
+	for key, value := range preferences {
+		switch v := value.(type) {
+		case string:
+			add(v, 1)
+		case []string:
+			for _, preference := range v {
+				add(preference, 1)
+			}
+		case []interface{}:
+			for _, preference := range v {
+				if s, ok := preference.(string); ok {
+					add(s, 1)
+				}
+			}
+		case bool:
+			if v {
+				add(key, 1)
+			}
+		case int:
+			add(key, float64(v))
+		case float64:
+			add(key, v)
+		}
+	}
+	return weights
+}
+
+// 按值降序排列的键
+func sortedByValue(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if values[keys[i]] != values[keys[j]] {
+			return values[keys[i]] > values[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -1,7 +1,6 @@
 package algorithms
 
 import (
-	"math"
 	"sort"
 	"sync"
 
@@ -14,7 +13,7 @@ type CollaborativeFilteringEngine struct {
 	userItemMatrix  map[string]map[string]float64 // 用户-物品评分矩阵
 	itemUserMatrix  map[string]map[string]float64 // 物品-用户评分矩阵
 	userSimilarity  map[string]map[string]float64 // 用户相似度矩阵
-	itemSimilarity  map[string]map[string]float64 // 物品相似度矩阵
+	itemIndex       *ItemSimilarityIndex          // 物品Top-K相似度索引
 	log             *logrus.Logger
 	config          *CollaborativeFilteringConfig
 }
@@ -24,7 +23,12 @@ type CollaborativeFilteringConfig struct {
 	SimilarityThreshold float64 // 相似度阈值
 	MaxNeighbors        int     // 最大邻居数
 	MinCommonItems      int     // 最小共同物品数
-	NormalizationMethod string  // 归一化方法
+	NormalizationMethod string  // 基于用户推荐时邻居评分的归一化方法：none、mean_centering、z_score
+	UserSimilarity      string  // 用户相似度度量
+	ItemSimilarity      string  // 物品相似度度量，为余弦相似度时使用增量维护的物品索引
+	Shrinkage           float64 // 收缩相似度的收缩系数
+	BM25K1              float64 // BM25评分饱和参数
+	BM25B               float64 // BM25长度归一化参数
 }
 
 // 创建新的协同过滤引擎
@@ -37,14 +41,19 @@ func NewCollaborativeFilteringEngine(log *logrus.Logger) *CollaborativeFiltering
 		SimilarityThreshold: 0.1,
 		MaxNeighbors:        50,
 		MinCommonItems:      2,
-		NormalizationMethod: "mean_centering",
+		NormalizationMethod: NormalizationMeanCentering,
+		UserSimilarity:      SimilarityPearson,
+		ItemSimilarity:      SimilarityCosine,
+		Shrinkage:           100,
+		BM25K1:              1.2,
+		BM25B:               0.75,
 	}
 	
 	return &CollaborativeFilteringEngine{
 		userItemMatrix: make(map[string]map[string]float64),
 		itemUserMatrix: make(map[string]map[string]float64),
 		userSimilarity: make(map[string]map[string]float64),
-		itemSimilarity: make(map[string]map[string]float64),
+		itemIndex:      NewItemSimilarityIndex(config.MaxNeighbors, config.SimilarityThreshold, log),
 		log:            log,
 		config:         config,
 	}
@@ -55,10 +64,27 @@ func (c *CollaborativeFilteringEngine) AddUserRating(userID string, itemID strin
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
+	c.setUserRating(userID, itemID, rating, false)
+}
+
+// 添加隐式反馈评分：保留同一用户物品的最高评分，之后的浏览、点击不会降低购买或评分
+func (c *CollaborativeFilteringEngine) AddImplicitRating(userID string, itemID string, rating float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.setUserRating(userID, itemID, rating, true)
+}
+
+// 写入评分并增量更新物品相似度索引，keepMax 为真时已有评分更高则不更新，调用方需持有写锁
+func (c *CollaborativeFilteringEngine) setUserRating(userID string, itemID string, rating float64, keepMax bool) {
 	// 更新用户-物品矩阵
 	if c.userItemMatrix[userID] == nil {
 		c.userItemMatrix[userID] = make(map[string]float64)
 	}
+	oldRating, existed := c.userItemMatrix[userID][itemID]
+	if keepMax && existed && oldRating >= rating {
+		return
+	}
 	c.userItemMatrix[userID][itemID] = rating
 	
 	// 更新物品-用户矩阵
@@ -67,6 +93,9 @@ func (c *CollaborativeFilteringEngine) AddUserRating(userID string, itemID strin
 	}
 	c.itemUserMatrix[itemID][userID] = rating
 	
+	// 增量更新物品相似度索引
+	c.itemIndex.Update(itemID, oldRating, rating, existed, c.userItemMatrix[userID])
+	
 	c.log.WithFields(logrus.Fields{
 		"user_id": userID,
 		"item_id": itemID,
@@ -74,11 +103,16 @@ func (c *CollaborativeFilteringEngine) AddUserRating(userID string, itemID strin
 	}).Debug("添加用户评分数据")
 }
 
-// 计算用户相似度（基于皮尔逊相关系数）
+// 计算用户相似度（按配置的用户相似度度量，默认为皮尔逊相关系数）
 func (c *CollaborativeFilteringEngine) CalculateUserSimilarity(userID1 string, userID2 string) float64 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	
+
+	return c.calculateUserSimilarity(userID1, userID2, c.userSimilarityContext())
+}
+
+// 计算用户相似度，调用方需持有锁
+func (c *CollaborativeFilteringEngine) calculateUserSimilarity(userID1 string, userID2 string, context *SimilarityContext) float64 {
 	ratings1, exists1 := c.userItemMatrix[userID1]
 	ratings2, exists2 := c.userItemMatrix[userID2]
 	
@@ -86,27 +120,24 @@ func (c *CollaborativeFilteringEngine) CalculateUserSimilarity(userID1 string, u
 		return 0.0
 	}
 	
-	// 找到共同评分的物品
-	commonItems := []string{}
-	for itemID := range ratings1 {
-		if _, exists := ratings2[itemID]; exists {
-			commonItems = append(commonItems, itemID)
-		}
-	}
-	
-	if len(commonItems) < c.config.MinCommonItems {
+	// 共同评分的物品数不足时不计算
+	if len(commonKeys(ratings1, ratings2)) < c.config.MinCommonItems {
 		return 0.0
 	}
 	
-	// 计算皮尔逊相关系数
-	return c.pearsonCorrelation(ratings1, ratings2, commonItems)
+	return c.similarityMetric(c.config.UserSimilarity, SimilarityPearson)(ratings1, ratings2, context)
 }
 
-// 计算物品相似度（基于余弦相似度）
+// 计算物品相似度（按配置的物品相似度度量，默认为余弦相似度）
 func (c *CollaborativeFilteringEngine) CalculateItemSimilarity(itemID1 string, itemID2 string) float64 {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	
+
+	return c.calculateItemSimilarity(itemID1, itemID2, c.itemSimilarityContext())
+}
+
+// 计算物品相似度，调用方需持有锁
+func (c *CollaborativeFilteringEngine) calculateItemSimilarity(itemID1 string, itemID2 string, context *SimilarityContext) float64 {
 	ratings1, exists1 := c.itemUserMatrix[itemID1]
 	ratings2, exists2 := c.itemUserMatrix[itemID2]
 	
@@ -114,8 +145,38 @@ func (c *CollaborativeFilteringEngine) CalculateItemSimilarity(itemID1 string, i
 		return 0.0
 	}
 	
-	// 计算余弦相似度
-	return c.cosineSimilarity(ratings1, ratings2)
+	return c.similarityMetric(c.config.ItemSimilarity, SimilarityCosine)(ratings1, ratings2, context)
+}
+
+// 获取相似度度量，未注册时使用默认度量，调用方需持有锁
+func (c *CollaborativeFilteringEngine) similarityMetric(name string, fallback string) SimilarityMetric {
+	if metric, exists := GetSimilarityMetric(name); exists {
+		return metric
+	}
+	metric, _ := GetSimilarityMetric(fallback)
+	return metric
+}
+
+// 计算用户相似度的上下文，维度为物品，调用方需持有锁
+func (c *CollaborativeFilteringEngine) userSimilarityContext() *SimilarityContext {
+	return c.similarityContext(c.itemUserMatrix, len(c.userItemMatrix))
+}
+
+// 计算物品相似度的上下文，维度为用户，调用方需持有锁
+func (c *CollaborativeFilteringEngine) itemSimilarityContext() *SimilarityContext {
+	return c.similarityContext(c.userItemMatrix, len(c.itemUserMatrix))
+}
+
+// 计算相似度的上下文，调用方需持有锁
+func (c *CollaborativeFilteringEngine) similarityContext(dimensions map[string]map[string]float64, vectorCount int) *SimilarityContext {
+	return &SimilarityContext{
+		Dimensions:   dimensions,
+		VectorCount:  vectorCount,
+		Shrinkage:    c.config.Shrinkage,
+		MaxNeighbors: c.config.MaxNeighbors,
+		BM25K1:       c.config.BM25K1,
+		BM25B:        c.config.BM25B,
+	}
 }
 
 // 基于用户的协同过滤推荐
@@ -131,11 +192,13 @@ func (c *CollaborativeFilteringEngine) UserBasedRecommend(userID string, topN in
 	// 计算与目标用户最相似的用户
 	similarUsers := c.findSimilarUsers(userID)
 	
-	// 生成推荐
+	// 生成推荐，邻居的评分按归一化方法换算到目标用户的评分尺度
 	recommendations := make(map[string]float64)
+	targetMean, targetStd := meanAndStd(userRatings)
 	
 	for _, similarUser := range similarUsers {
 		similarUserRatings := c.userItemMatrix[similarUser.UserID]
+		neighborMean, neighborStd := meanAndStd(similarUserRatings)
 		
 		for itemID, rating := range similarUserRatings {
 			// 跳过用户已经评分过的物品
@@ -147,7 +210,8 @@ func (c *CollaborativeFilteringEngine) UserBasedRecommend(userID string, topN in
 			if _, exists := recommendations[itemID]; !exists {
 				recommendations[itemID] = 0.0
 			}
-			recommendations[itemID] += similarUser.Similarity * rating
+			normalized := normalizeRating(c.config.NormalizationMethod, rating, [2]float64{neighborMean, neighborStd}, [2]float64{targetMean, targetStd})
+			recommendations[itemID] += similarUser.Similarity * normalized
 		}
 	}
 	
@@ -182,17 +246,13 @@ func (c *CollaborativeFilteringEngine) ItemBasedRecommend(userID string, topN in
 		return []Recommendation{}
 	}
 	
-	// 计算物品相似度矩阵（如果还没有计算）
-	if len(c.itemSimilarity) == 0 {
-		c.buildItemSimilarityMatrix()
-	}
-	
 	recommendations := make(map[string]float64)
+	context := c.itemSimilarityContext()
 	
 	// 对用户评分过的每个物品
 	for userItemID, userRating := range userRatings {
-		// 找到相似的物品
-		similarItems := c.findSimilarItems(userItemID)
+		// 获取相似物品
+		similarItems := c.itemNeighbors(userItemID, context)
 		
 		for _, similarItem := range similarItems {
 			// 跳过用户已经评分过的物品
@@ -229,85 +289,47 @@ func (c *CollaborativeFilteringEngine) ItemBasedRecommend(userID string, topN in
 	return result
 }
 
-// 皮尔逊相关系数计算
-func (c *CollaborativeFilteringEngine) pearsonCorrelation(ratings1 map[string]float64, ratings2 map[string]float64, commonItems []string) float64 {
-	if len(commonItems) == 0 {
-		return 0.0
+// 获取物品的相似物品：余弦相似度从增量维护的索引中读取，其他度量在共同评分用户评价过的物品中计算，调用方需持有锁
+func (c *CollaborativeFilteringEngine) itemNeighbors(itemID string, context *SimilarityContext) []SimilarItem {
+	if c.config.ItemSimilarity == SimilarityCosine {
+		return c.itemIndex.Neighbors(itemID)
 	}
 	
-	// 计算平均值
-	var sum1, sum2 float64
-	for _, itemID := range commonItems {
-		sum1 += ratings1[itemID]
-		sum2 += ratings2[itemID]
-	}
-	mean1 := sum1 / float64(len(commonItems))
-	mean2 := sum2 / float64(len(commonItems))
-	
-	// 计算分子和分母
-	var numerator, denominator1, denominator2 float64
-	for _, itemID := range commonItems {
-		diff1 := ratings1[itemID] - mean1
-		diff2 := ratings2[itemID] - mean2
-		numerator += diff1 * diff2
-		denominator1 += diff1 * diff1
-		denominator2 += diff2 * diff2
-	}
-	
-	// 避免除零
-	if denominator1 == 0 || denominator2 == 0 {
-		return 0.0
-	}
-	
-	correlation := numerator / math.Sqrt(denominator1*denominator2)
-	
-	// 考虑共同物品数量的影响
-	weight := float64(len(commonItems)) / float64(c.config.MaxNeighbors)
-	return correlation * weight
-}
-
-// 余弦相似度计算
-func (c *CollaborativeFilteringEngine) cosineSimilarity(ratings1 map[string]float64, ratings2 map[string]float64) float64 {
-	// 找到共同用户
-	commonUsers := []string{}
-	for userID := range ratings1 {
-		if _, exists := ratings2[userID]; exists {
-			commonUsers = append(commonUsers, userID)
+	candidates := make(map[string]bool)
+	for userID := range c.itemUserMatrix[itemID] {
+		for otherItemID := range c.userItemMatrix[userID] {
+			if otherItemID != itemID {
+				candidates[otherItemID] = true
+			}
 		}
 	}
 	
-	if len(commonUsers) == 0 {
-		return 0.0
-	}
-	
-	// 计算点积和模
-	var dotProduct, norm1, norm2 float64
-	for _, userID := range commonUsers {
-		rating1 := ratings1[userID]
-		rating2 := ratings2[userID]
-		dotProduct += rating1 * rating2
-		norm1 += rating1 * rating1
-		norm2 += rating2 * rating2
+	neighbors := make([]SimilarItem, 0, len(candidates))
+	for otherItemID := range candidates {
+		similarity := c.calculateItemSimilarity(itemID, otherItemID, context)
+		if similarity >= c.config.SimilarityThreshold {
+			neighbors = append(neighbors, SimilarItem{ItemID: otherItemID, Similarity: similarity})
+		}
 	}
+	sortNeighbors(neighbors)
 	
-	// 避免除零
-	if norm1 == 0 || norm2 == 0 {
-		return 0.0
+	if len(neighbors) > c.config.MaxNeighbors {
+		neighbors = neighbors[:c.config.MaxNeighbors]
 	}
-	
-	return dotProduct / math.Sqrt(norm1*norm2)
+	return neighbors
 }
 
 // 找到相似用户
 func (c *CollaborativeFilteringEngine) findSimilarUsers(userID string) []SimilarUser {
 	similarUsers := []SimilarUser{}
+	context := c.userSimilarityContext()
 	
 	for otherUserID := range c.userItemMatrix {
 		if otherUserID == userID {
 			continue
 		}
 		
-		similarity := c.CalculateUserSimilarity(userID, otherUserID)
+		similarity := c.calculateUserSimilarity(userID, otherUserID, context)
 		if similarity >= c.config.SimilarityThreshold {
 			similarUsers = append(similarUsers, SimilarUser{
 				UserID:     otherUserID,
@@ -329,53 +351,100 @@ func (c *CollaborativeFilteringEngine) findSimilarUsers(userID string) []Similar
 	return similarUsers
 }
 
-// 找到相似物品
-func (c *CollaborativeFilteringEngine) findSimilarItems(itemID string) []SimilarItem {
-	similarItems := []SimilarItem{}
-	
-	for otherItemID := range c.itemUserMatrix {
-		if otherItemID == itemID {
-			continue
-		}
-		
-		similarity := c.CalculateItemSimilarity(itemID, otherItemID)
-		if similarity >= c.config.SimilarityThreshold {
-			similarItems = append(similarItems, SimilarItem{
-				ItemID:     otherItemID,
-				Similarity: similarity,
-			})
+// 后台全量重建物品相似度索引，构建期间索引仍可查询并继续增量更新
+func (c *CollaborativeFilteringEngine) RebuildItemIndex() {
+	c.mu.RLock()
+	snapshot := make(map[string]map[string]float64, len(c.userItemMatrix))
+	for userID, ratings := range c.userItemMatrix {
+		copied := make(map[string]float64, len(ratings))
+		for itemID, rating := range ratings {
+			copied[itemID] = rating
 		}
+		snapshot[userID] = copied
 	}
-	
-	// 按相似度排序
-	sort.Slice(similarItems, func(i, j int) bool {
-		return similarItems[i].Similarity > similarItems[j].Similarity
-	})
-	
-	// 限制邻居数量
-	if len(similarItems) > c.config.MaxNeighbors {
-		similarItems = similarItems[:c.config.MaxNeighbors]
+	k := c.config.MaxNeighbors
+	threshold := c.config.SimilarityThreshold
+	// 持有读锁时开始构建，保证快照之后的评分写入都会被记录重放
+	generation := c.itemIndex.beginBuild()
+	c.mu.RUnlock()
+
+	c.itemIndex.build(generation, snapshot, k, threshold)
+}
+
+// 获取用户评分记录
+func (c *CollaborativeFilteringEngine) GetUserRatings(userID string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ratings := make(map[string]float64, len(c.userItemMatrix[userID]))
+	for itemID, rating := range c.userItemMatrix[userID] {
+		ratings[itemID] = rating
 	}
-	
-	return similarItems
+	return ratings
 }
 
-// 构建物品相似度矩阵
-func (c *CollaborativeFilteringEngine) buildItemSimilarityMatrix() {
-	c.itemSimilarity = make(map[string]map[string]float64)
-	
-	for itemID1 := range c.itemUserMatrix {
-		c.itemSimilarity[itemID1] = make(map[string]float64)
-		
-		for itemID2 := range c.itemUserMatrix {
-			if itemID1 == itemID2 {
-				continue
-			}
-			
-			similarity := c.CalculateItemSimilarity(itemID1, itemID2)
-			c.itemSimilarity[itemID1][itemID2] = similarity
-		}
+// 获取物品的评分用户
+func (c *CollaborativeFilteringEngine) GetItemRatings(itemID string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ratings := make(map[string]float64, len(c.itemUserMatrix[itemID]))
+	for userID, rating := range c.itemUserMatrix[itemID] {
+		ratings[userID] = rating
+	}
+	return ratings
+}
+
+// 设置配置
+func (c *CollaborativeFilteringEngine) SetConfig(config *CollaborativeFilteringConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.config
+	c.config = config
+	if *old == *config {
+		return
+	}
+	// 用户相似度依赖配置，清空缓存；物品索引只依赖邻居数、相似度阈值和物品相似度度量，变化时才在后台重建
+	c.userSimilarity = make(map[string]map[string]float64)
+	if old.MaxNeighbors != config.MaxNeighbors ||
+		old.SimilarityThreshold != config.SimilarityThreshold ||
+		old.ItemSimilarity != config.ItemSimilarity {
+		go c.RebuildItemIndex()
+	}
+	c.log.Info("更新协同过滤配置")
+}
+
+// 获取配置
+func (c *CollaborativeFilteringEngine) GetConfig() *CollaborativeFilteringConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.config
+}
+
+// 获取算法统计信息
+func (c *CollaborativeFilteringEngine) GetStats() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ratingCount := 0
+	for _, ratings := range c.userItemMatrix {
+		ratingCount += len(ratings)
+	}
+
+	stats := map[string]interface{}{
+		"user_count":      len(c.userItemMatrix),
+		"item_count":      len(c.itemUserMatrix),
+		"rating_count":    ratingCount,
+		"user_similarity": c.config.UserSimilarity,
+		"item_similarity": c.config.ItemSimilarity,
+		"normalization":   c.config.NormalizationMethod,
+	}
+	for key, value := range c.itemIndex.GetStats() {
+		stats[key] = value
 	}
+	return stats
 }
 
 // 推荐结果
@@ -298,6 +298,110 @@ func (c *ContentBasedFilteringEngine) calculateCategorySimilarity(userPreference
 	return 0.0
 }
 
+// 计算用户画像与指定物品的相似度，不受相似度阈值限制，用户画像或物品特征不存在时不返回
+func (c *ContentBasedFilteringEngine) ScoreItems(userID string, itemIDs []string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	scores := make(map[string]float64)
+	profile, exists := c.userProfiles[userID]
+	if !exists {
+		return scores
+	}
+	for _, itemID := range itemIDs {
+		if item, exists := c.itemFeatures[itemID]; exists {
+			scores[itemID] = c.calculateSimilarity(profile, item)
+		}
+	}
+	return scores
+}
+
+// 用户对类别的偏好：用户历史交互评分中该类别所占的比例，取值[0,1]
+func (c *ContentBasedFilteringEngine) CategoryAffinity(userID string, category string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	total, matched := 0.0, 0.0
+	for itemID, rating := range c.userItemHistory[userID] {
+		if rating <= 0 {
+			continue
+		}
+		total += rating
+		if item, exists := c.itemFeatures[itemID]; exists && item.Category == category {
+			matched += rating
+		}
+	}
+	if total == 0 || category == "" {
+		return 0
+	}
+	return matched / total
+}
+
+// 计算两个物品的内容相似度：特征余弦、关键词Jaccard与类别是否相同的加权和
+func (c *ContentBasedFilteringEngine) ItemContentSimilarity(itemID1 string, itemID2 string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item1, exists1 := c.itemFeatures[itemID1]
+	item2, exists2 := c.itemFeatures[itemID2]
+	if !exists1 || !exists2 {
+		return 0.0
+	}
+
+	keywords := make(map[string]bool, len(item1.Keywords))
+	for _, keyword := range item1.Keywords {
+		keywords[keyword] = true
+	}
+	common := 0
+	union := len(keywords)
+	for _, keyword := range item2.Keywords {
+		if keywords[keyword] {
+			common++
+		} else {
+			union++
+		}
+	}
+	keywordSimilarity := 0.0
+	if union > 0 {
+		keywordSimilarity = float64(common) / float64(union)
+	}
+
+	categorySimilarity := 0.0
+	if item1.Category != "" && item1.Category == item2.Category {
+		categorySimilarity = 1.0
+	}
+
+	return 0.5*c.calculateFeatureSimilarity(item1.Features, item2.Features) + 0.3*keywordSimilarity + 0.2*categorySimilarity
+}
+
+// 获取物品的特征向量，用于多样性重排计算物品之间的相似度
+func (c *ContentBasedFilteringEngine) ItemVector(itemID string) (map[string]float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.itemVector(itemID)
+}
+
+// 物品特征向量：数值与离散特征、类别独热以及关键词，关键词部分归一化为单位长度，调用方需持有读锁
+func (c *ContentBasedFilteringEngine) itemVector(itemID string) (map[string]float64, bool) {
+	item, exists := c.itemFeatures[itemID]
+	if !exists {
+		return nil, false
+	}
+
+	vector := make(map[string]float64, len(item.Features)+len(item.Keywords)+1)
+	for feature, value := range item.Features {
+		vector[feature] = value
+	}
+	if item.Category != "" {
+		vector["category:"+item.Category] = 1.0
+	}
+	for _, keyword := range item.Keywords {
+		vector["keyword:"+keyword] = 1.0 / math.Sqrt(float64(len(item.Keywords)))
+	}
+	return vector, true
+}
+
 // 获取用户画像
 func (c *ContentBasedFilteringEngine) GetUserProfile(userID string) (*UserProfile, bool) {
 	c.mu.RLock()
@@ -333,6 +437,80 @@ func (c *ContentBasedFilteringEngine) UpdateUserPreference(userID string, prefer
 	}).Debug("更新用户偏好")
 }
 
+// 添加物品内容（从标题、描述和标签中提取关键词）
+func (c *ContentBasedFilteringEngine) AddItemContent(itemID string, category string, title string, description string, tags []string, features map[string]float64) {
+	keywordSet := make(map[string]bool)
+	keywords := make([]string, 0)
+	for _, word := range append(c.preprocessText(title+" "+description), tags...) {
+		word = strings.ToLower(word)
+		if !keywordSet[word] {
+			keywordSet[word] = true
+			keywords = append(keywords, word)
+		}
+	}
+
+	if len(keywords) > c.GetConfig().MaxFeatures {
+		keywords = keywords[:c.GetConfig().MaxFeatures]
+	}
+
+	c.AddItemFeatures(itemID, category, keywords, features)
+}
+
+// 获取物品特征
+func (c *ContentBasedFilteringEngine) GetItemFeatures(itemID string) (*ItemFeatures, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, exists := c.itemFeatures[itemID]
+	return &item, exists
+}
+
+// 设置配置
+func (c *ContentBasedFilteringEngine) SetConfig(config *ContentBasedFilteringConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.config = config
+	c.log.Info("更新内容过滤配置")
+}
+
+// 获取配置
+func (c *ContentBasedFilteringEngine) GetConfig() *ContentBasedFilteringConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.config
+}
+
+// 获取算法统计信息
+func (c *ContentBasedFilteringEngine) GetStats() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	historyCount := 0
+	for _, history := range c.userItemHistory {
+		historyCount += len(history)
+	}
+
+	return map[string]interface{}{
+		"user_profile_count": len(c.userProfiles),
+		"item_feature_count": len(c.itemFeatures),
+		"history_count":      historyCount,
+	}
+}
+
+// 获取用户交互历史
+func (c *ContentBasedFilteringEngine) GetUserHistory(userID string) map[string]float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	history := make(map[string]float64, len(c.userItemHistory[userID]))
+	for itemID, rating := range c.userItemHistory[userID] {
+		history[itemID] = rating
+	}
+	return history
+}
+
 // 获取热门关键词
 func (c *ContentBasedFilteringEngine) GetPopularKeywords(limit int) []string {
 	c.mu.RLock()
@@ -5,7 +5,9 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/guanguoyintao/luban/internal/recommendation/diversity"
 	"github.com/sirupsen/logrus"
 )
 
@@ -14,9 +16,31 @@ type HybridFilteringEngine struct {
 	mu              sync.RWMutex
 	collaborative   *CollaborativeFilteringEngine   // 协同过滤引擎
 	contentBased    *ContentBasedFilteringEngine    // 基于内容过滤引擎
-	weights         map[string]float64              // 算法权重
+	trending        *TrendingEngine                 // 趋势热度引擎，为空时按评分次数估计热度
+	weights         map[string]float64              // 手动设置的权重，覆盖所有场景学到的权重
+	scenarioWeights map[string]map[string]float64   // 手动设置的场景权重，优先于全局手动权重
+	learner         *BlendLearner                   // 按场景从用户反馈学习混合权重
 	log             *logrus.Logger
 	config          *HybridFilteringConfig
+	
+	feedbackMu      sync.Mutex
+	served          map[string]map[string]servedItem // 最近推荐给用户的物品
+	userBalance     map[string]float64                    // 用户反馈对协同过滤权重占比的调整量
+}
+
+// 每个用户保留的最近推荐物品数
+const maxServedPerUser = 200
+
+// 用户组件权重占比的范围
+const (
+	minComponentShare = 0.1
+	maxComponentShare = 0.9
+)
+
+// 推荐给用户的物品：推荐场景与组件得分，组件得分顺序同 BlendComponents
+type servedItem struct {
+	scenario string
+	features []float64
 }
 
 // 混合过滤配置
@@ -27,8 +51,15 @@ type HybridFilteringConfig struct {
 	PopularityWeight      float64 // 流行度权重
 	RecencyWeight        float64 // 时效性权重
 	EnableDiversity      bool    // 是否启用多样性
+	DiversityMethod      diversity.Method // 多样性重排方法：mmr 或 dpp
+	DiversityLambda      float64 // 多样性重排中相关性与多样性的权衡，1 时保持得分顺序
 	EnablePopularity     bool    // 是否启用流行度
 	EnableRecency        bool    // 是否启用时效性
+	FeedbackLearningRate float64 // 用户反馈调整协同过滤与内容过滤权重占比的学习率
+	EnableBlendLearning  bool    // 是否按场景从用户反馈学习混合权重
+	BlendLearningRate    float64 // 混合权重学习率
+	BlendL2              float64 // 混合权重L2正则系数
+	BlendMinSamples      int     // 场景反馈样本数达到后才使用学到的混合权重
 }
 
 // 混合推荐结果
@@ -57,21 +88,37 @@ func NewHybridFilteringEngine(collaborative *CollaborativeFilteringEngine, conte
 		PopularityWeight:      0.05,
 		RecencyWeight:        0.05,
 		EnableDiversity:      true,
+		DiversityMethod:      diversity.MethodMMR,
+		DiversityLambda:      0.7,
 		EnablePopularity:     true,
 		EnableRecency:        true,
+		FeedbackLearningRate: 0.1,
+		EnableBlendLearning:  true,
+		BlendLearningRate:    0.05,
+		BlendL2:              0.001,
+		BlendMinSamples:      100,
 	}
 	
 	return &HybridFilteringEngine{
 		collaborative: collaborative,
 		contentBased:  contentBased,
 		weights:       make(map[string]float64),
+		scenarioWeights: make(map[string]map[string]float64),
+		learner:       NewBlendLearner(),
 		log:           log,
 		config:        config,
+		served:        make(map[string]map[string]servedItem),
+		userBalance:   make(map[string]float64),
 	}
 }
 
 // 生成混合推荐
 func (h *HybridFilteringEngine) GenerateRecommendations(userID string, topN int) []HybridRecommendation {
+	return h.GenerateScenarioRecommendations(userID, "", topN)
+}
+
+// 按推荐场景的混合权重生成混合推荐
+func (h *HybridFilteringEngine) GenerateScenarioRecommendations(userID string, scenario string, topN int) []HybridRecommendation {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	
@@ -85,26 +132,69 @@ func (h *HybridFilteringEngine) GenerateRecommendations(userID string, topN int)
 	allRecommendations := h.mergeRecommendations(collaborativeRecs, contentBasedRecs)
 	
 	// 计算混合得分
-	hybridRecs := h.calculateHybridScores(userID, allRecommendations)
-	
-	// 应用多样性优化
-	if h.config.EnableDiversity {
-		hybridRecs = h.applyDiversityOptimization(hybridRecs)
-	}
+	hybridRecs := h.calculateHybridScores(userID, scenario, allRecommendations)
 	
 	// 按最终得分排序
 	sort.Slice(hybridRecs, func(i, j int) bool {
 		return hybridRecs[i].Score > hybridRecs[j].Score
 	})
 	
+	// 应用多样性重排
+	if h.config.EnableDiversity {
+		hybridRecs = h.applyDiversityOptimization(hybridRecs, topN)
+	}
+	
 	// 返回前N个推荐
 	if len(hybridRecs) > topN {
 		hybridRecs = hybridRecs[:topN]
 	}
 	
+	// 记录组件得分，用于根据反馈学习混合权重并调整用户的组件权重
+	h.rememberServed(userID, scenario, hybridRecs)
+	
 	return hybridRecs
 }
 
+// 计算指定物品的混合得分与组件得分，供排序阶段构造特征，不做多样性重排也不记录展示
+func (h *HybridFilteringEngine) ScoreItems(userID string, scenario string, itemIDs []string) []HybridRecommendation {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	requested := make(map[string]HybridRecommendation, len(itemIDs))
+	for _, itemID := range itemIDs {
+		requested[itemID] = HybridRecommendation{ItemID: itemID}
+	}
+	
+	// 协同过滤得分来自全部邻居物品，未被邻居评分的物品得分为0
+	for _, rec := range h.collaborative.UserBasedRecommend(userID, math.MaxInt32) {
+		if hybridRec, exists := requested[rec.ItemID]; exists {
+			hybridRec.CollaborativeScore = rec.Score
+			requested[rec.ItemID] = hybridRec
+		}
+	}
+	for itemID, score := range h.contentBased.ScoreItems(userID, itemIDs) {
+		hybridRec := requested[itemID]
+		hybridRec.ContentBasedScore = score
+		requested[itemID] = hybridRec
+	}
+	
+	return h.calculateHybridScores(userID, scenario, requested)
+}
+
+// 物品热度得分，取值[0,1]
+func (h *HybridFilteringEngine) PopularityScore(itemID string) float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.calculatePopularityScore(itemID)
+}
+
+// 物品时效性得分，取值[0,1]
+func (h *HybridFilteringEngine) RecencyScore(itemID string) float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.calculateRecencyScore(itemID)
+}
+
 // 合并推荐结果
 func (h *HybridFilteringEngine) mergeRecommendations(collaborativeRecs []Recommendation, contentBasedRecs []Recommendation) map[string]HybridRecommendation {
 	merged := make(map[string]HybridRecommendation)
@@ -143,13 +233,16 @@ func (h *HybridFilteringEngine) mergeRecommendations(collaborativeRecs []Recomme
 }
 
 // 计算混合得分
-func (h *HybridFilteringEngine) calculateHybridScores(userID string, recommendations map[string]HybridRecommendation) []HybridRecommendation {
+func (h *HybridFilteringEngine) calculateHybridScores(userID string, scenario string, recommendations map[string]HybridRecommendation) []HybridRecommendation {
 	results := make([]HybridRecommendation, 0, len(recommendations))
 	
+	weights := h.blendWeights(scenario)
+	collaborativeWeight, contentBasedWeight := h.userComponentWeights(userID, weights["collaborative"], weights["content_based"])
+	
 	for _, rec := range recommendations {
 		// 基础混合得分
-		baseScore := h.config.CollaborativeWeight*rec.CollaborativeScore + 
-					h.config.ContentBasedWeight*rec.ContentBasedScore
+		baseScore := collaborativeWeight*rec.CollaborativeScore + 
+					contentBasedWeight*rec.ContentBasedScore
 		
 		// 计算多样性得分
 		diversityScore := 0.0
@@ -171,9 +264,9 @@ func (h *HybridFilteringEngine) calculateHybridScores(userID string, recommendat
 		
 		// 最终得分
 		finalScore := baseScore + 
-					h.config.DiversityWeight*diversityScore + 
-					h.config.PopularityWeight*popularityScore + 
-					h.config.RecencyWeight*recencyScore
+					weights["diversity"]*diversityScore + 
+					weights["popularity"]*popularityScore + 
+					weights["recency"]*recencyScore
 		
 		rec.Score = finalScore
 		rec.DiversityScore = diversityScore
@@ -193,6 +286,11 @@ func (h *HybridFilteringEngine) calculateDiversityScore(userID string, itemID st
 	// 简单的多样性计算：基于物品类别的新颖性
 	// 这里可以扩展为更复杂的多样性算法
 	
+	h.collaborative.mu.RLock()
+	defer h.collaborative.mu.RUnlock()
+	h.contentBased.mu.RLock()
+	defer h.contentBased.mu.RUnlock()
+
 	// 获取用户历史中的物品类别
 	userHistory := h.collaborative.userItemMatrix[userID]
 	if len(userHistory) == 0 {
@@ -233,6 +331,13 @@ func (h *HybridFilteringEngine) calculateDiversityScore(userID string, itemID st
 
 // 计算流行度得分
 func (h *HybridFilteringEngine) calculatePopularityScore(itemID string) float64 {
+	if h.trending != nil {
+		return h.trending.PopularityScore(itemID)
+	}
+	
+	h.collaborative.mu.RLock()
+	defer h.collaborative.mu.RUnlock()
+
 	// 基于物品被评分的次数计算流行度
 	ratingCount := 0
 	for _, userRatings := range h.collaborative.userItemMatrix {
@@ -258,9 +363,16 @@ func (h *HybridFilteringEngine) calculatePopularityScore(itemID string) float64
 
 // 计算时效性得分
 func (h *HybridFilteringEngine) calculateRecencyScore(itemID string) float64 {
+	if h.trending != nil {
+		return h.trending.RecencyScore(itemID, time.Now())
+	}
+	
 	// 基于物品被评分的最近时间计算时效性
 	// 这里简化处理，实际应用中需要记录评分时间
 	
+	h.collaborative.mu.RLock()
+	defer h.collaborative.mu.RUnlock()
+
 	// 获取物品被评分的用户数作为时效性的简单指标
 	ratingCount := 0
 	for _, userRatings := range h.collaborative.userItemMatrix {
@@ -321,66 +433,43 @@ func (h *HybridFilteringEngine) generateRecommendationReason(rec HybridRecommend
 	return strings.Join(reasons, "，")
 }
 
-// 应用多样性优化
-func (h *HybridFilteringEngine) applyDiversityOptimization(recommendations []HybridRecommendation) []HybridRecommendation {
+// 应用多样性重排：按内容特征向量的相似度对前 topN 个位置做 MMR 或 DPP 重排，不丢弃推荐，
+// 重排后第k位的得分取第k高的混合得分，下游按得分排序时保持重排顺序
+func (h *HybridFilteringEngine) applyDiversityOptimization(recommendations []HybridRecommendation, topN int) []HybridRecommendation {
 	if len(recommendations) <= 1 {
 		return recommendations
 	}
 	
-	// 简单的多样性优化：确保推荐列表中不同类别的物品
-	optimized := []HybridRecommendation{recommendations[0]}
-	selectedCategories := make(map[string]bool)
-	
-	// 记录第一个物品的类别
-	if itemFeatures, exists := h.contentBased.itemFeatures[recommendations[0].ItemID]; exists {
-		selectedCategories[itemFeatures.Category] = true
+	candidates := make([]diversity.Candidate, len(recommendations))
+	h.contentBased.mu.RLock()
+	for i, rec := range recommendations {
+		vector, _ := h.contentBased.itemVector(rec.ItemID)
+		candidates[i] = diversity.Candidate{ID: rec.ItemID, Relevance: rec.Score, Vector: vector}
 	}
+	h.contentBased.mu.RUnlock()
 	
-	// 从剩余的推荐中选择多样性较高的物品
-	for i := 1; i < len(recommendations); i++ {
-		bestIdx := -1
-		bestDiversityScore := -1.0
-		
-		for j := i; j < len(recommendations); j++ {
-			itemFeatures, exists := h.contentBased.itemFeatures[recommendations[j].ItemID]
-			if !exists {
-				continue
-			}
-			
-			// 如果类别已经存在，降低多样性得分
-			diversityScore := recommendations[j].DiversityScore
-			if selectedCategories[itemFeatures.Category] {
-				diversityScore *= 0.5
-			}
-			
-			if diversityScore > bestDiversityScore {
-				bestDiversityScore = diversityScore
-				bestIdx = j
-			}
-		}
-		
-		if bestIdx != -1 {
-			// 交换位置
-			recommendations[i], recommendations[bestIdx] = recommendations[bestIdx], recommendations[i]
-			
-			// 记录选择的类别
-			if itemFeatures, exists := h.contentBased.itemFeatures[recommendations[i].ItemID]; exists {
-				selectedCategories[itemFeatures.Category] = true
-			}
-			
-			optimized = append(optimized, recommendations[i])
-		}
+	config := diversity.Config{Method: h.config.DiversityMethod, Lambda: h.config.DiversityLambda, Window: topN}
+	order, err := diversity.Rerank(candidates, config)
+	if err != nil {
+		h.log.WithError(err).Warn("多样性重排失败，保持得分顺序")
+		return recommendations
 	}
 	
-	return optimized
+	scores := diversity.PositionScores(candidates)
+	reranked := make([]HybridRecommendation, len(order))
+	for position, index := range order {
+		reranked[position] = recommendations[index]
+		reranked[position].Score = scores[position]
+	}
+	return reranked
 }
 
-// 更新权重
+// 更新权重：手动设置的权重覆盖所有场景学到的权重，为空时恢复使用学到的权重
 func (h *HybridFilteringEngine) UpdateWeights(weights map[string]float64) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	
-	h.weights = weights
+	h.weights = copyBlendWeights(weights)
 	
 	h.log.WithFields(logrus.Fields{
 		"collaborative_weight": weights["collaborative"],
@@ -391,6 +480,23 @@ func (h *HybridFilteringEngine) UpdateWeights(weights map[string]float64) {
 	}).Info("更新混合过滤权重")
 }
 
+// 更新场景权重：手动设置的场景权重覆盖该场景学到的权重，为空时恢复使用学到的权重
+func (h *HybridFilteringEngine) UpdateScenarioWeights(scenario string, weights map[string]float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	
+	if len(weights) == 0 {
+		delete(h.scenarioWeights, scenario)
+	} else {
+		h.scenarioWeights[scenario] = copyBlendWeights(weights)
+	}
+	
+	h.log.WithFields(logrus.Fields{
+		"scenario": scenario,
+		"weights":  weights,
+	}).Info("更新混合过滤场景权重")
+}
+
 // 获取权重
 func (h *HybridFilteringEngine) GetWeights() map[string]float64 {
 	h.mu.RLock()
@@ -406,6 +512,183 @@ func (h *HybridFilteringEngine) GetWeights() map[string]float64 {
 	return weights
 }
 
+// 获取场景实际使用的混合权重
+func (h *HybridFilteringEngine) GetScenarioWeights(scenario string) map[string]float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	return h.blendWeights(scenario)
+}
+
+// 场景的混合权重：依次以学到的权重、手动设置的权重、手动设置的场景权重覆盖配置的权重，调用方需持有锁
+func (h *HybridFilteringEngine) blendWeights(scenario string) map[string]float64 {
+	weights := map[string]float64{
+		"collaborative": h.config.CollaborativeWeight,
+		"content_based": h.config.ContentBasedWeight,
+		"diversity":     h.config.DiversityWeight,
+		"popularity":    h.config.PopularityWeight,
+		"recency":       h.config.RecencyWeight,
+	}
+	
+	if h.config.EnableBlendLearning {
+		// 学到的权重总和与配置的权重总和一致，混合得分保持同一量纲
+		total := 0.0
+		for _, weight := range weights {
+			total += weight
+		}
+		if learned, ok := h.learner.Weights(scenario, total, h.config.BlendMinSamples); ok {
+			weights = learned
+		}
+	}
+	
+	for _, overrides := range []map[string]float64{h.weights, h.scenarioWeights[scenario]} {
+		for name, weight := range overrides {
+			if _, exists := weights[name]; exists {
+				weights[name] = weight
+			}
+		}
+	}
+	return weights
+}
+
+// 获取混合权重学习器，用于持久化学到的权重
+func (h *HybridFilteringEngine) GetBlendLearner() *BlendLearner {
+	return h.learner
+}
+
+// 记录用户反馈：signal 取值[-1,1]，作为推荐场景混合权重学习的样本，
+// 同时正反馈使用户的权重占比向推荐该物品的组件移动，负反馈则相反
+func (h *HybridFilteringEngine) RecordFeedback(userID string, itemID string, signal float64) {
+	h.feedbackMu.Lock()
+	item, exists := h.served[userID][itemID]
+	if exists {
+		delete(h.served[userID], itemID)
+	}
+	h.feedbackMu.Unlock()
+	if !exists {
+		return
+	}
+	
+	h.mu.RLock()
+	config := *h.config
+	weights := h.blendWeights(item.scenario)
+	h.mu.RUnlock()
+	
+	if config.EnableBlendLearning && signal != 0 {
+		h.learner.Observe(item.scenario, item.features, signal > 0, config.BlendLearningRate, config.BlendL2)
+	}
+	
+	baseShare, ok := collaborativeShare(weights["collaborative"], weights["content_based"])
+	if !ok {
+		return
+	}
+	
+	// 只由一个组件推荐时该组件的目标占比为1，两者都推荐时不调整
+	collaborativeScore, contentBasedScore := item.features[0], item.features[1]
+	target := 0.5
+	switch {
+	case collaborativeScore > 0 && contentBasedScore <= 0:
+		target = 1
+	case contentBasedScore > 0 && collaborativeScore <= 0:
+		target = 0
+	}
+	
+	h.feedbackMu.Lock()
+	share := baseShare + h.userBalance[userID]
+	share += config.FeedbackLearningRate * signal * (target - share)
+	share = math.Max(minComponentShare, math.Min(maxComponentShare, share))
+	h.userBalance[userID] = share - baseShare
+	h.feedbackMu.Unlock()
+	
+	h.log.WithFields(logrus.Fields{
+		"user_id":             userID,
+		"item_id":             itemID,
+		"scenario":            item.scenario,
+		"signal":              signal,
+		"collaborative_share": share,
+	}).Debug("根据反馈调整混合权重")
+}
+
+// 获取用户在场景下的混合权重，包含反馈调整后的协同过滤与内容过滤权重
+func (h *HybridFilteringEngine) GetUserWeights(userID string, scenario string) map[string]float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	weights := h.blendWeights(scenario)
+	weights["collaborative"], weights["content_based"] = h.userComponentWeights(userID, weights["collaborative"], weights["content_based"])
+	return weights
+}
+
+// 用户的协同过滤与内容过滤权重：两者之和不变，占比按用户反馈调整
+func (h *HybridFilteringEngine) userComponentWeights(userID string, collaborativeWeight float64, contentBasedWeight float64) (float64, float64) {
+	baseShare, ok := collaborativeShare(collaborativeWeight, contentBasedWeight)
+	if !ok {
+		return collaborativeWeight, contentBasedWeight
+	}
+	
+	h.feedbackMu.Lock()
+	offset, exists := h.userBalance[userID]
+	h.feedbackMu.Unlock()
+	if !exists {
+		return collaborativeWeight, contentBasedWeight
+	}
+	
+	total := collaborativeWeight + contentBasedWeight
+	share := math.Max(minComponentShare, math.Min(maxComponentShare, baseShare+offset))
+	return total * share, total * (1 - share)
+}
+
+// 协同过滤权重的占比，两个组件权重都为0时返回false
+func collaborativeShare(collaborativeWeight float64, contentBasedWeight float64) (float64, bool) {
+	total := collaborativeWeight + contentBasedWeight
+	if total <= 0 {
+		return 0, false
+	}
+	return collaborativeWeight / total, true
+}
+
+// 复制权重，只保留混合权重名称
+func copyBlendWeights(weights map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(weights))
+	for _, name := range BlendComponents {
+		if weight, exists := weights[name]; exists {
+			copied[name] = weight
+		}
+	}
+	return copied
+}
+
+// 记录推荐给用户的物品的场景与组件得分，超出上限时清空该用户之前的记录
+func (h *HybridFilteringEngine) rememberServed(userID string, scenario string, recommendations []HybridRecommendation) {
+	h.feedbackMu.Lock()
+	defer h.feedbackMu.Unlock()
+	
+	served := h.served[userID]
+	if served == nil || len(served)+len(recommendations) > maxServedPerUser {
+		served = make(map[string]servedItem, len(recommendations))
+		h.served[userID] = served
+	}
+	for _, rec := range recommendations {
+		served[rec.ItemID] = servedItem{
+			scenario: scenario,
+			features: []float64{
+				rec.CollaborativeScore,
+				rec.ContentBasedScore,
+				rec.DiversityScore,
+				rec.PopularityScore,
+				rec.RecencyScore,
+			},
+		}
+	}
+}
+
+// 设置趋势热度引擎，流行度与时效性得分改为基于时间衰减的行为计数
+func (h *HybridFilteringEngine) SetTrendingEngine(trending *TrendingEngine) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.trending = trending
+}
+
 // 设置配置
 func (h *HybridFilteringEngine) SetConfig(config *HybridFilteringConfig) {
 	h.mu.Lock()
@@ -431,21 +714,14 @@ func (h *HybridFilteringEngine) GetPerformanceStats() map[string]interface{} {
 	stats := make(map[string]interface{})
 	
 	// 协同过滤统计
-	collaborativeStats := map[string]interface{}{
-		"user_count": len(h.collaborative.userItemMatrix),
-		"item_count": len(h.collaborative.itemUserMatrix),
-	}
-	stats["collaborative"] = collaborativeStats
+	stats["collaborative"] = h.collaborative.GetStats()
 	
 	// 内容过滤统计
-	contentBasedStats := map[string]interface{}{
-		"user_profile_count": len(h.contentBased.userProfiles),
-		"item_feature_count": len(h.contentBased.itemFeatures),
-	}
-	stats["content_based"] = contentBasedStats
+	stats["content_based"] = h.contentBased.GetStats()
 	
 	// 权重配置
-	stats["weights"] = h.GetWeights()
+	stats["weights"] = h.blendWeights("")
+	stats["blend_learning"] = h.learner.GetStats()
 	
 	return stats
 }
\ No newline at end of file
@@ -0,0 +1,305 @@
+package algorithms
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 物品相似度索引：预计算每个物品的Top-K相似物品，评分变化时增量维护
+//
+// 相似度与 cosineSimilarity 一致，只在共同评分用户上计算点积和模，
+// 因此每个物品对维护点积及双方在共同用户上的平方和即可增量更新。
+type ItemSimilarityIndex struct {
+	mu         sync.RWMutex
+	state      *similarityState
+	generation int64          // 全量构建代数，用于丢弃过期的构建结果
+	building   bool           // 是否正在后台全量构建
+	pending    []ratingChange // 构建期间到达的评分变化，构建完成后重放
+	lastBuild  time.Time
+	log        *logrus.Logger
+}
+
+// 索引数据
+type similarityState struct {
+	pairs     map[string]map[string]pairStats // 物品对统计量
+	neighbors map[string][]SimilarItem        // 物品的Top-K相似物品
+	k         int                             // 每个物品保留的邻居数
+	threshold float64                         // 相似度阈值
+}
+
+// 物品对在共同评分用户上的统计量
+type pairStats struct {
+	dot     float64 // 评分点积
+	selfSq  float64 // 本物品评分平方和
+	otherSq float64 // 另一物品评分平方和
+}
+
+// 评分变化
+type ratingChange struct {
+	itemID    string
+	oldRating float64
+	newRating float64
+	existed   bool               // 用户此前是否已评分该物品
+	coRatings map[string]float64 // 同一用户对其他物品的评分
+}
+
+// 创建新的物品相似度索引
+func NewItemSimilarityIndex(k int, threshold float64, log *logrus.Logger) *ItemSimilarityIndex {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &ItemSimilarityIndex{
+		state: newSimilarityState(k, threshold),
+		log:   log,
+	}
+}
+
+// 获取物品的Top-K相似物品，返回的列表只读
+func (idx *ItemSimilarityIndex) Neighbors(itemID string) []SimilarItem {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.state.neighbors[itemID]
+}
+
+// 获取两个物品的相似度
+func (idx *ItemSimilarityIndex) Similarity(itemID1 string, itemID2 string) float64 {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.state.pairs[itemID1][itemID2].similarity()
+}
+
+// 增量更新：用户对物品的评分由 oldRating 变为 newRating
+// coRatings 为该用户对其他物品的评分，仅在调用期间读取
+func (idx *ItemSimilarityIndex) Update(itemID string, oldRating float64, newRating float64, existed bool, coRatings map[string]float64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	change := ratingChange{
+		itemID:    itemID,
+		oldRating: oldRating,
+		newRating: newRating,
+		existed:   existed,
+		coRatings: coRatings,
+	}
+	idx.state.apply(change)
+
+	if idx.building {
+		copied := make(map[string]float64, len(coRatings))
+		for otherID, rating := range coRatings {
+			copied[otherID] = rating
+		}
+		change.coRatings = copied
+		idx.pending = append(idx.pending, change)
+	}
+}
+
+// 开始全量构建，返回构建代数；此后的增量更新会被记录以便重放
+// 调用方需保证获取评分快照与本调用之间没有新的评分写入
+func (idx *ItemSimilarityIndex) beginBuild() int64 {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.generation++
+	idx.building = true
+	idx.pending = nil
+	return idx.generation
+}
+
+// 基于评分快照全量构建索引，完成后重放构建期间的增量更新并替换
+func (idx *ItemSimilarityIndex) build(generation int64, userItemMatrix map[string]map[string]float64, k int, threshold float64) {
+	startTime := time.Now()
+
+	state := newSimilarityState(k, threshold)
+	for _, ratings := range userItemMatrix {
+		for itemID1, rating1 := range ratings {
+			for itemID2, rating2 := range ratings {
+				if itemID1 == itemID2 {
+					continue
+				}
+				if state.pairs[itemID1] == nil {
+					state.pairs[itemID1] = make(map[string]pairStats)
+				}
+				p := state.pairs[itemID1][itemID2]
+				p.dot += rating1 * rating2
+				p.selfSq += rating1 * rating1
+				p.otherSq += rating2 * rating2
+				state.pairs[itemID1][itemID2] = p
+			}
+		}
+	}
+	for itemID := range state.pairs {
+		state.refill(itemID)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if generation != idx.generation {
+		// 已有更新的构建在进行，丢弃本次结果
+		return
+	}
+
+	for _, change := range idx.pending {
+		state.apply(change)
+	}
+	idx.state = state
+	idx.building = false
+	idx.pending = nil
+	idx.lastBuild = time.Now()
+
+	idx.log.WithFields(logrus.Fields{
+		"items":       len(state.pairs),
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	}).Info("物品相似度索引构建完成")
+}
+
+// 获取索引统计信息
+func (idx *ItemSimilarityIndex) GetStats() map[string]interface{} {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	pairCount := 0
+	for _, pairs := range idx.state.pairs {
+		pairCount += len(pairs)
+	}
+
+	return map[string]interface{}{
+		"indexed_items":    len(idx.state.neighbors),
+		"indexed_pairs":    pairCount / 2,
+		"neighbors_k":      idx.state.k,
+		"index_building":   idx.building,
+		"index_last_build": idx.lastBuild,
+	}
+}
+
+// 创建空的索引数据
+func newSimilarityState(k int, threshold float64) *similarityState {
+	return &similarityState{
+		pairs:     make(map[string]map[string]pairStats),
+		neighbors: make(map[string][]SimilarItem),
+		k:         k,
+		threshold: threshold,
+	}
+}
+
+// 应用一次评分变化，只有该用户评分过的物品对受影响
+func (s *similarityState) apply(change ratingChange) {
+	itemID := change.itemID
+	for otherID, otherRating := range change.coRatings {
+		if otherID == itemID {
+			continue
+		}
+
+		if s.pairs[itemID] == nil {
+			s.pairs[itemID] = make(map[string]pairStats)
+		}
+		if s.pairs[otherID] == nil {
+			s.pairs[otherID] = make(map[string]pairStats)
+		}
+
+		p := s.pairs[itemID][otherID]
+		if change.existed {
+			p.dot += (change.newRating - change.oldRating) * otherRating
+			p.selfSq += change.newRating*change.newRating - change.oldRating*change.oldRating
+		} else {
+			// 该用户成为两个物品的共同评分用户
+			p.dot += change.newRating * otherRating
+			p.selfSq += change.newRating * change.newRating
+			p.otherSq += otherRating * otherRating
+		}
+		s.pairs[itemID][otherID] = p
+		s.pairs[otherID][itemID] = pairStats{dot: p.dot, selfSq: p.otherSq, otherSq: p.selfSq}
+
+		similarity := p.similarity()
+		s.updateNeighbor(itemID, otherID, similarity)
+		s.updateNeighbor(otherID, itemID, similarity)
+	}
+}
+
+// 更新物品邻居列表中的单个条目
+// 查询方可能持有旧列表，因此总是在副本上修改
+func (s *similarityState) updateNeighbor(itemID string, otherID string, similarity float64) {
+	neighbors := append([]SimilarItem(nil), s.neighbors[itemID]...)
+	qualifies := similarity >= s.threshold
+
+	position := -1
+	for i, neighbor := range neighbors {
+		if neighbor.ItemID == otherID {
+			position = i
+			break
+		}
+	}
+
+	if position >= 0 {
+		decreased := similarity < neighbors[position].Similarity
+		if decreased && len(neighbors) >= s.k {
+			// 列表已满且得分下降，列表外的物品可能进入Top-K，重新计算
+			s.refill(itemID)
+			return
+		}
+		if !qualifies {
+			s.neighbors[itemID] = append(neighbors[:position], neighbors[position+1:]...)
+			return
+		}
+		neighbors[position].Similarity = similarity
+		sortNeighbors(neighbors)
+		s.neighbors[itemID] = neighbors
+		return
+	}
+
+	if !qualifies {
+		return
+	}
+	if len(neighbors) >= s.k && similarity <= neighbors[len(neighbors)-1].Similarity {
+		return
+	}
+
+	neighbors = append(neighbors, SimilarItem{ItemID: otherID, Similarity: similarity})
+	sortNeighbors(neighbors)
+	if len(neighbors) > s.k {
+		neighbors = neighbors[:s.k]
+	}
+	s.neighbors[itemID] = neighbors
+}
+
+// 根据物品对统计量重新计算物品的Top-K邻居
+func (s *similarityState) refill(itemID string) {
+	neighbors := make([]SimilarItem, 0)
+	for otherID, p := range s.pairs[itemID] {
+		similarity := p.similarity()
+		if similarity >= s.threshold {
+			neighbors = append(neighbors, SimilarItem{ItemID: otherID, Similarity: similarity})
+		}
+	}
+
+	sortNeighbors(neighbors)
+	if len(neighbors) > s.k {
+		neighbors = neighbors[:s.k]
+	}
+	s.neighbors[itemID] = neighbors
+}
+
+// 余弦相似度
+func (p pairStats) similarity() float64 {
+	if p.selfSq <= 0 || p.otherSq <= 0 {
+		return 0.0
+	}
+	return p.dot / math.Sqrt(p.selfSq*p.otherSq)
+}
+
+// 按相似度降序排序，相同时按物品ID排序保证结果稳定
+func sortNeighbors(neighbors []SimilarItem) {
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Similarity != neighbors[j].Similarity {
+			return neighbors[i].Similarity > neighbors[j].Similarity
+		}
+		return neighbors[i].ItemID < neighbors[j].ItemID
+	})
+}
@@ -0,0 +1,484 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 矩阵分解推荐算法（隐式反馈交替最小二乘，ALS）
+type MatrixFactorizationEngine struct {
+	mu           sync.RWMutex
+	interactions map[string]map[string]float64 // 用户-物品交互强度
+	userFactors  map[string][]float64          // 用户隐向量
+	itemFactors  map[string][]float64          // 物品隐向量
+	itemGram     [][]float64                   // 物品隐向量格拉姆矩阵，用于折叠新用户
+	pendingCount int                           // 上次训练后新增的交互数
+	lastTrained  time.Time                     // 上次训练时间
+	log          *logrus.Logger
+	config       *MatrixFactorizationConfig
+}
+
+// 矩阵分解配置
+type MatrixFactorizationConfig struct {
+	Factors        int     // 隐向量维度
+	Iterations     int     // 迭代次数
+	Regularization float64 // 正则化系数
+	Alpha          float64 // 置信度系数，c = 1 + alpha * r
+	InitStdDev     float64 // 隐向量初始化标准差
+	Seed           int64   // 随机种子
+}
+
+// 创建新的矩阵分解引擎
+func NewMatrixFactorizationEngine(log *logrus.Logger) *MatrixFactorizationEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &MatrixFactorizationConfig{
+		Factors:        16,
+		Iterations:     10,
+		Regularization: 1.0,
+		Alpha:          40.0,
+		InitStdDev:     0.01,
+		Seed:           42,
+	}
+
+	return &MatrixFactorizationEngine{
+		interactions: make(map[string]map[string]float64),
+		userFactors:  make(map[string][]float64),
+		itemFactors:  make(map[string][]float64),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 添加用户交互数据（同一用户物品的交互强度累加）
+func (m *MatrixFactorizationEngine) AddInteraction(userID string, itemID string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.interactions[userID] == nil {
+		m.interactions[userID] = make(map[string]float64)
+	}
+	m.interactions[userID][itemID] += value
+	m.pendingCount++
+
+	m.log.WithFields(logrus.Fields{
+		"user_id": userID,
+		"item_id": itemID,
+		"value":   value,
+	}).Debug("添加矩阵分解交互数据")
+}
+
+// 训练模型，训练期间不阻塞推荐请求
+func (m *MatrixFactorizationEngine) Train() {
+	startTime := time.Now()
+
+	// 复制训练数据快照
+	m.mu.RLock()
+	config := *m.config
+	interactions := make(map[string]map[string]float64, len(m.interactions))
+	for userID, items := range m.interactions {
+		copied := make(map[string]float64, len(items))
+		for itemID, value := range items {
+			copied[itemID] = value
+		}
+		interactions[userID] = copied
+	}
+	pending := m.pendingCount
+	m.mu.RUnlock()
+
+	if len(interactions) == 0 {
+		return
+	}
+
+	// 构建物品-用户倒排
+	itemUsers := make(map[string]map[string]float64)
+	for userID, items := range interactions {
+		for itemID, value := range items {
+			if itemUsers[itemID] == nil {
+				itemUsers[itemID] = make(map[string]float64)
+			}
+			itemUsers[itemID][userID] = value
+		}
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	userFactors := make(map[string][]float64, len(interactions))
+	itemFactors := make(map[string][]float64, len(itemUsers))
+	for _, userID := range sortedKeys(interactions) {
+		userFactors[userID] = randomVector(rng, config.Factors, config.InitStdDev)
+	}
+	for _, itemID := range sortedKeys(itemUsers) {
+		itemFactors[itemID] = randomVector(rng, config.Factors, config.InitStdDev)
+	}
+
+	// 交替固定一侧隐向量，求解另一侧的最小二乘
+	for iter := 0; iter < config.Iterations; iter++ {
+		itemGram := gramMatrix(itemFactors, config.Factors)
+		for userID, items := range interactions {
+			userFactors[userID] = solveImplicit(itemGram, itemFactors, items, &config)
+		}
+
+		userGram := gramMatrix(userFactors, config.Factors)
+		for itemID, users := range itemUsers {
+			itemFactors[itemID] = solveImplicit(userGram, userFactors, users, &config)
+		}
+	}
+
+	m.mu.Lock()
+	if m.config.Factors != config.Factors {
+		// 训练期间隐向量维度已变更，丢弃本次结果
+		m.mu.Unlock()
+		m.log.Warn("训练期间配置变更，丢弃矩阵分解训练结果")
+		return
+	}
+	m.userFactors = userFactors
+	m.itemFactors = itemFactors
+	m.itemGram = gramMatrix(itemFactors, config.Factors)
+	// 训练期间新出现的用户不在快照中，替换隐向量后重新折叠
+	for userID := range m.interactions {
+		if _, exists := m.userFactors[userID]; !exists {
+			m.foldInUser(userID)
+		}
+	}
+	m.pendingCount -= pending
+	m.lastTrained = time.Now()
+	m.mu.Unlock()
+
+	m.log.WithFields(logrus.Fields{
+		"users":      len(userFactors),
+		"items":      len(itemFactors),
+		"iterations": config.Iterations,
+		"duration":   time.Since(startTime).Milliseconds(),
+	}).Info("矩阵分解模型训练完成")
+}
+
+// 增量折叠新用户：固定物品隐向量，仅求解该用户的隐向量
+func (m *MatrixFactorizationEngine) FoldInUser(userID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.foldInUser(userID)
+}
+
+// 折叠用户，调用方需持有写锁
+func (m *MatrixFactorizationEngine) foldInUser(userID string) bool {
+	items := make(map[string]float64)
+	for itemID, value := range m.interactions[userID] {
+		if _, exists := m.itemFactors[itemID]; exists {
+			items[itemID] = value
+		}
+	}
+	if len(items) == 0 {
+		return false
+	}
+
+	m.userFactors[userID] = solveImplicit(m.itemGram, m.itemFactors, items, m.config)
+	return true
+}
+
+// 生成推荐
+func (m *MatrixFactorizationEngine) Recommend(userID string, topN int) []Recommendation {
+	m.mu.RLock()
+	_, trained := m.userFactors[userID]
+	m.mu.RUnlock()
+
+	// 未参与训练的用户先折叠进模型
+	if !trained && !m.FoldInUser(userID) {
+		return []Recommendation{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userVector := m.userFactors[userID]
+	seen := m.interactions[userID]
+
+	recommendations := make([]Recommendation, 0, len(m.itemFactors))
+	for itemID, itemVector := range m.itemFactors {
+		if _, exists := seen[itemID]; exists {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{
+			ItemID: itemID,
+			Score:  dot(userVector, itemVector),
+		})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+
+	if len(recommendations) > topN {
+		recommendations = recommendations[:topN]
+	}
+
+	return recommendations
+}
+
+// 预测用户对物品的偏好得分
+func (m *MatrixFactorizationEngine) Predict(userID string, itemID string) (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userVector, userExists := m.userFactors[userID]
+	itemVector, itemExists := m.itemFactors[itemID]
+	if !userExists || !itemExists {
+		return 0, false
+	}
+	return dot(userVector, itemVector), true
+}
+
+// 预测用户对一组物品的偏好得分，未参与训练的用户先折叠进模型，没有隐向量的物品不返回
+func (m *MatrixFactorizationEngine) PredictItems(userID string, itemIDs []string) map[string]float64 {
+	m.mu.RLock()
+	_, trained := m.userFactors[userID]
+	m.mu.RUnlock()
+
+	scores := make(map[string]float64, len(itemIDs))
+	if !trained && !m.FoldInUser(userID) {
+		return scores
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	userVector := m.userFactors[userID]
+	for _, itemID := range itemIDs {
+		if itemVector, exists := m.itemFactors[itemID]; exists {
+			scores[itemID] = dot(userVector, itemVector)
+		}
+	}
+	return scores
+}
+
+// 获取与指定物品隐向量最相似的物品
+func (m *MatrixFactorizationEngine) SimilarItems(itemID string, topN int) []SimilarItem {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	target, exists := m.itemFactors[itemID]
+	if !exists {
+		return []SimilarItem{}
+	}
+
+	similar := make([]SimilarItem, 0, len(m.itemFactors))
+	for otherID, vector := range m.itemFactors {
+		if otherID == itemID {
+			continue
+		}
+		similar = append(similar, SimilarItem{
+			ItemID:     otherID,
+			Similarity: cosine(target, vector),
+		})
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+
+	if len(similar) > topN {
+		similar = similar[:topN]
+	}
+
+	return similar
+}
+
+// 获取用户交互数据
+func (m *MatrixFactorizationEngine) GetUserInteractions(userID string) map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	interactions := make(map[string]float64, len(m.interactions[userID]))
+	for itemID, value := range m.interactions[userID] {
+		interactions[itemID] = value
+	}
+	return interactions
+}
+
+// 设置配置，隐向量维度变化后需要重新训练
+func (m *MatrixFactorizationEngine) SetConfig(config *MatrixFactorizationConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if config.Factors != m.config.Factors {
+		m.userFactors = make(map[string][]float64)
+		m.itemFactors = make(map[string][]float64)
+		m.itemGram = nil
+	}
+	m.config = config
+	m.log.Info("更新矩阵分解配置")
+}
+
+// 获取配置
+func (m *MatrixFactorizationEngine) GetConfig() *MatrixFactorizationConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config
+}
+
+// 获取算法统计信息
+func (m *MatrixFactorizationEngine) GetStats() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	interactionCount := 0
+	for _, items := range m.interactions {
+		interactionCount += len(items)
+	}
+
+	stats := map[string]interface{}{
+		"user_count":        len(m.interactions),
+		"interaction_count": interactionCount,
+		"trained_users":     len(m.userFactors),
+		"trained_items":     len(m.itemFactors),
+		"pending_count":     m.pendingCount,
+	}
+	if !m.lastTrained.IsZero() {
+		stats["last_trained"] = m.lastTrained
+	}
+	return stats
+}
+
+// 求解隐式反馈加权最小二乘：(G + Σ(c-1)·y·yᵀ + λI)·x = Σ c·y
+func solveImplicit(gram [][]float64, factors map[string][]float64, observed map[string]float64, config *MatrixFactorizationConfig) []float64 {
+	k := config.Factors
+	a := make([][]float64, k)
+	for i := range a {
+		a[i] = make([]float64, k)
+		copy(a[i], gram[i])
+		a[i][i] += config.Regularization
+	}
+	b := make([]float64, k)
+
+	for id, value := range observed {
+		vector, exists := factors[id]
+		if !exists {
+			continue
+		}
+		confidence := 1 + config.Alpha*value
+		for i := 0; i < k; i++ {
+			b[i] += confidence * vector[i]
+			for j := 0; j < k; j++ {
+				a[i][j] += (confidence - 1) * vector[i] * vector[j]
+			}
+		}
+	}
+
+	return choleskySolve(a, b)
+}
+
+// 计算隐向量的格拉姆矩阵 YᵀY
+func gramMatrix(factors map[string][]float64, k int) [][]float64 {
+	gram := make([][]float64, k)
+	for i := range gram {
+		gram[i] = make([]float64, k)
+	}
+	for _, vector := range factors {
+		for i := 0; i < k; i++ {
+			for j := i; j < k; j++ {
+				gram[i][j] += vector[i] * vector[j]
+			}
+		}
+	}
+	for i := 0; i < k; i++ {
+		for j := 0; j < i; j++ {
+			gram[i][j] = gram[j][i]
+		}
+	}
+	return gram
+}
+
+// Cholesky分解求解对称正定线性方程组
+func choleskySolve(a [][]float64, b []float64) []float64 {
+	n := len(b)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := a[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				// 数值误差导致非正定时加微小扰动
+				if sum <= 0 {
+					sum = 1e-10
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+
+	// 前代求解 L·y = b
+	y := make([]float64, n)
+	for i := 0; i < n; i++ {
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= l[i][k] * y[k]
+		}
+		y[i] = sum / l[i][i]
+	}
+
+	// 回代求解 Lᵀ·x = y
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := y[i]
+		for k := i + 1; k < n; k++ {
+			sum -= l[k][i] * x[k]
+		}
+		x[i] = sum / l[i][i]
+	}
+
+	return x
+}
+
+// 生成正态分布随机向量
+func randomVector(rng *rand.Rand, size int, stdDev float64) []float64 {
+	vector := make([]float64, size)
+	for i := range vector {
+		vector[i] = rng.NormFloat64() * stdDev
+	}
+	return vector
+}
+
+// 向量点积
+func dot(a []float64, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// 向量余弦相似度
+func cosine(a []float64, b []float64) float64 {
+	normA := math.Sqrt(dot(a, a))
+	normB := math.Sqrt(dot(b, b))
+	if normA == 0 || normB == 0 {
+		return 0.0
+	}
+	return dot(a, b) / (normA * normB)
+}
+
+// 按字典序返回映射的键，保证随机初始化可复现
+func sortedKeys(m map[string]map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
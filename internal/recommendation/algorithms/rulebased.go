@@ -0,0 +1,433 @@
+package algorithms
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 规则条件运算符
+const (
+	RuleOperatorEq          = "eq"           // 等于
+	RuleOperatorNe          = "ne"           // 不等于
+	RuleOperatorIn          = "in"           // 属于集合
+	RuleOperatorNotIn       = "not_in"       // 不属于集合
+	RuleOperatorContains    = "contains"     // 集合或字符串包含
+	RuleOperatorContainsAny = "contains_any" // 集合与集合有交集
+	RuleOperatorGt          = "gt"           // 大于
+	RuleOperatorGte         = "gte"          // 大于等于
+	RuleOperatorLt          = "lt"           // 小于
+	RuleOperatorLte         = "lte"          // 小于等于
+	RuleOperatorExists      = "exists"       // 字段存在
+)
+
+// 条件值以该前缀开头时引用请求上下文中的字段，例如 "$context.cart_brands"
+const ruleReferencePrefix = "$"
+
+// 基于规则的推荐算法
+type RuleBasedEngine struct {
+	mu    sync.RWMutex
+	rules []Rule
+	items map[string]RuleItem
+	log   *logrus.Logger
+}
+
+// 推荐规则
+type Rule struct {
+	Name           string          // 规则名称
+	Description    string          // 规则描述
+	Priority       int             // 优先级，物品命中多条规则时取优先级最高的规则
+	Score          float64         // 命中规则的物品得分
+	Limit          int             // 单条规则最多推荐的物品数，为0时不限制
+	SortBy         string          // 规则内物品排序字段（降序），为空时按物品ID排序
+	Reason         string          // 推荐理由，为空时使用规则描述
+	Conditions     []RuleCondition // 请求条件，全部满足时规则生效
+	ItemConditions []RuleCondition // 物品条件，全部满足的物品被推荐
+}
+
+// 规则条件
+type RuleCondition struct {
+	Field    string      // 字段路径，使用点号访问嵌套字段，例如 "context.cart_categories"
+	Operator string      // 运算符
+	Value    interface{} // 比较值
+}
+
+// 规则引擎中的物品
+type RuleItem struct {
+	ItemID     string
+	Category   string
+	Title      string
+	Tags       []string
+	Attributes map[string]interface{}
+}
+
+// 规则匹配结果
+type RuleMatch struct {
+	ItemID string
+	Score  float64
+	Rule   Rule
+}
+
+// 创建新的规则引擎
+func NewRuleBasedEngine(log *logrus.Logger) *RuleBasedEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &RuleBasedEngine{
+		rules: make([]Rule, 0),
+		items: make(map[string]RuleItem),
+		log:   log,
+	}
+}
+
+// 设置规则，校验失败时保留原有规则
+func (r *RuleBasedEngine) SetRules(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("规则名称不能为空")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("规则名称重复: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		for _, condition := range append(append([]RuleCondition{}, rule.Conditions...), rule.ItemConditions...) {
+			if err := validateCondition(condition); err != nil {
+				return fmt.Errorf("规则 %s: %w", rule.Name, err)
+			}
+		}
+	}
+
+	sorted := make([]Rule, len(rules))
+	copy(sorted, rules)
+	for i := range sorted {
+		if sorted[i].Score <= 0 {
+			sorted[i].Score = 1.0
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+
+	r.mu.Lock()
+	r.rules = sorted
+	r.mu.Unlock()
+
+	r.log.WithField("rules", len(sorted)).Info("更新推荐规则")
+	return nil
+}
+
+// 获取规则
+func (r *RuleBasedEngine) GetRules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := make([]Rule, len(r.rules))
+	copy(rules, r.rules)
+	return rules
+}
+
+// 添加或更新物品
+func (r *RuleBasedEngine) AddItem(item RuleItem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items[item.ItemID] = item
+}
+
+// 根据请求事实生成推荐，facts 为请求字段（scenario、user_id、context 等）
+func (r *RuleBasedEngine) Recommend(facts map[string]interface{}, topN int) []RuleMatch {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make(map[string]bool)
+	results := make([]RuleMatch, 0)
+
+	// 规则已按优先级排序，物品归属第一条命中的规则
+	for _, rule := range r.rules {
+		if !matchConditions(rule.Conditions, facts, facts) {
+			continue
+		}
+
+		candidates := make([]RuleItem, 0)
+		for _, item := range r.items {
+			if matched[item.ItemID] {
+				continue
+			}
+			if matchConditions(rule.ItemConditions, item.fields(), facts) {
+				candidates = append(candidates, item)
+			}
+		}
+		sortRuleItems(candidates, rule.SortBy)
+
+		if rule.Limit > 0 && len(candidates) > rule.Limit {
+			candidates = candidates[:rule.Limit]
+		}
+		for _, item := range candidates {
+			matched[item.ItemID] = true
+			results = append(results, RuleMatch{ItemID: item.ItemID, Score: rule.Score, Rule: rule})
+		}
+	}
+
+	// 同优先级的规则按得分排序，保持规则内部顺序
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rule.Priority != results[j].Rule.Priority {
+			return results[i].Rule.Priority > results[j].Rule.Priority
+		}
+		return results[i].Score > results[j].Score
+	})
+
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results
+}
+
+// 查找物品命中的规则
+func (r *RuleBasedEngine) MatchItem(facts map[string]interface{}, itemID string) (Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	item, exists := r.items[itemID]
+	if !exists {
+		return Rule{}, false
+	}
+
+	for _, rule := range r.rules {
+		if matchConditions(rule.Conditions, facts, facts) && matchConditions(rule.ItemConditions, item.fields(), facts) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// 获取算法统计信息
+func (r *RuleBasedEngine) GetStats() map[string]interface{} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return map[string]interface{}{
+		"rule_count": len(r.rules),
+		"item_count": len(r.items),
+	}
+}
+
+// 物品字段，属性同时以顶层字段和 attributes.<key> 访问
+func (item RuleItem) fields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(item.Attributes)+5)
+	for key, value := range item.Attributes {
+		fields[key] = value
+	}
+	fields["item_id"] = item.ItemID
+	fields["category"] = item.Category
+	fields["title"] = item.Title
+	fields["tags"] = item.Tags
+	fields["attributes"] = item.Attributes
+	return fields
+}
+
+// 校验条件
+func validateCondition(condition RuleCondition) error {
+	if condition.Field == "" {
+		return fmt.Errorf("条件字段不能为空")
+	}
+	switch condition.Operator {
+	case RuleOperatorEq, RuleOperatorNe, RuleOperatorIn, RuleOperatorNotIn, RuleOperatorContains,
+		RuleOperatorContainsAny, RuleOperatorGt, RuleOperatorGte, RuleOperatorLt, RuleOperatorLte, RuleOperatorExists:
+		return nil
+	default:
+		return fmt.Errorf("不支持的条件运算符: %s", condition.Operator)
+	}
+}
+
+// 判断条件是否全部满足，条件值中的引用从 facts 中解析
+func matchConditions(conditions []RuleCondition, target map[string]interface{}, facts map[string]interface{}) bool {
+	for _, condition := range conditions {
+		if !matchCondition(condition, target, facts) {
+			return false
+		}
+	}
+	return true
+}
+
+// 判断单个条件是否满足
+func matchCondition(condition RuleCondition, target map[string]interface{}, facts map[string]interface{}) bool {
+	actual, exists := lookupField(target, condition.Field)
+	if condition.Operator == RuleOperatorExists {
+		expected, ok := condition.Value.(bool)
+		if !ok {
+			expected = true
+		}
+		return exists == expected
+	}
+
+	expected := condition.Value
+	if reference, ok := expected.(string); ok && strings.HasPrefix(reference, ruleReferencePrefix) {
+		resolved, found := lookupField(facts, strings.TrimPrefix(reference, ruleReferencePrefix))
+		if !found {
+			// 引用缺失时仅否定类条件成立
+			return condition.Operator == RuleOperatorNe || condition.Operator == RuleOperatorNotIn
+		}
+		expected = resolved
+	}
+
+	switch condition.Operator {
+	case RuleOperatorEq:
+		return exists && valuesEqual(actual, expected)
+	case RuleOperatorNe:
+		return !exists || !valuesEqual(actual, expected)
+	case RuleOperatorIn:
+		return exists && containsValue(expected, actual)
+	case RuleOperatorNotIn:
+		return !exists || !containsValue(expected, actual)
+	case RuleOperatorContains:
+		if text, ok := actual.(string); ok {
+			if sub, ok := expected.(string); ok {
+				return strings.Contains(text, sub)
+			}
+		}
+		return exists && containsValue(actual, expected)
+	case RuleOperatorContainsAny:
+		for _, value := range toSlice(expected) {
+			if containsValue(actual, value) {
+				return true
+			}
+		}
+		return false
+	case RuleOperatorGt, RuleOperatorGte, RuleOperatorLt, RuleOperatorLte:
+		left, ok1 := toNumber(actual)
+		right, ok2 := toNumber(expected)
+		if !exists || !ok1 || !ok2 {
+			return false
+		}
+		switch condition.Operator {
+		case RuleOperatorGt:
+			return left > right
+		case RuleOperatorGte:
+			return left >= right
+		case RuleOperatorLt:
+			return left < right
+		default:
+			return left <= right
+		}
+	}
+	return false
+}
+
+// 按点号路径查找字段
+func lookupField(values map[string]interface{}, path string) (interface{}, bool) {
+	if value, exists := values[path]; exists {
+		return value, true
+	}
+
+	var current interface{} = values
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, exists := node[part]
+			if !exists {
+				return nil, false
+			}
+			current = value
+		case map[string]string:
+			value, exists := node[part]
+			if !exists {
+				return nil, false
+			}
+			current = value
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// 比较两个值，数值类型统一按浮点数比较，其余按字符串比较
+func valuesEqual(a interface{}, b interface{}) bool {
+	_, textA := a.(string)
+	_, textB := b.(string)
+	if !textA && !textB {
+		if x, ok := toNumber(a); ok {
+			if y, ok := toNumber(b); ok {
+				return x == y
+			}
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// 判断集合是否包含值
+func containsValue(collection interface{}, value interface{}) bool {
+	for _, element := range toSlice(collection) {
+		if valuesEqual(element, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// 将任意切片转换为 []interface{}，非切片视为单元素集合
+func toSlice(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	result := make([]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		result[i] = v.Index(i).Interface()
+	}
+	return result
+}
+
+// 转换为数值，支持数值字符串
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// 按字段降序排序，字段缺失的物品排在最后，相同时按物品ID排序
+func sortRuleItems(items []RuleItem, field string) {
+	sort.Slice(items, func(i, j int) bool {
+		if field != "" {
+			a, okA := lookupField(items[i].fields(), field)
+			b, okB := lookupField(items[j].fields(), field)
+			x, numA := toNumber(a)
+			y, numB := toNumber(b)
+			okA, okB = okA && numA, okB && numB
+			if okA != okB {
+				return okA
+			}
+			if okA && x != y {
+				return x > y
+			}
+		}
+		return items[i].ItemID < items[j].ItemID
+	})
+}
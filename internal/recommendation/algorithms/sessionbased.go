@@ -0,0 +1,431 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+)
+
+// 会话状态
+const (
+	SessionStatusActive    = "active"
+	SessionStatusCompleted = "completed"
+	SessionStatusExpired   = "expired"
+)
+
+// 基于会话的下一物品推荐算法：按空闲间隔切分会话，
+// 结合会话近邻（Session-kNN）与一阶马尔可夫转移统计，不依赖用户历史
+type SessionBasedEngine struct {
+	mu          sync.RWMutex
+	active      map[string]*trackedSession    // 会话键 -> 进行中的会话
+	archived    []*archivedSession            // 已结束的会话
+	itemIndex   map[string][]*archivedSession // 物品 -> 包含该物品的历史会话
+	transitions map[string]map[string]float64 // 物品转移计数
+	outgoing    map[string]float64            // 物品转出总数
+	sequence    int64
+	log         *logrus.Logger
+	config      *SessionBasedConfig
+}
+
+// 会话推荐配置
+type SessionBasedConfig struct {
+	IdleTimeout   time.Duration // 会话空闲超时，超过后开启新会话
+	Neighbors     int           // 近邻会话数
+	SampleSize    int           // 每次推荐参与相似度计算的最近候选会话数
+	MarkovWeight  float64       // 马尔可夫转移得分权重，其余为近邻得分
+	MinSessionLen int           // 参与训练的最小会话长度
+	MaxSessions   int           // 保留的历史会话上限
+}
+
+// 进行中的会话
+type trackedSession struct {
+	session   models.RecommendationSession
+	items     []string
+	lastEvent time.Time
+}
+
+// 已结束的会话
+type archivedSession struct {
+	id    string
+	items map[string]struct{}
+	end   time.Time
+}
+
+// 创建新的会话推荐引擎
+func NewSessionBasedEngine(log *logrus.Logger) *SessionBasedEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &SessionBasedConfig{
+		IdleTimeout:   30 * time.Minute,
+		Neighbors:     100,
+		SampleSize:    500,
+		MarkovWeight:  0.5,
+		MinSessionLen: 2,
+		MaxSessions:   100000,
+	}
+
+	return &SessionBasedEngine{
+		active:      make(map[string]*trackedSession),
+		archived:    make([]*archivedSession, 0),
+		itemIndex:   make(map[string][]*archivedSession),
+		transitions: make(map[string]map[string]float64),
+		outgoing:    make(map[string]float64),
+		log:         log,
+		config:      config,
+	}
+}
+
+// 记录会话事件，sessionKey 为会话标识（匿名用户可使用设备或Cookie标识），
+// 与上一事件间隔超过空闲超时时结束旧会话并开启新会话
+func (s *SessionBasedEngine) AddEvent(sessionKey string, userID string, itemID string, timestamp time.Time) models.RecommendationSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	tracked, exists := s.active[sessionKey]
+	if exists && timestamp.Sub(tracked.lastEvent) > s.config.IdleTimeout {
+		s.closeSession(sessionKey, tracked, SessionStatusCompleted)
+		exists = false
+	}
+	if !exists {
+		s.sequence++
+		tracked = &trackedSession{
+			session: models.RecommendationSession{
+				ID:        fmt.Sprintf("%s-%d", sessionKey, s.sequence),
+				UserID:    userID,
+				Status:    SessionStatusActive,
+				StartTime: timestamp,
+				Context:   make(map[string]interface{}),
+				Metadata:  make(map[string]interface{}),
+			},
+			items: make([]string, 0),
+		}
+		s.active[sessionKey] = tracked
+	}
+
+	// 连续重复的物品不计入转移
+	if n := len(tracked.items); n > 0 && tracked.items[n-1] != itemID {
+		previous := tracked.items[n-1]
+		if s.transitions[previous] == nil {
+			s.transitions[previous] = make(map[string]float64)
+		}
+		s.transitions[previous][itemID]++
+		s.outgoing[previous]++
+	}
+	if n := len(tracked.items); n == 0 || tracked.items[n-1] != itemID {
+		tracked.items = append(tracked.items, itemID)
+	}
+	if timestamp.After(tracked.lastEvent) {
+		tracked.lastEvent = timestamp
+	}
+	if tracked.session.UserID == "" {
+		tracked.session.UserID = userID
+	}
+	tracked.session.Metadata["item_count"] = len(tracked.items)
+
+	return tracked.snapshot()
+}
+
+// 结束空闲超时的会话，返回结束的会话数
+func (s *SessionBasedEngine) ExpireSessions(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for _, key := range sortedSessionKeys(s.active) {
+		tracked := s.active[key]
+		if now.Sub(tracked.lastEvent) > s.config.IdleTimeout {
+			s.closeSession(key, tracked, SessionStatusExpired)
+			expired++
+		}
+	}
+	return expired
+}
+
+// 获取进行中的会话
+func (s *SessionBasedEngine) GetSession(sessionKey string) (models.RecommendationSession, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tracked, exists := s.active[sessionKey]
+	if !exists {
+		return models.RecommendationSession{}, false
+	}
+	return tracked.snapshot(), true
+}
+
+// 获取进行中会话的物品序列
+func (s *SessionBasedEngine) SessionItems(sessionKey string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tracked, exists := s.active[sessionKey]
+	if !exists {
+		return []string{}
+	}
+	return append([]string(nil), tracked.items...)
+}
+
+// 根据当前会话的物品序列推荐下一物品
+func (s *SessionBasedEngine) Recommend(sessionItems []string, topN int) []Recommendation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(sessionItems) == 0 {
+		return []Recommendation{}
+	}
+
+	current := make(map[string]struct{}, len(sessionItems))
+	for _, itemID := range sessionItems {
+		current[itemID] = struct{}{}
+	}
+
+	knnScores := s.neighborScores(sessionItems)
+	markovScores := s.transitionScores(sessionItems[len(sessionItems)-1])
+	normalizeScores(knnScores)
+	normalizeScores(markovScores)
+
+	scores := make(map[string]float64)
+	for itemID, score := range knnScores {
+		scores[itemID] += (1 - s.config.MarkovWeight) * score
+	}
+	for itemID, score := range markovScores {
+		scores[itemID] += s.config.MarkovWeight * score
+	}
+
+	recommendations := make([]Recommendation, 0, len(scores))
+	for itemID, score := range scores {
+		if _, seen := current[itemID]; seen {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{ItemID: itemID, Score: score})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].ItemID < recommendations[j].ItemID
+	})
+
+	if len(recommendations) > topN {
+		recommendations = recommendations[:topN]
+	}
+	return recommendations
+}
+
+// 获取物品的转移概率
+func (s *SessionBasedEngine) TransitionProbability(fromItem string, toItem string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := s.outgoing[fromItem]
+	if total == 0 {
+		return 0
+	}
+	return s.transitions[fromItem][toItem] / total
+}
+
+// 设置配置
+func (s *SessionBasedEngine) SetConfig(config *SessionBasedConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = config
+	s.log.Info("更新会话推荐配置")
+}
+
+// 获取配置
+func (s *SessionBasedEngine) GetConfig() *SessionBasedConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.config
+}
+
+// 获取算法统计信息
+func (s *SessionBasedEngine) GetStats() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transitionCount := 0
+	for _, targets := range s.transitions {
+		transitionCount += len(targets)
+	}
+
+	return map[string]interface{}{
+		"active_sessions":   len(s.active),
+		"archived_sessions": len(s.archived),
+		"item_count":        len(s.itemIndex),
+		"transition_count":  transitionCount,
+		"idle_timeout":      s.config.IdleTimeout.String(),
+	}
+}
+
+// 结束会话并归档，调用方需持有写锁
+func (s *SessionBasedEngine) closeSession(key string, tracked *trackedSession, status string) {
+	delete(s.active, key)
+
+	end := tracked.lastEvent
+	tracked.session.Status = status
+	tracked.session.EndTime = &end
+
+	if len(tracked.items) < s.config.MinSessionLen {
+		return
+	}
+
+	archived := &archivedSession{
+		id:    tracked.session.ID,
+		items: make(map[string]struct{}, len(tracked.items)),
+		end:   end,
+	}
+	for _, itemID := range tracked.items {
+		archived.items[itemID] = struct{}{}
+	}
+	s.archived = append(s.archived, archived)
+	for itemID := range archived.items {
+		s.itemIndex[itemID] = append(s.itemIndex[itemID], archived)
+	}
+
+	if len(s.archived) > s.config.MaxSessions {
+		s.evictSessions()
+	}
+}
+
+// 淘汰最早的历史会话，每次淘汰上限的十分之一以分摊重建索引的开销
+func (s *SessionBasedEngine) evictSessions() {
+	sort.SliceStable(s.archived, func(i, j int) bool {
+		return s.archived[i].end.Before(s.archived[j].end)
+	})
+
+	keep := s.config.MaxSessions - s.config.MaxSessions/10
+	s.archived = append([]*archivedSession(nil), s.archived[len(s.archived)-keep:]...)
+
+	s.itemIndex = make(map[string][]*archivedSession)
+	for _, archived := range s.archived {
+		for itemID := range archived.items {
+			s.itemIndex[itemID] = append(s.itemIndex[itemID], archived)
+		}
+	}
+}
+
+// 会话近邻得分：当前会话中越靠后的物品权重越高
+func (s *SessionBasedEngine) neighborScores(sessionItems []string) map[string]float64 {
+	weights := make(map[string]float64, len(sessionItems))
+	for position, itemID := range sessionItems {
+		weights[itemID] = float64(position+1) / float64(len(sessionItems))
+	}
+
+	// 候选会话：与当前会话有共同物品的最近会话
+	candidates := make(map[*archivedSession]struct{})
+	for itemID := range weights {
+		for _, archived := range s.itemIndex[itemID] {
+			candidates[archived] = struct{}{}
+		}
+	}
+	sampled := make([]*archivedSession, 0, len(candidates))
+	for archived := range candidates {
+		sampled = append(sampled, archived)
+	}
+	sort.Slice(sampled, func(i, j int) bool {
+		if !sampled[i].end.Equal(sampled[j].end) {
+			return sampled[i].end.After(sampled[j].end)
+		}
+		return sampled[i].id < sampled[j].id
+	})
+	if len(sampled) > s.config.SampleSize {
+		sampled = sampled[:s.config.SampleSize]
+	}
+
+	type neighbor struct {
+		session    *archivedSession
+		similarity float64
+	}
+	neighbors := make([]neighbor, 0, len(sampled))
+	for _, archived := range sampled {
+		var overlap float64
+		for itemID, weight := range weights {
+			if _, exists := archived.items[itemID]; exists {
+				overlap += weight
+			}
+		}
+		similarity := overlap / math.Sqrt(float64(len(weights)*len(archived.items)))
+		neighbors = append(neighbors, neighbor{session: archived, similarity: similarity})
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].similarity > neighbors[j].similarity
+	})
+	if len(neighbors) > s.config.Neighbors {
+		neighbors = neighbors[:s.config.Neighbors]
+	}
+
+	scores := make(map[string]float64)
+	for _, n := range neighbors {
+		for itemID := range n.session.items {
+			scores[itemID] += n.similarity
+		}
+	}
+	return scores
+}
+
+// 一阶马尔可夫转移得分
+func (s *SessionBasedEngine) transitionScores(lastItem string) map[string]float64 {
+	scores := make(map[string]float64)
+	total := s.outgoing[lastItem]
+	if total == 0 {
+		return scores
+	}
+	for itemID, count := range s.transitions[lastItem] {
+		scores[itemID] = count / total
+	}
+	return scores
+}
+
+// 会话快照，复制可变字段
+func (t *trackedSession) snapshot() models.RecommendationSession {
+	session := t.session
+	session.Context = make(map[string]interface{}, len(t.session.Context))
+	for key, value := range t.session.Context {
+		session.Context[key] = value
+	}
+	session.Metadata = make(map[string]interface{}, len(t.session.Metadata))
+	for key, value := range t.session.Metadata {
+		session.Metadata[key] = value
+	}
+	return session
+}
+
+// 按最大值归一化得分
+func normalizeScores(scores map[string]float64) {
+	var maxScore float64
+	for _, score := range scores {
+		maxScore = math.Max(maxScore, score)
+	}
+	if maxScore == 0 {
+		return
+	}
+	for itemID := range scores {
+		scores[itemID] /= maxScore
+	}
+}
+
+// 按键排序的会话列表
+func sortedSessionKeys(sessions map[string]*trackedSession) []string {
+	keys := make([]string, 0, len(sessions))
+	for key := range sessions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,329 @@
+package algorithms
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// 内置相似度度量
+const (
+	SimilarityPearson        = "pearson"         // 皮尔逊相关系数，按共同评分数加权
+	SimilarityCosine         = "cosine"          // 余弦相似度
+	SimilarityAdjustedCosine = "adjusted_cosine" // 调整余弦相似度，评分减去所在维度的均值
+	SimilarityJaccard        = "jaccard"         // 杰卡德系数，只考虑是否评分
+	SimilarityTanimoto       = "tanimoto"        // 谷本系数，连续评分的杰卡德系数
+	SimilarityShrunk         = "shrunk"          // 收缩的皮尔逊相关系数，共同评分少时向0收缩
+	SimilarityBM25           = "bm25"            // BM25加权的余弦相似度，降低热门维度与高频评分的影响
+)
+
+// 评分归一化方法，用于基于用户的评分预测
+const (
+	NormalizationNone          = "none"           // 不归一化
+	NormalizationMeanCentering = "mean_centering" // 邻居评分减去邻居均值后加上目标用户均值
+	NormalizationZScore        = "z_score"        // 邻居评分标准化后按目标用户的均值与标准差还原
+)
+
+// 相似度度量：计算两个稀疏评分向量的相似度
+type SimilarityMetric func(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64
+
+var (
+	similarityMu      sync.RWMutex
+	similarityMetrics = map[string]SimilarityMetric{
+		SimilarityPearson:        pearsonSimilarity,
+		SimilarityCosine:         cosineSimilarity,
+		SimilarityAdjustedCosine: adjustedCosineSimilarity,
+		SimilarityJaccard:        jaccardSimilarity,
+		SimilarityTanimoto:       tanimotoSimilarity,
+		SimilarityShrunk:         shrunkSimilarity,
+		SimilarityBM25:           bm25Similarity,
+	}
+)
+
+// 注册相似度度量，同名度量会被替换
+func RegisterSimilarityMetric(name string, metric SimilarityMetric) {
+	similarityMu.Lock()
+	defer similarityMu.Unlock()
+
+	similarityMetrics[name] = metric
+}
+
+// 获取相似度度量
+func GetSimilarityMetric(name string) (SimilarityMetric, bool) {
+	similarityMu.RLock()
+	defer similarityMu.RUnlock()
+
+	metric, exists := similarityMetrics[name]
+	return metric, exists
+}
+
+// 获取已注册的相似度度量名称
+func SimilarityMetricNames() []string {
+	similarityMu.RLock()
+	defer similarityMu.RUnlock()
+
+	names := make([]string, 0, len(similarityMetrics))
+	for name := range similarityMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 判断是否为支持的归一化方法
+func IsValidNormalization(method string) bool {
+	switch method {
+	case NormalizationNone, NormalizationMeanCentering, NormalizationZScore:
+		return true
+	}
+	return false
+}
+
+// 相似度计算的上下文：评分向量所在维度的统计量按需计算并缓存，只在一次计算过程中使用
+type SimilarityContext struct {
+	Dimensions   map[string]map[string]float64 // 维度-评分，计算用户相似度时为物品-用户评分
+	VectorCount  int                           // 向量总数
+	Shrinkage    float64                       // 收缩系数
+	MaxNeighbors int                           // 皮尔逊相关系数按共同评分数加权的基准
+	BM25K1       float64                       // BM25评分饱和参数
+	BM25B        float64                       // BM25长度归一化参数
+
+	means         map[string]float64
+	idfs          map[string]float64
+	averageLength float64
+}
+
+// 维度上的平均评分
+func (s *SimilarityContext) DimensionMean(key string) float64 {
+	if mean, exists := s.means[key]; exists {
+		return mean
+	}
+	if s.means == nil {
+		s.means = make(map[string]float64)
+	}
+
+	mean, _ := meanAndStd(s.Dimensions[key])
+	s.means[key] = mean
+	return mean
+}
+
+// 维度的逆文档频率
+func (s *SimilarityContext) DimensionIDF(key string) float64 {
+	if idf, exists := s.idfs[key]; exists {
+		return idf
+	}
+	if s.idfs == nil {
+		s.idfs = make(map[string]float64)
+	}
+
+	n := float64(len(s.Dimensions[key]))
+	idf := math.Log(1 + (float64(s.VectorCount)-n+0.5)/(n+0.5))
+	s.idfs[key] = idf
+	return idf
+}
+
+// 向量的平均长度
+func (s *SimilarityContext) AverageLength() float64 {
+	if s.averageLength > 0 {
+		return s.averageLength
+	}
+	if s.VectorCount == 0 {
+		return 1
+	}
+
+	total := 0
+	for _, ratings := range s.Dimensions {
+		total += len(ratings)
+	}
+	s.averageLength = math.Max(float64(total)/float64(s.VectorCount), 1)
+	return s.averageLength
+}
+
+// 两个向量的共同维度
+func commonKeys(vector1 map[string]float64, vector2 map[string]float64) []string {
+	if len(vector2) < len(vector1) {
+		vector1, vector2 = vector2, vector1
+	}
+	keys := make([]string, 0, len(vector1))
+	for key := range vector1 {
+		if _, exists := vector2[key]; exists {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// 皮尔逊相关系数：在共同维度上计算，并按共同维度数占最大邻居数的比例加权
+func pearsonSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	common := commonKeys(vector1, vector2)
+	correlation := pearsonCorrelation(vector1, vector2, common)
+	if context.MaxNeighbors <= 0 {
+		return correlation
+	}
+	return correlation * float64(len(common)) / float64(context.MaxNeighbors)
+}
+
+// 收缩的皮尔逊相关系数：相关系数乘以 n/(n+收缩系数)，n 为共同维度数
+func shrunkSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	common := commonKeys(vector1, vector2)
+	n := float64(len(common))
+	return pearsonCorrelation(vector1, vector2, common) * n / (n + math.Max(context.Shrinkage, 0))
+}
+
+// 共同维度上的皮尔逊相关系数
+func pearsonCorrelation(vector1 map[string]float64, vector2 map[string]float64, common []string) float64 {
+	if len(common) == 0 {
+		return 0.0
+	}
+
+	var sum1, sum2 float64
+	for _, key := range common {
+		sum1 += vector1[key]
+		sum2 += vector2[key]
+	}
+	mean1 := sum1 / float64(len(common))
+	mean2 := sum2 / float64(len(common))
+
+	var numerator, denominator1, denominator2 float64
+	for _, key := range common {
+		diff1 := vector1[key] - mean1
+		diff2 := vector2[key] - mean2
+		numerator += diff1 * diff2
+		denominator1 += diff1 * diff1
+		denominator2 += diff2 * diff2
+	}
+	if denominator1 == 0 || denominator2 == 0 {
+		return 0.0
+	}
+	return numerator / math.Sqrt(denominator1*denominator2)
+}
+
+// 余弦相似度：在共同维度上计算点积和模
+func cosineSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	var dotProduct, norm1, norm2 float64
+	for _, key := range commonKeys(vector1, vector2) {
+		dotProduct += vector1[key] * vector2[key]
+		norm1 += vector1[key] * vector1[key]
+		norm2 += vector2[key] * vector2[key]
+	}
+	if norm1 == 0 || norm2 == 0 {
+		return 0.0
+	}
+	return dotProduct / math.Sqrt(norm1*norm2)
+}
+
+// 调整余弦相似度：评分减去所在维度的均值后计算余弦相似度，
+// 计算物品相似度时即减去用户的平均评分，消除用户评分尺度的差异
+func adjustedCosineSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	var dotProduct, norm1, norm2 float64
+	for _, key := range commonKeys(vector1, vector2) {
+		mean := context.DimensionMean(key)
+		diff1 := vector1[key] - mean
+		diff2 := vector2[key] - mean
+		dotProduct += diff1 * diff2
+		norm1 += diff1 * diff1
+		norm2 += diff2 * diff2
+	}
+	if norm1 == 0 || norm2 == 0 {
+		return 0.0
+	}
+	return dotProduct / math.Sqrt(norm1*norm2)
+}
+
+// 杰卡德系数：共同评分维度数除以评分维度的并集大小
+func jaccardSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	common := len(commonKeys(vector1, vector2))
+	union := len(vector1) + len(vector2) - common
+	if union == 0 {
+		return 0.0
+	}
+	return float64(common) / float64(union)
+}
+
+// 谷本系数：点积除以两个向量模的平方和减去点积
+func tanimotoSimilarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	var dotProduct, norm1, norm2 float64
+	for _, key := range commonKeys(vector1, vector2) {
+		dotProduct += vector1[key] * vector2[key]
+	}
+	for _, value := range vector1 {
+		norm1 += value * value
+	}
+	for _, value := range vector2 {
+		norm2 += value * value
+	}
+	denominator := norm1 + norm2 - dotProduct
+	if denominator == 0 {
+		return 0.0
+	}
+	return dotProduct / denominator
+}
+
+// BM25加权的余弦相似度：评分按BM25饱和并乘以维度的逆文档频率，在完整向量上计算余弦相似度
+func bm25Similarity(vector1 map[string]float64, vector2 map[string]float64, context *SimilarityContext) float64 {
+	weighted1 := bm25Weights(vector1, context)
+	weighted2 := bm25Weights(vector2, context)
+
+	var dotProduct, norm1, norm2 float64
+	for key, value := range weighted1 {
+		norm1 += value * value
+		dotProduct += value * weighted2[key]
+	}
+	for _, value := range weighted2 {
+		norm2 += value * value
+	}
+	if norm1 == 0 || norm2 == 0 {
+		return 0.0
+	}
+	return dotProduct / math.Sqrt(norm1*norm2)
+}
+
+// BM25权重，负评分保留符号
+func bm25Weights(vector map[string]float64, context *SimilarityContext) map[string]float64 {
+	lengthNorm := context.BM25K1 * (1 - context.BM25B + context.BM25B*float64(len(vector))/context.AverageLength())
+
+	weights := make(map[string]float64, len(vector))
+	for key, value := range vector {
+		magnitude := math.Abs(value)
+		if magnitude == 0 {
+			continue
+		}
+		saturated := magnitude * (context.BM25K1 + 1) / (magnitude + lengthNorm)
+		weights[key] = math.Copysign(saturated*context.DimensionIDF(key), value)
+	}
+	return weights
+}
+
+// 评分的均值与标准差
+func meanAndStd(ratings map[string]float64) (float64, float64) {
+	if len(ratings) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, rating := range ratings {
+		sum += rating
+	}
+	mean := sum / float64(len(ratings))
+
+	var variance float64
+	for _, rating := range ratings {
+		variance += (rating - mean) * (rating - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(ratings)))
+}
+
+// 按归一化方法把邻居的评分换算到目标用户的评分尺度，neighbor 与 target 为双方评分的均值与标准差
+func normalizeRating(method string, rating float64, neighbor [2]float64, target [2]float64) float64 {
+	switch method {
+	case NormalizationMeanCentering:
+		return target[0] + rating - neighbor[0]
+	case NormalizationZScore:
+		if neighbor[1] == 0 {
+			return target[0]
+		}
+		return target[0] + target[1]*(rating-neighbor[0])/neighbor[1]
+	default:
+		return rating
+	}
+}
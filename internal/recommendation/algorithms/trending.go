@@ -0,0 +1,424 @@
+package algorithms
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 热度统计窗口
+type TrendingWindow string
+
+const (
+	TrendingWindowDecayed TrendingWindow = "decayed" // 指数时间衰减
+	TrendingWindowHour    TrendingWindow = "1h"      // 最近1小时
+	TrendingWindowDay     TrendingWindow = "24h"     // 最近24小时
+	TrendingWindowWeek    TrendingWindow = "7d"      // 最近7天
+	TrendingWindowRising  TrendingWindow = "rising"  // 热度上升最快
+)
+
+// 滑动窗口分桶：1小时窗口按5分钟分桶，24小时与7天窗口按小时分桶
+const (
+	fineBucketWidth   = 5 * time.Minute
+	fineBucketCount   = 12
+	coarseBucketWidth = time.Hour
+	coarseBucketCount = 168
+)
+
+// 时间衰减的趋势热度算法：按物品和类别维护指数衰减计数与滑动窗口计数，
+// 通过最近1小时与24小时基线的速率比识别热度上升的物品
+type TrendingEngine struct {
+	mu            sync.RWMutex
+	items         map[string]*trendCounter       // 物品计数
+	categories    map[string]*trendCounter       // 类别计数
+	itemCategory  map[string]string              // 物品所属类别
+	userItems     map[string]map[string]struct{} // 用户接触过的物品
+	referenceTime time.Time                      // 衰减计数的参考时间
+	maxDecayed    float64                        // 物品衰减计数的最大值
+	log           *logrus.Logger
+	config        *TrendingConfig
+}
+
+// 趋势热度配置
+type TrendingConfig struct {
+	HalfLife       time.Duration // 衰减半衰期
+	RisingRatio    float64       // 判定为上升的最小速率比
+	MinRisingCount float64       // 判定为上升时最近1小时的最小计数
+	Smoothing      float64       // 速率比的平滑项
+}
+
+// 趋势热度结果
+type TrendingItem struct {
+	ItemID   string
+	Category string
+	Score    float64 // 所选窗口的热度
+	Velocity float64 // 最近1小时相对24小时基线的速率比
+}
+
+// 类别热度结果
+type CategoryTrend struct {
+	Category string
+	Score    float64
+}
+
+// 热度计数器
+type trendCounter struct {
+	decayed   float64 // 以参考时间为基准放大的衰减计数
+	lastEvent time.Time
+	fine      slidingWindow
+	coarse    slidingWindow
+}
+
+// 分桶滑动窗口
+type slidingWindow struct {
+	width  time.Duration
+	counts []float64
+	latest int64 // 最新分桶序号
+}
+
+// 创建新的趋势热度引擎
+func NewTrendingEngine(log *logrus.Logger) *TrendingEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &TrendingConfig{
+		HalfLife:       24 * time.Hour,
+		RisingRatio:    2.0,
+		MinRisingCount: 3,
+		Smoothing:      1.0,
+	}
+
+	return &TrendingEngine{
+		items:        make(map[string]*trendCounter),
+		categories:   make(map[string]*trendCounter),
+		itemCategory: make(map[string]string),
+		userItems:    make(map[string]map[string]struct{}),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 记录物品事件，weight 为行为权重，category 为空时沿用已知类别
+func (t *TrendingEngine) AddEvent(userID string, itemID string, category string, weight float64, timestamp time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	if t.referenceTime.IsZero() {
+		t.referenceTime = timestamp
+	}
+	if category != "" {
+		t.itemCategory[itemID] = category
+	}
+	category = t.itemCategory[itemID]
+
+	t.rebase(timestamp)
+	scaled := weight * math.Exp(t.decayRate()*timestamp.Sub(t.referenceTime).Seconds())
+
+	item := counterFor(t.items, itemID)
+	item.add(timestamp, weight, scaled)
+	t.maxDecayed = math.Max(t.maxDecayed, item.decayed)
+	if category != "" {
+		counterFor(t.categories, category).add(timestamp, weight, scaled)
+	}
+
+	if userID != "" {
+		if t.userItems[userID] == nil {
+			t.userItems[userID] = make(map[string]struct{})
+		}
+		t.userItems[userID][itemID] = struct{}{}
+	}
+}
+
+// 设置物品类别
+func (t *TrendingEngine) SetItemCategory(itemID string, category string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.itemCategory[itemID] = category
+}
+
+// 获取窗口内的热门物品，category 为空时不限类别
+func (t *TrendingEngine) Trending(window TrendingWindow, category string, topN int, now time.Time) []TrendingItem {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.rank(window, category, nil, topN, now)
+}
+
+// 获取热度上升最快的物品
+func (t *TrendingEngine) Rising(category string, topN int, now time.Time) []TrendingItem {
+	return t.Trending(TrendingWindowRising, category, topN, now)
+}
+
+// 生成推荐，排除用户接触过的物品
+func (t *TrendingEngine) Recommend(userID string, window TrendingWindow, category string, topN int, now time.Time) []TrendingItem {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.rank(window, category, t.userItems[userID], topN, now)
+}
+
+// 获取窗口内的热门类别
+func (t *TrendingEngine) CategoryTrending(window TrendingWindow, topN int, now time.Time) []CategoryTrend {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	trends := make([]CategoryTrend, 0, len(t.categories))
+	for category, c := range t.categories {
+		if score := t.windowScore(c, window, now); score > 0 {
+			trends = append(trends, CategoryTrend{Category: category, Score: score})
+		}
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Score != trends[j].Score {
+			return trends[i].Score > trends[j].Score
+		}
+		return trends[i].Category < trends[j].Category
+	})
+	if len(trends) > topN {
+		trends = trends[:topN]
+	}
+	return trends
+}
+
+// 归一化的衰减热度得分，取值[0,1]
+func (t *TrendingEngine) PopularityScore(itemID string) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	c, exists := t.items[itemID]
+	if !exists {
+		return 0
+	}
+	// 所有物品按同一参考时间放大，与最大值之比即当前衰减计数之比
+	if t.maxDecayed == 0 {
+		return 0
+	}
+	return c.decayed / t.maxDecayed
+}
+
+// 时效性得分：距最近一次事件按半衰期衰减，取值[0,1]
+func (t *TrendingEngine) RecencyScore(itemID string, now time.Time) float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	c, exists := t.items[itemID]
+	if !exists {
+		return 0
+	}
+	age := now.Sub(c.lastEvent).Seconds()
+	if age < 0 {
+		age = 0
+	}
+	return math.Exp(-t.decayRate() * age)
+}
+
+// 获取物品的热度详情
+func (t *TrendingEngine) ItemTrend(itemID string, now time.Time) (TrendingItem, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	c, exists := t.items[itemID]
+	if !exists {
+		return TrendingItem{}, false
+	}
+	return TrendingItem{
+		ItemID:   itemID,
+		Category: t.itemCategory[itemID],
+		Score:    t.windowScore(c, TrendingWindowDecayed, now),
+		Velocity: t.velocity(c, now),
+	}, true
+}
+
+// 设置配置
+func (t *TrendingEngine) SetConfig(config *TrendingConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 半衰期变化后按新衰减率重新放大计数会失真，保留当前衰减值作为新的起点
+	if config.HalfLife != t.config.HalfLife && !t.referenceTime.IsZero() {
+		t.rebaseTo(time.Now())
+	}
+	t.config = config
+	t.log.Info("更新趋势热度配置")
+}
+
+// 获取配置
+func (t *TrendingEngine) GetConfig() *TrendingConfig {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.config
+}
+
+// 获取算法统计信息
+func (t *TrendingEngine) GetStats() map[string]interface{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return map[string]interface{}{
+		"item_count":     len(t.items),
+		"category_count": len(t.categories),
+		"user_count":     len(t.userItems),
+		"half_life":      t.config.HalfLife.String(),
+	}
+}
+
+// 排序物品，调用方需持有锁
+func (t *TrendingEngine) rank(window TrendingWindow, category string, exclude map[string]struct{}, topN int, now time.Time) []TrendingItem {
+	results := make([]TrendingItem, 0)
+	for itemID, c := range t.items {
+		if _, seen := exclude[itemID]; seen {
+			continue
+		}
+		if category != "" && t.itemCategory[itemID] != category {
+			continue
+		}
+
+		velocity := t.velocity(c, now)
+		score := t.windowScore(c, window, now)
+		if window == TrendingWindowRising {
+			if c.fine.sum(now, time.Hour) < t.config.MinRisingCount || velocity < t.config.RisingRatio {
+				continue
+			}
+		}
+		if score <= 0 {
+			continue
+		}
+
+		results = append(results, TrendingItem{
+			ItemID:   itemID,
+			Category: t.itemCategory[itemID],
+			Score:    score,
+			Velocity: velocity,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ItemID < results[j].ItemID
+	})
+	if len(results) > topN {
+		results = results[:topN]
+	}
+	return results
+}
+
+// 计数器在窗口内的热度
+func (t *TrendingEngine) windowScore(c *trendCounter, window TrendingWindow, now time.Time) float64 {
+	switch window {
+	case TrendingWindowHour:
+		return c.fine.sum(now, time.Hour)
+	case TrendingWindowDay:
+		return c.coarse.sum(now, 24*time.Hour)
+	case TrendingWindowWeek:
+		return c.coarse.sum(now, 7*24*time.Hour)
+	case TrendingWindowRising:
+		return t.velocity(c, now)
+	default:
+		return c.decayed * math.Exp(-t.decayRate()*now.Sub(t.referenceTime).Seconds())
+	}
+}
+
+// 最近1小时速率相对前23小时每小时平均速率的比值
+func (t *TrendingEngine) velocity(c *trendCounter, now time.Time) float64 {
+	lastHour := c.fine.sum(now, time.Hour)
+	baseline := math.Max(c.coarse.sum(now, 24*time.Hour)-lastHour, 0) / 23
+	return (lastHour + t.config.Smoothing) / (baseline + t.config.Smoothing)
+}
+
+// 衰减率
+func (t *TrendingEngine) decayRate() float64 {
+	return math.Ln2 / t.config.HalfLife.Seconds()
+}
+
+// 放大系数过大时把参考时间前移，避免浮点溢出
+func (t *TrendingEngine) rebase(now time.Time) {
+	if t.decayRate()*now.Sub(t.referenceTime).Seconds() > 50 {
+		t.rebaseTo(now)
+	}
+}
+
+// 把衰减计数的参考时间移动到指定时间
+func (t *TrendingEngine) rebaseTo(now time.Time) {
+	factor := math.Exp(-t.decayRate() * now.Sub(t.referenceTime).Seconds())
+	for _, c := range t.items {
+		c.decayed *= factor
+	}
+	for _, c := range t.categories {
+		c.decayed *= factor
+	}
+	t.maxDecayed *= factor
+	t.referenceTime = now
+}
+
+// 获取或创建计数器
+func counterFor(counters map[string]*trendCounter, key string) *trendCounter {
+	c, exists := counters[key]
+	if !exists {
+		c = &trendCounter{
+			fine:   slidingWindow{width: fineBucketWidth},
+			coarse: slidingWindow{width: coarseBucketWidth},
+		}
+		counters[key] = c
+	}
+	return c
+}
+
+// 累加计数
+func (c *trendCounter) add(timestamp time.Time, weight float64, scaled float64) {
+	c.decayed += scaled
+	if timestamp.After(c.lastEvent) {
+		c.lastEvent = timestamp
+	}
+	c.fine.add(timestamp, weight, fineBucketCount)
+	c.coarse.add(timestamp, weight, coarseBucketCount)
+}
+
+// 写入分桶，早于窗口范围的事件只计入衰减计数
+func (w *slidingWindow) add(timestamp time.Time, value float64, size int) {
+	slot := timestamp.UnixNano() / int64(w.width)
+	if w.counts == nil {
+		w.counts = make([]float64, size)
+		w.latest = slot
+	}
+
+	if slot > w.latest {
+		// 清空新旧最新分桶之间已过期的分桶
+		for s := w.latest + 1; s <= slot && s <= w.latest+int64(len(w.counts)); s++ {
+			w.counts[s%int64(len(w.counts))] = 0
+		}
+		w.latest = slot
+	}
+	if slot <= w.latest-int64(len(w.counts)) {
+		return
+	}
+	w.counts[slot%int64(len(w.counts))] += value
+}
+
+// 最近一段时间内的计数之和
+func (w *slidingWindow) sum(now time.Time, span time.Duration) float64 {
+	if w.counts == nil {
+		return 0
+	}
+
+	nowSlot := now.UnixNano() / int64(w.width)
+	buckets := min(int64(span/w.width), int64(len(w.counts)))
+	var total float64
+	for s := nowSlot - buckets + 1; s <= nowSlot; s++ {
+		if s > w.latest || s <= w.latest-int64(len(w.counts)) {
+			continue
+		}
+		total += w.counts[s%int64(len(w.counts))]
+	}
+	return total
+}
@@ -0,0 +1,739 @@
+package algorithms
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/guanguoyintao/luban/internal/infra/ann"
+)
+
+// 双塔神经网络推荐算法：用户塔与物品塔分别将用户、物品映射到同一向量空间，
+// 以向量内积衡量偏好，使用采样Softmax训练，纯CPU实现
+type TwoTowerEngine struct {
+	mu           sync.RWMutex
+	interactions map[string]map[string]float64 // 用户行为历史
+	items        map[string]twoTowerItem       // 物品类别与稠密特征
+	model        *twoTowerModel                // 最近一次训练的模型
+	itemVectors  map[string][]float64          // 物品塔输出的物品向量
+	trainStats   map[string]interface{}
+	log          *logrus.Logger
+	config       *TwoTowerConfig
+}
+
+// 双塔模型配置
+type TwoTowerConfig struct {
+	EmbeddingDim    int     // ID与类别嵌入维度
+	HiddenDim       int     // 隐藏层维度
+	OutputDim       int     // 输出向量维度
+	FeatureDim      int     // 物品稠密特征维度，为0时由第一个物品确定
+	Epochs          int     // 训练轮数
+	LearningRate    float64 // 学习率
+	Regularization  float64 // L2正则化系数
+	NegativeSamples int     // 每个正样本采样的负样本数
+	Temperature     float64 // Softmax温度
+	InitStdDev      float64 // 嵌入初始化标准差
+	Seed            int64   // 随机种子
+}
+
+// 双塔模型中的物品
+type twoTowerItem struct {
+	category string
+	features []float64
+}
+
+// 模型参数
+type twoTowerModel struct {
+	userEmbeddings     map[string][]float64 // 用户ID嵌入
+	historyEmbeddings  map[string][]float64 // 用户塔中行为历史物品的嵌入
+	itemEmbeddings     map[string][]float64 // 物品塔中物品ID嵌入
+	categoryEmbeddings map[string][]float64 // 物品类别嵌入
+	userTower          *tower
+	itemTower          *tower
+	featureDim         int
+}
+
+// 两层全连接网络：ReLU隐藏层 + 线性输出层，输出做L2归一化
+type tower struct {
+	hidden *denseLayer
+	output *denseLayer
+}
+
+// 全连接层
+type denseLayer struct {
+	weights [][]float64
+	bias    []float64
+}
+
+// 单次前向传播的中间结果，反向传播时使用
+type towerPass struct {
+	input     []float64
+	hidden    []float64
+	output    []float64
+	embedding []float64
+	norm      float64
+}
+
+// 创建新的双塔模型引擎
+func NewTwoTowerEngine(log *logrus.Logger) *TwoTowerEngine {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	config := &TwoTowerConfig{
+		EmbeddingDim:    16,
+		HiddenDim:       32,
+		OutputDim:       16,
+		Epochs:          20,
+		LearningRate:    0.02,
+		Regularization:  0.0001,
+		NegativeSamples: 5,
+		Temperature:     0.2,
+		InitStdDev:      0.1,
+		Seed:            42,
+	}
+
+	return &TwoTowerEngine{
+		interactions: make(map[string]map[string]float64),
+		items:        make(map[string]twoTowerItem),
+		itemVectors:  make(map[string][]float64),
+		trainStats:   make(map[string]interface{}),
+		log:          log,
+		config:       config,
+	}
+}
+
+// 添加或更新物品特征，特征通常来自 dataprocessing.ProcessedItemData
+func (t *TwoTowerEngine) AddItem(itemID string, category string, features []float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.items[itemID] = twoTowerItem{
+		category: category,
+		features: append([]float64(nil), features...),
+	}
+}
+
+// 添加用户行为
+func (t *TwoTowerEngine) AddInteraction(userID string, itemID string, value float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.interactions[userID] == nil {
+		t.interactions[userID] = make(map[string]float64)
+	}
+	t.interactions[userID][itemID] += value
+	if _, exists := t.items[itemID]; !exists {
+		// 没有特征的物品仅使用ID嵌入
+		t.items[itemID] = twoTowerItem{}
+	}
+}
+
+// 训练模型，在上一次训练的参数上继续训练
+func (t *TwoTowerEngine) Train() {
+	startTime := time.Now()
+
+	t.mu.RLock()
+	config := *t.config
+	interactions := make(map[string]map[string]float64, len(t.interactions))
+	for userID, items := range t.interactions {
+		copied := make(map[string]float64, len(items))
+		for itemID, value := range items {
+			copied[itemID] = value
+		}
+		interactions[userID] = copied
+	}
+	items := make(map[string]twoTowerItem, len(t.items))
+	for itemID, item := range t.items {
+		items[itemID] = item
+	}
+	var model *twoTowerModel
+	if t.model != nil {
+		model = t.model.clone()
+	}
+	t.mu.RUnlock()
+
+	if len(interactions) == 0 || len(items) < 2 {
+		return
+	}
+
+	rng := rand.New(rand.NewSource(config.Seed))
+	if model == nil {
+		featureDim := config.FeatureDim
+		if featureDim == 0 {
+			for _, itemID := range sortedItemIDs(items) {
+				if len(items[itemID].features) > 0 {
+					featureDim = len(items[itemID].features)
+					break
+				}
+			}
+		}
+		model = newTwoTowerModel(rng, &config, featureDim)
+	}
+
+	itemIDs := sortedItemIDs(items)
+	samples := make([]bprSample, 0)
+	for _, userID := range sortedKeys(interactions) {
+		for itemID := range interactions[userID] {
+			samples = append(samples, bprSample{userID: userID, itemID: itemID})
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].userID != samples[j].userID {
+			return samples[i].userID < samples[j].userID
+		}
+		return samples[i].itemID < samples[j].itemID
+	})
+
+	var loss float64
+	for epoch := 0; epoch < config.Epochs; epoch++ {
+		rng.Shuffle(len(samples), func(i, j int) {
+			samples[i], samples[j] = samples[j], samples[i]
+		})
+
+		loss = 0
+		for _, sample := range samples {
+			loss += model.trainStep(rng, sample, interactions[sample.userID], items, itemIDs, &config)
+		}
+		if len(samples) > 0 {
+			loss /= float64(len(samples))
+		}
+	}
+
+	// 预计算全部物品向量
+	itemVectors := make(map[string][]float64, len(items))
+	for itemID, item := range items {
+		itemVectors[itemID] = model.itemPass(rng, itemID, item, &config).embedding
+	}
+
+	stats := map[string]interface{}{
+		"epochs":           config.Epochs,
+		"training_samples": len(samples),
+		"training_loss":    loss,
+		"duration_ms":      time.Since(startTime).Milliseconds(),
+		"trained_at":       time.Now(),
+	}
+
+	t.mu.Lock()
+	if t.config.EmbeddingDim != config.EmbeddingDim || t.config.HiddenDim != config.HiddenDim || t.config.OutputDim != config.OutputDim {
+		// 训练期间网络结构已变更，丢弃本次结果
+		t.mu.Unlock()
+		t.log.Warn("训练期间配置变更，丢弃双塔模型训练结果")
+		return
+	}
+	t.model = model
+	t.itemVectors = itemVectors
+	t.trainStats = stats
+	t.mu.Unlock()
+
+	t.log.WithFields(logrus.Fields(stats)).Info("双塔模型训练完成")
+}
+
+// 生成推荐
+func (t *TwoTowerEngine) Recommend(userID string, topN int) []Recommendation {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	userVector, ok := t.userVector(userID)
+	if !ok {
+		return []Recommendation{}
+	}
+	history := t.interactions[userID]
+
+	recommendations := make([]Recommendation, 0, len(t.itemVectors))
+	for itemID, itemVector := range t.itemVectors {
+		if _, seen := history[itemID]; seen {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{
+			ItemID: itemID,
+			Score:  dot(userVector, itemVector),
+		})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+
+	if len(recommendations) > topN {
+		recommendations = recommendations[:topN]
+	}
+	return recommendations
+}
+
+// 获取用户向量，用户没有ID嵌入也没有已知行为时返回false
+func (t *TwoTowerEngine) UserVector(userID string) ([]float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.userVector(userID)
+}
+
+// 预测用户对物品的偏好得分（余弦相似度）
+func (t *TwoTowerEngine) Predict(userID string, itemID string) (float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	itemVector, exists := t.itemVectors[itemID]
+	if !exists {
+		return 0, false
+	}
+	userVector, ok := t.userVector(userID)
+	if !ok {
+		return 0, false
+	}
+	return dot(userVector, itemVector), true
+}
+
+// 获取与物品向量最相似的物品
+func (t *TwoTowerEngine) SimilarItems(itemID string, topN int) []SimilarItem {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	target, exists := t.itemVectors[itemID]
+	if !exists {
+		return []SimilarItem{}
+	}
+
+	similar := make([]SimilarItem, 0, len(t.itemVectors))
+	for otherID, vector := range t.itemVectors {
+		if otherID == itemID {
+			continue
+		}
+		similar = append(similar, SimilarItem{ItemID: otherID, Similarity: dot(target, vector)})
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	if len(similar) > topN {
+		similar = similar[:topN]
+	}
+	return similar
+}
+
+// 获取物品向量
+func (t *TwoTowerEngine) ItemEmbeddings() map[string][]float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	embeddings := make(map[string][]float64, len(t.itemVectors))
+	for itemID, vector := range t.itemVectors {
+		embeddings[itemID] = append([]float64(nil), vector...)
+	}
+	return embeddings
+}
+
+// 导出物品向量到向量索引，供向量召回使用
+func (t *TwoTowerEngine) ExportEmbeddings(index ann.Index) (int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	exported := 0
+	for _, itemID := range sortedVectorIDs(t.itemVectors) {
+		if err := index.Upsert(itemID, t.itemVectors[itemID], t.items[itemID].category); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+	return exported, nil
+}
+
+// 获取用户行为历史
+func (t *TwoTowerEngine) GetUserInteractions(userID string) map[string]float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	interactions := make(map[string]float64, len(t.interactions[userID]))
+	for itemID, value := range t.interactions[userID] {
+		interactions[itemID] = value
+	}
+	return interactions
+}
+
+// 设置配置，网络结构变化后需要重新训练
+func (t *TwoTowerEngine) SetConfig(config *TwoTowerConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if config.EmbeddingDim != t.config.EmbeddingDim || config.HiddenDim != t.config.HiddenDim ||
+		config.OutputDim != t.config.OutputDim || config.FeatureDim != t.config.FeatureDim {
+		t.model = nil
+		t.itemVectors = make(map[string][]float64)
+	}
+	t.config = config
+	t.log.Info("更新双塔模型配置")
+}
+
+// 获取配置
+func (t *TwoTowerEngine) GetConfig() *TwoTowerConfig {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.config
+}
+
+// 获取算法统计信息
+func (t *TwoTowerEngine) GetStats() map[string]interface{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	interactionCount := 0
+	for _, items := range t.interactions {
+		interactionCount += len(items)
+	}
+
+	stats := map[string]interface{}{
+		"user_count":        len(t.interactions),
+		"item_count":        len(t.items),
+		"interaction_count": interactionCount,
+		"embedded_items":    len(t.itemVectors),
+	}
+	for key, value := range t.trainStats {
+		stats[key] = value
+	}
+	return stats
+}
+
+// 计算用户向量，调用方需持有锁
+func (t *TwoTowerEngine) userVector(userID string) ([]float64, bool) {
+	if t.model == nil {
+		return nil, false
+	}
+	history := t.interactions[userID]
+	if _, known := t.model.userEmbeddings[userID]; !known && len(history) == 0 {
+		return nil, false
+	}
+	return t.model.userPass(userID, history, "").embedding, true
+}
+
+// 初始化模型参数
+func newTwoTowerModel(rng *rand.Rand, config *TwoTowerConfig, featureDim int) *twoTowerModel {
+	return &twoTowerModel{
+		userEmbeddings:     make(map[string][]float64),
+		historyEmbeddings:  make(map[string][]float64),
+		itemEmbeddings:     make(map[string][]float64),
+		categoryEmbeddings: make(map[string][]float64),
+		userTower:          newTower(rng, 2*config.EmbeddingDim, config.HiddenDim, config.OutputDim),
+		itemTower:          newTower(rng, 2*config.EmbeddingDim+featureDim, config.HiddenDim, config.OutputDim),
+		featureDim:         featureDim,
+	}
+}
+
+// 单个样本的训练：对正样本与采样负样本计算Softmax交叉熵并反向传播，返回损失
+func (m *twoTowerModel) trainStep(rng *rand.Rand, sample bprSample, history map[string]float64, items map[string]twoTowerItem, itemIDs []string, config *TwoTowerConfig) float64 {
+	m.ensureUser(rng, sample.userID, history, config)
+
+	candidates := []string{sample.itemID}
+	for n := 0; n < config.NegativeSamples; n++ {
+		negative, ok := sampleNegative(rng, itemIDs, history)
+		if !ok {
+			break
+		}
+		candidates = append(candidates, negative)
+	}
+	if len(candidates) < 2 {
+		return 0
+	}
+
+	// 正样本不计入用户历史，避免标签泄漏
+	user := m.userPass(sample.userID, history, sample.itemID)
+	passes := make([]*towerPass, len(candidates))
+	logits := make([]float64, len(candidates))
+	for k, itemID := range candidates {
+		passes[k] = m.itemPass(rng, itemID, items[itemID], config)
+		logits[k] = dot(user.embedding, passes[k].embedding) / config.Temperature
+	}
+	probabilities := softmax(logits)
+
+	gradUser := make([]float64, len(user.embedding))
+	for k, itemID := range candidates {
+		label := 0.0
+		if k == 0 {
+			label = 1.0
+		}
+		g := (probabilities[k] - label) / config.Temperature
+		for f := range gradUser {
+			gradUser[f] += g * passes[k].embedding[f]
+		}
+
+		gradItem := make([]float64, len(user.embedding))
+		for f := range gradItem {
+			gradItem[f] = g * user.embedding[f]
+		}
+		m.backwardItem(itemID, items[itemID], passes[k], gradItem, config)
+	}
+	m.backwardUser(sample.userID, history, sample.itemID, user, gradUser, config)
+
+	return -math.Log(math.Max(probabilities[0], 1e-12))
+}
+
+// 用户塔前向传播，输入为用户ID嵌入与历史物品嵌入均值
+func (m *twoTowerModel) userPass(userID string, history map[string]float64, exclude string) *towerPass {
+	dim := len(m.userTower.hidden.weights[0]) / 2
+	input := make([]float64, 2*dim)
+	if embedding, exists := m.userEmbeddings[userID]; exists {
+		copy(input[:dim], embedding)
+	}
+
+	count := 0
+	for itemID := range history {
+		embedding, exists := m.historyEmbeddings[itemID]
+		if itemID == exclude || !exists {
+			continue
+		}
+		for f, value := range embedding {
+			input[dim+f] += value
+		}
+		count++
+	}
+	if count > 0 {
+		for f := dim; f < 2*dim; f++ {
+			input[f] /= float64(count)
+		}
+	}
+
+	return m.userTower.forward(input)
+}
+
+// 物品塔前向传播，输入为物品ID嵌入、类别嵌入与稠密特征
+func (m *twoTowerModel) itemPass(rng *rand.Rand, itemID string, item twoTowerItem, config *TwoTowerConfig) *towerPass {
+	m.ensureItem(rng, itemID, item, config)
+
+	dim := config.EmbeddingDim
+	input := make([]float64, 2*dim+m.featureDim)
+	copy(input[:dim], m.itemEmbeddings[itemID])
+	copy(input[dim:2*dim], m.categoryEmbeddings[item.category])
+	copy(input[2*dim:], item.features)
+
+	return m.itemTower.forward(input)
+}
+
+// 用户塔反向传播并更新用户与历史物品嵌入
+func (m *twoTowerModel) backwardUser(userID string, history map[string]float64, exclude string, pass *towerPass, gradEmbedding []float64, config *TwoTowerConfig) {
+	gradInput := m.userTower.backward(pass, gradEmbedding, config)
+	dim := config.EmbeddingDim
+
+	updateEmbedding(m.userEmbeddings[userID], gradInput[:dim], config)
+
+	members := make([]string, 0, len(history))
+	for itemID := range history {
+		if _, exists := m.historyEmbeddings[itemID]; exists && itemID != exclude {
+			members = append(members, itemID)
+		}
+	}
+	if len(members) == 0 {
+		return
+	}
+	share := make([]float64, dim)
+	for f := range share {
+		share[f] = gradInput[dim+f] / float64(len(members))
+	}
+	for _, itemID := range members {
+		updateEmbedding(m.historyEmbeddings[itemID], share, config)
+	}
+}
+
+// 物品塔反向传播并更新物品与类别嵌入
+func (m *twoTowerModel) backwardItem(itemID string, item twoTowerItem, pass *towerPass, gradEmbedding []float64, config *TwoTowerConfig) {
+	gradInput := m.itemTower.backward(pass, gradEmbedding, config)
+	dim := config.EmbeddingDim
+
+	updateEmbedding(m.itemEmbeddings[itemID], gradInput[:dim], config)
+	updateEmbedding(m.categoryEmbeddings[item.category], gradInput[dim:2*dim], config)
+}
+
+// 初始化用户及其历史物品的嵌入
+func (m *twoTowerModel) ensureUser(rng *rand.Rand, userID string, history map[string]float64, config *TwoTowerConfig) {
+	if _, exists := m.userEmbeddings[userID]; !exists {
+		m.userEmbeddings[userID] = randomVector(rng, config.EmbeddingDim, config.InitStdDev)
+	}
+	for _, itemID := range sortedVectorKeys(history) {
+		if _, exists := m.historyEmbeddings[itemID]; !exists {
+			m.historyEmbeddings[itemID] = randomVector(rng, config.EmbeddingDim, config.InitStdDev)
+		}
+	}
+}
+
+// 初始化物品及其类别的嵌入
+func (m *twoTowerModel) ensureItem(rng *rand.Rand, itemID string, item twoTowerItem, config *TwoTowerConfig) {
+	if _, exists := m.itemEmbeddings[itemID]; !exists {
+		m.itemEmbeddings[itemID] = randomVector(rng, config.EmbeddingDim, config.InitStdDev)
+	}
+	if _, exists := m.categoryEmbeddings[item.category]; !exists {
+		m.categoryEmbeddings[item.category] = randomVector(rng, config.EmbeddingDim, config.InitStdDev)
+	}
+}
+
+// 深拷贝模型参数
+func (m *twoTowerModel) clone() *twoTowerModel {
+	return &twoTowerModel{
+		userEmbeddings:     copyFactors(m.userEmbeddings),
+		historyEmbeddings:  copyFactors(m.historyEmbeddings),
+		itemEmbeddings:     copyFactors(m.itemEmbeddings),
+		categoryEmbeddings: copyFactors(m.categoryEmbeddings),
+		userTower:          m.userTower.clone(),
+		itemTower:          m.itemTower.clone(),
+		featureDim:         m.featureDim,
+	}
+}
+
+// 创建塔网络，权重使用He初始化
+func newTower(rng *rand.Rand, inputDim int, hiddenDim int, outputDim int) *tower {
+	return &tower{
+		hidden: newDenseLayer(rng, inputDim, hiddenDim),
+		output: newDenseLayer(rng, hiddenDim, outputDim),
+	}
+}
+
+// 前向传播
+func (t *tower) forward(input []float64) *towerPass {
+	hidden := t.hidden.forward(input)
+	for i, value := range hidden {
+		if value < 0 {
+			hidden[i] = 0
+		}
+	}
+	output := t.output.forward(hidden)
+
+	norm := math.Sqrt(dot(output, output))
+	if norm < 1e-12 {
+		norm = 1e-12
+	}
+	embedding := make([]float64, len(output))
+	for i, value := range output {
+		embedding[i] = value / norm
+	}
+
+	return &towerPass{input: input, hidden: hidden, output: output, embedding: embedding, norm: norm}
+}
+
+// 反向传播并以SGD更新参数，返回对输入的梯度
+func (t *tower) backward(pass *towerPass, gradEmbedding []float64, config *TwoTowerConfig) []float64 {
+	// L2归一化的梯度：(g - e·(e·g)) / ||o||
+	projection := dot(pass.embedding, gradEmbedding)
+	gradOutput := make([]float64, len(gradEmbedding))
+	for i := range gradOutput {
+		gradOutput[i] = (gradEmbedding[i] - pass.embedding[i]*projection) / pass.norm
+	}
+
+	gradHidden := t.output.backward(pass.hidden, gradOutput, config)
+	for i, value := range pass.hidden {
+		if value <= 0 {
+			gradHidden[i] = 0
+		}
+	}
+	return t.hidden.backward(pass.input, gradHidden, config)
+}
+
+// 深拷贝塔网络
+func (t *tower) clone() *tower {
+	return &tower{hidden: t.hidden.clone(), output: t.output.clone()}
+}
+
+// 创建全连接层
+func newDenseLayer(rng *rand.Rand, inputDim int, outputDim int) *denseLayer {
+	std := math.Sqrt(2.0 / float64(max(inputDim, 1)))
+	weights := make([][]float64, outputDim)
+	for i := range weights {
+		weights[i] = randomVector(rng, inputDim, std)
+	}
+	return &denseLayer{weights: weights, bias: make([]float64, outputDim)}
+}
+
+// 前向传播
+func (l *denseLayer) forward(input []float64) []float64 {
+	output := make([]float64, len(l.weights))
+	for i, row := range l.weights {
+		output[i] = dot(row, input) + l.bias[i]
+	}
+	return output
+}
+
+// 反向传播，先计算输入梯度再更新参数
+func (l *denseLayer) backward(input []float64, gradOutput []float64, config *TwoTowerConfig) []float64 {
+	gradInput := make([]float64, len(input))
+	for i, row := range l.weights {
+		for j, weight := range row {
+			gradInput[j] += gradOutput[i] * weight
+		}
+	}
+
+	lr := config.LearningRate
+	for i, row := range l.weights {
+		g := gradOutput[i]
+		for j := range row {
+			row[j] -= lr * (g*input[j] + config.Regularization*row[j])
+		}
+		l.bias[i] -= lr * g
+	}
+	return gradInput
+}
+
+// 深拷贝全连接层
+func (l *denseLayer) clone() *denseLayer {
+	weights := make([][]float64, len(l.weights))
+	for i, row := range l.weights {
+		weights[i] = append([]float64(nil), row...)
+	}
+	return &denseLayer{weights: weights, bias: append([]float64(nil), l.bias...)}
+}
+
+// 以SGD更新嵌入向量
+func updateEmbedding(embedding []float64, gradient []float64, config *TwoTowerConfig) {
+	for f := range embedding {
+		embedding[f] -= config.LearningRate * (gradient[f] + config.Regularization*embedding[f])
+	}
+}
+
+// 数值稳定的Softmax
+func softmax(logits []float64) []float64 {
+	maxLogit := math.Inf(-1)
+	for _, logit := range logits {
+		maxLogit = math.Max(maxLogit, logit)
+	}
+
+	var sum float64
+	probabilities := make([]float64, len(logits))
+	for i, logit := range logits {
+		probabilities[i] = math.Exp(logit - maxLogit)
+		sum += probabilities[i]
+	}
+	for i := range probabilities {
+		probabilities[i] /= sum
+	}
+	return probabilities
+}
+
+// 按ID排序的物品列表
+func sortedItemIDs(items map[string]twoTowerItem) []string {
+	ids := make([]string, 0, len(items))
+	for itemID := range items {
+		ids = append(ids, itemID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// 按ID排序的向量键
+func sortedVectorIDs(vectors map[string][]float64) []string {
+	ids := make([]string, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// 按ID排序的行为键
+func sortedVectorKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 关联规则推荐引擎适配器
+type AssociationRuleAdapter struct {
+	engine *algorithms.AssociationRuleEngine
+	log    *logrus.Logger
+}
+
+// 请求上下文中表示当前物品的字段
+var contextItemKeys = []string{"cart_items", "item_ids", "item_id"}
+
+// 创建新的关联规则推荐引擎适配器，并启动定时挖掘
+func NewAssociationRuleAdapter(engine *algorithms.AssociationRuleEngine, log *logrus.Logger) *AssociationRuleAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewAssociationRuleEngine(log)
+	}
+	engine.StartRefresh()
+
+	return &AssociationRuleAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐，当前物品取自 Context 中的 cart_items（购物车）、item_ids 或 item_id（商品详情页）
+func (a *AssociationRuleAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items := contextItems(request.Context)
+	rules := a.engine.Recommend(items, resolveLimit(request.Limit))
+
+	results := make([]RecommendationResult, 0, len(rules))
+	for _, rule := range rules {
+		results = append(results, RecommendationResult{
+			ItemID:     rule.Consequent,
+			Score:      rule.Confidence,
+			Reason:     associationReason(rule),
+			Algorithm:  AlgorithmAssociationRules,
+			Confidence: rule.Confidence,
+			Metadata: map[string]interface{}{
+				"antecedent": strings.Join(rule.Antecedent, ","),
+				"support":    rule.Support,
+				"lift":       rule.Lift,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmAssociationRules,
+		Metadata: map[string]interface{}{
+			"context_items": len(items),
+		},
+	}, nil
+}
+
+// 批量生成推荐
+func (a *AssociationRuleAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释，没有请求上下文时无法确定前件
+func (a *AssociationRuleAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐：关联规则推荐依赖请求中的当前物品", itemID)}
+}
+
+// 更新推荐模型，购买行为按订单或时间窗口归入购买篮，由定时任务重新挖掘
+func (a *AssociationRuleAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, behavior := range update.Behaviors {
+		if behavior.Behavior != datacollection.BehaviorPurchase {
+			continue
+		}
+		orderID, _ := behavior.Context["order_id"].(string)
+		a.engine.AddPurchase(behavior.UserID, orderID, behavior.ItemID, behavior.Timestamp)
+		added++
+	}
+
+	a.log.WithField("purchases", added).Debug("更新关联规则购买篮")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *AssociationRuleAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmAssociationRules}, nil
+}
+
+// 获取算法参数
+func (a *AssociationRuleAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"min_support":       config.MinSupport,
+		"min_support_count": config.MinSupportCount,
+		"min_confidence":    config.MinConfidence,
+		"min_lift":          config.MinLift,
+		"max_itemset_size":  config.MaxItemsetSize,
+		"basket_window":     config.BasketWindow.String(),
+		"max_transactions":  config.MaxTransactions,
+		"refresh_interval":  config.RefreshInterval.String(),
+	}, nil
+}
+
+// 设置算法参数
+func (a *AssociationRuleAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "min_support", "min_confidence":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v > 1 {
+				return invalidParameterError(name, value)
+			}
+			if name == "min_support" {
+				config.MinSupport = v
+			} else {
+				config.MinConfidence = v
+			}
+		case "min_lift":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			config.MinLift = v
+		case "min_support_count", "max_itemset_size", "max_transactions":
+			v, ok := toInt(value)
+			if !ok || v <= 0 || (name == "max_itemset_size" && v < 2) {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "min_support_count":
+				config.MinSupportCount = v
+			case "max_itemset_size":
+				config.MaxItemsetSize = v
+			default:
+				config.MaxTransactions = v
+			}
+		case "basket_window", "refresh_interval":
+			text, _ := value.(string)
+			duration, err := time.ParseDuration(text)
+			if err != nil || duration <= 0 {
+				return invalidParameterError(name, value)
+			}
+			if name == "basket_window" {
+				config.BasketWindow = duration
+			} else {
+				config.RefreshInterval = duration
+			}
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *AssociationRuleAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	return a.engine.GetStats(), nil
+}
+
+// 记录用户反馈，购买反馈计入购买篮
+func (a *AssociationRuleAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+
+	if parsed.Type == FeedbackType(datacollection.BehaviorPurchase) {
+		orderID, _ := parsed.Context["order_id"].(string)
+		a.engine.AddPurchase(userID, orderID, itemID, parsed.Timestamp)
+	}
+	return nil
+}
+
+// 关闭推荐引擎，停止定时挖掘
+func (a *AssociationRuleAdapter) Close() error {
+	a.engine.StopRefresh()
+	a.log.Info("关闭关联规则推荐引擎")
+	return nil
+}
+
+// 从请求上下文中提取当前物品
+func contextItems(requestContext map[string]interface{}) []string {
+	items := make([]string, 0)
+	for _, key := range contextItemKeys {
+		switch v := requestContext[key].(type) {
+		case string:
+			if v != "" {
+				items = append(items, v)
+			}
+		case []string:
+			items = append(items, v...)
+		case []interface{}:
+			for _, value := range v {
+				if itemID, ok := value.(string); ok {
+					items = append(items, itemID)
+				}
+			}
+		}
+	}
+	return items
+}
+
+// 关联规则推荐理由
+func associationReason(rule algorithms.AssociationRule) string {
+	return fmt.Sprintf("购买了 %s 的用户中有%.0f%%也购买了此物品", strings.Join(rule.Antecedent, "、"), rule.Confidence*100)
+}
@@ -0,0 +1,288 @@
+package bandit
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// DefaultFeatureDimension 默认上下文特征维度：1维偏置 + 15维哈希特征
+const DefaultFeatureDimension = 16
+
+// ExplorerConfig 探索层配置
+type ExplorerConfig struct {
+	ImpressionTTL  time.Duration // 展示后超过该时间没有反馈视为奖励0
+	MaxImpressions int           // 等待反馈的展示上限，超出时最早的展示按奖励0结算
+}
+
+// DefaultExplorerConfig 默认探索层配置
+func DefaultExplorerConfig() ExplorerConfig {
+	return ExplorerConfig{
+		ImpressionTTL:  30 * time.Minute,
+		MaxImpressions: 100000,
+	}
+}
+
+// Placement 探索后的结果位置
+type Placement struct {
+	Index      int     // 候选下标，即按引擎得分的排名
+	Explored   bool    // 纯利用时不会出现在结果中，由探索带入
+	Score      float64 // 策略得分
+	Propensity float64 // 策略在该位置选中此候选的概率，0表示无法计算
+}
+
+// Explorer 探索层：在引擎打分之后按探索策略重排结果，记录展示并根据反馈更新策略
+type Explorer struct {
+	mu          sync.Mutex
+	policy      Policy
+	dimension   int
+	impressions map[string]*impression
+	queue       []queuedImpression // 按展示时间排列，已结算或被覆盖的条目出队时跳过
+	head        int
+	lastSweep   time.Time
+	rewarded    int
+	expired     int
+	config      ExplorerConfig
+}
+
+// 等待反馈的展示
+type impression struct {
+	features []float64
+	prior    float64
+	servedAt time.Time
+}
+
+// 展示队列条目
+type queuedImpression struct {
+	key   string
+	shown *impression
+}
+
+// NewExplorer 创建探索层，dimension 为上下文特征维度
+func NewExplorer(policy Policy, dimension int, config ExplorerConfig) *Explorer {
+	if dimension <= 0 {
+		dimension = DefaultFeatureDimension
+	}
+	return &Explorer{
+		policy:      policy,
+		dimension:   dimension,
+		impressions: make(map[string]*impression),
+		config:      config,
+	}
+}
+
+// Policy 获取探索策略
+func (e *Explorer) Policy() Policy {
+	return e.policy
+}
+
+// Rerank 为前 slots 个位置选择候选，candidates 需按引擎得分降序排列
+func (e *Explorer) Rerank(userID string, context map[string]interface{}, candidates []Candidate, slots int) []Placement {
+	now := time.Now()
+	e.expire(now)
+
+	features := ContextFeatures(context, e.dimension)
+	ranked := e.policy.Rank(candidates, features, slots)
+
+	placements := make([]Placement, len(ranked))
+	e.mu.Lock()
+	for i, r := range ranked {
+		placements[i] = Placement{
+			Index:      r.Index,
+			Explored:   r.Index >= slots,
+			Score:      r.Score,
+			Propensity: r.Propensity,
+		}
+		key := impressionKey(userID, candidates[r.Index].ID)
+		shown := &impression{
+			features: features,
+			prior:    candidates[r.Index].Prior,
+			servedAt: now,
+		}
+		e.impressions[key] = shown
+		e.queue = append(e.queue, queuedImpression{key: key, shown: shown})
+	}
+	overflow := e.trim()
+	e.mu.Unlock()
+
+	e.settle(overflow, 0)
+	return placements
+}
+
+// Reward 记录展示物品的反馈，物品没有等待反馈的展示时返回false
+func (e *Explorer) Reward(userID string, itemID string, reward float64) bool {
+	key := impressionKey(userID, itemID)
+
+	e.mu.Lock()
+	shown, exists := e.impressions[key]
+	if exists {
+		delete(e.impressions, key)
+		e.rewarded++
+	}
+	e.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+	e.policy.Update(Observation{ArmID: itemID, Features: shown.features, Prior: shown.prior, Reward: clamp(reward)})
+	return true
+}
+
+// GetStats 获取统计信息
+func (e *Explorer) GetStats() map[string]interface{} {
+	e.mu.Lock()
+	stats := map[string]interface{}{
+		"policy":              string(e.policy.Type()),
+		"pending_impressions": len(e.impressions),
+		"rewarded":            e.rewarded,
+		"expired":             e.expired,
+	}
+	e.mu.Unlock()
+
+	for key, value := range e.policy.GetStats() {
+		stats[key] = value
+	}
+	return stats
+}
+
+// 结算超时的展示，按TTL的十分之一为间隔
+func (e *Explorer) expire(now time.Time) {
+	e.mu.Lock()
+	if now.Sub(e.lastSweep) < e.config.ImpressionTTL/10 {
+		e.mu.Unlock()
+		return
+	}
+	e.lastSweep = now
+
+	expired := make(map[string]*impression)
+	for e.head < len(e.queue) {
+		entry := e.queue[e.head]
+		if e.pending(entry) && now.Sub(entry.shown.servedAt) <= e.config.ImpressionTTL {
+			break
+		}
+		if e.pending(entry) {
+			expired[entry.key] = entry.shown
+			delete(e.impressions, entry.key)
+		}
+		e.pop()
+	}
+	e.mu.Unlock()
+
+	e.settle(expired, 0)
+}
+
+// 超出上限时从队首移除最早的展示，调用方需持有锁
+func (e *Explorer) trim() map[string]*impression {
+	overflow := len(e.impressions) - e.config.MaxImpressions
+	if overflow <= 0 {
+		return nil
+	}
+
+	removed := make(map[string]*impression, overflow)
+	for len(removed) < overflow && e.head < len(e.queue) {
+		entry := e.queue[e.head]
+		if e.pending(entry) {
+			removed[entry.key] = entry.shown
+			delete(e.impressions, entry.key)
+		}
+		e.pop()
+	}
+	return removed
+}
+
+// 队列条目是否仍在等待反馈，调用方需持有锁
+func (e *Explorer) pending(entry queuedImpression) bool {
+	return e.impressions[entry.key] == entry.shown
+}
+
+// 弹出队首，已弹出部分超过一半时压缩队列，调用方需持有锁
+func (e *Explorer) pop() {
+	e.queue[e.head] = queuedImpression{}
+	e.head++
+	if e.head == len(e.queue) {
+		e.queue = e.queue[:0]
+		e.head = 0
+	} else if e.head > len(e.queue)/2 {
+		e.queue = append(e.queue[:0], e.queue[e.head:]...)
+		e.head = 0
+	}
+}
+
+// 以固定奖励结算展示
+func (e *Explorer) settle(impressions map[string]*impression, reward float64) {
+	if len(impressions) == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	e.expired += len(impressions)
+	e.mu.Unlock()
+
+	for key, shown := range impressions {
+		e.policy.Update(Observation{ArmID: itemFromKey(key), Features: shown.features, Prior: shown.prior, Reward: reward})
+	}
+}
+
+// ContextFeatures 将请求上下文哈希为定长特征向量：第0维为偏置，
+// 数值特征按字段名哈希累加数值，布尔特征为真时累加1，字符串特征按"字段=值"哈希累加1
+func ContextFeatures(context map[string]interface{}, dimension int) []float64 {
+	features := make([]float64, dimension)
+	features[0] = 1
+	if dimension == 1 {
+		return features
+	}
+
+	for key, value := range context {
+		switch v := value.(type) {
+		case float64:
+			features[hashBucket(key, dimension)] += v
+		case float32:
+			features[hashBucket(key, dimension)] += float64(v)
+		case int:
+			features[hashBucket(key, dimension)] += float64(v)
+		case int64:
+			features[hashBucket(key, dimension)] += float64(v)
+		case bool:
+			if v {
+				features[hashBucket(key, dimension)]++
+			}
+		case string:
+			features[hashBucket(key+"="+v, dimension)]++
+		}
+	}
+	return features
+}
+
+// 哈希到第1维之后的分桶
+func hashBucket(key string, dimension int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return 1 + int(h.Sum32()%uint32(dimension-1))
+}
+
+// 展示键
+func impressionKey(userID string, itemID string) string {
+	return fmt.Sprintf("%s\x00%s", userID, itemID)
+}
+
+// 从展示键中取出物品ID
+func itemFromKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if key[i] == 0 {
+			return key[i+1:]
+		}
+	}
+	return key
+}
+
+// 奖励截断到[0,1]
+func clamp(reward float64) float64 {
+	if reward < 0 {
+		return 0
+	}
+	if reward > 1 {
+		return 1
+	}
+	return reward
+}
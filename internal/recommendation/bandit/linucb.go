@@ -0,0 +1,142 @@
+package bandit
+
+import (
+	"math"
+	"sync"
+)
+
+// LinUCB：每个物品一个线性模型，用请求上下文特征预测奖励相对先验的残差，
+// 得分为先验 + 预测残差 + 置信半径
+type linUCB struct {
+	mu        sync.RWMutex
+	models    map[string]*linearModel
+	alpha     float64
+	dimension int
+	pulls     float64
+	reward    float64
+}
+
+// 岭回归模型，直接维护 A 的逆矩阵
+type linearModel struct {
+	inverse [][]float64
+	b       []float64
+}
+
+// 策略类型
+func (p *linUCB) Type() PolicyType { return PolicyLinUCB }
+
+// 按置信上界排序
+func (p *linUCB) Rank(candidates []Candidate, features []float64, slots int) []Ranked {
+	x := fitDimension(features, p.dimension)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		model, exists := p.models[candidate.ID]
+		if !exists {
+			// 未观测过的物品 A=I、b=0，置信半径为特征范数
+			scores[i] = candidate.Prior + p.alpha*math.Sqrt(dotProduct(x, x))
+			continue
+		}
+		scores[i] = candidate.Prior + model.predict(x) + p.alpha*math.Sqrt(model.variance(x))
+	}
+	return topRanked(scores, slots)
+}
+
+// 根据反馈更新物品模型
+func (p *linUCB) Update(observation Observation) {
+	x := fitDimension(observation.Features, p.dimension)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	model, exists := p.models[observation.ArmID]
+	if !exists {
+		model = newLinearModel(p.dimension)
+		p.models[observation.ArmID] = model
+	}
+	model.update(x, observation.Reward-observation.Prior)
+	p.pulls++
+	p.reward += observation.Reward
+}
+
+// 获取统计信息
+func (p *linUCB) GetStats() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	averageReward := 0.0
+	if p.pulls > 0 {
+		averageReward = p.reward / p.pulls
+	}
+	return map[string]interface{}{
+		"arm_count":      len(p.models),
+		"total_pulls":    p.pulls,
+		"average_reward": averageReward,
+		"alpha":          p.alpha,
+		"dimension":      p.dimension,
+	}
+}
+
+// 创建线性模型，A 初始化为单位矩阵
+func newLinearModel(dimension int) *linearModel {
+	inverse := make([][]float64, dimension)
+	for i := range inverse {
+		inverse[i] = make([]float64, dimension)
+		inverse[i][i] = 1
+	}
+	return &linearModel{inverse: inverse, b: make([]float64, dimension)}
+}
+
+// 预测值 θ·x，θ = A⁻¹b
+func (m *linearModel) predict(x []float64) float64 {
+	var prediction float64
+	for i, row := range m.inverse {
+		prediction += x[i] * dotProduct(row, m.b)
+	}
+	return prediction
+}
+
+// 预测方差 xᵀA⁻¹x
+func (m *linearModel) variance(x []float64) float64 {
+	var variance float64
+	for i, row := range m.inverse {
+		variance += x[i] * dotProduct(row, x)
+	}
+	return math.Max(variance, 0)
+}
+
+// 加入一个样本，用Sherman-Morrison公式更新逆矩阵
+func (m *linearModel) update(x []float64, target float64) {
+	ax := make([]float64, len(x))
+	for i, row := range m.inverse {
+		ax[i] = dotProduct(row, x)
+	}
+	denominator := 1 + dotProduct(x, ax)
+	for i, row := range m.inverse {
+		for j := range row {
+			row[j] -= ax[i] * ax[j] / denominator
+		}
+	}
+	for i := range m.b {
+		m.b[i] += target * x[i]
+	}
+}
+
+// 向量内积
+func dotProduct(a []float64, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// 截断或补零到指定维度
+func fitDimension(features []float64, dimension int) []float64 {
+	x := make([]float64, dimension)
+	copy(x, features)
+	return x
+}
@@ -0,0 +1,358 @@
+// Package bandit 推荐结果的多臂老虎机探索
+package bandit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// PolicyType 探索策略类型
+type PolicyType string
+
+const (
+	PolicyEpsilonGreedy PolicyType = "epsilon_greedy" // ε-贪心
+	PolicyUCB1          PolicyType = "ucb1"           // 置信上界
+	PolicyThompson      PolicyType = "thompson"       // 汤普森采样
+	PolicyLinUCB        PolicyType = "linucb"         // 基于上下文特征的线性置信上界
+)
+
+var ErrUnsupportedPolicy = errors.New("不支持的探索策略")
+
+// Candidate 待排序的候选物品
+type Candidate struct {
+	ID    string
+	Prior float64 // 引擎得分归一化到[0,1]后作为先验
+}
+
+// Ranked 策略给出的排序结果
+type Ranked struct {
+	Index      int     // 候选下标
+	Score      float64 // 策略得分
+	Propensity float64 // 在已选出前面位置的条件下该位置选中此候选的概率，0表示无法计算
+}
+
+// Observation 一次展示的反馈
+type Observation struct {
+	ArmID    string
+	Features []float64 // 展示时的上下文特征
+	Prior    float64   // 展示时的先验得分
+	Reward   float64   // 奖励，取值[0,1]
+}
+
+// Policy 探索策略
+type Policy interface {
+	// 策略类型
+	Type() PolicyType
+
+	// 为前 slots 个位置选择候选，features 为请求上下文特征
+	Rank(candidates []Candidate, features []float64, slots int) []Ranked
+
+	// 根据反馈更新
+	Update(observation Observation)
+
+	// 获取统计信息
+	GetStats() map[string]interface{}
+}
+
+// Config 探索策略配置
+type Config struct {
+	Policy      PolicyType
+	Epsilon     float64 // ε-贪心的随机探索概率
+	UCBScale    float64 // UCB1置信半径系数
+	Alpha       float64 // LinUCB置信半径系数
+	PriorWeight float64 // 引擎先验相当于的观测次数
+	Dimension   int     // LinUCB特征维度
+	Seed        int64
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig(policy PolicyType) Config {
+	return Config{
+		Policy:      policy,
+		Epsilon:     0.1,
+		UCBScale:    0.5,
+		Alpha:       0.5,
+		PriorWeight: 5,
+		Dimension:   DefaultFeatureDimension,
+		Seed:        42,
+	}
+}
+
+// NewPolicy 根据配置创建探索策略
+func NewPolicy(config Config) (Policy, error) {
+	rng := rand.New(rand.NewSource(config.Seed))
+	arms := &armTable{arms: make(map[string]*armStats), priorWeight: config.PriorWeight}
+
+	switch config.Policy {
+	case PolicyEpsilonGreedy:
+		return &epsilonGreedy{arms: arms, epsilon: config.Epsilon, rng: rng}, nil
+	case PolicyUCB1:
+		return &ucb1{arms: arms, scale: config.UCBScale}, nil
+	case PolicyThompson:
+		return &thompson{arms: arms, rng: rng}, nil
+	case PolicyLinUCB:
+		if config.Dimension <= 0 {
+			return nil, fmt.Errorf("LinUCB特征维度必须大于0: %d", config.Dimension)
+		}
+		return &linUCB{
+			models:    make(map[string]*linearModel),
+			alpha:     config.Alpha,
+			dimension: config.Dimension,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPolicy, config.Policy)
+	}
+}
+
+// 物品的累计反馈
+type armStats struct {
+	pulls  float64
+	reward float64
+}
+
+// 按物品统计反馈，ε-贪心、UCB1、汤普森采样共用
+type armTable struct {
+	mu          sync.RWMutex
+	arms        map[string]*armStats
+	totalPulls  float64
+	priorWeight float64
+}
+
+// 记录反馈
+func (t *armTable) update(observation Observation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	arm, exists := t.arms[observation.ArmID]
+	if !exists {
+		arm = &armStats{}
+		t.arms[observation.ArmID] = arm
+	}
+	arm.pulls++
+	arm.reward += observation.Reward
+	t.totalPulls++
+}
+
+// 获取物品反馈，调用方需持有锁
+func (t *armTable) get(armID string) armStats {
+	if arm, exists := t.arms[armID]; exists {
+		return *arm
+	}
+	return armStats{}
+}
+
+// 融合先验的平均奖励
+func (t *armTable) mean(arm armStats, prior float64) float64 {
+	return (arm.reward + t.priorWeight*prior) / (arm.pulls + t.priorWeight)
+}
+
+// 统计信息
+func (t *armTable) stats() map[string]interface{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var reward float64
+	for _, arm := range t.arms {
+		reward += arm.reward
+	}
+	averageReward := 0.0
+	if t.totalPulls > 0 {
+		averageReward = reward / t.totalPulls
+	}
+	return map[string]interface{}{
+		"arm_count":      len(t.arms),
+		"total_pulls":    t.totalPulls,
+		"average_reward": averageReward,
+	}
+}
+
+// ε-贪心：每个位置以ε的概率从剩余候选中随机选择，否则选择平均奖励最高的候选
+type epsilonGreedy struct {
+	arms    *armTable
+	epsilon float64
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// 策略类型
+func (p *epsilonGreedy) Type() PolicyType { return PolicyEpsilonGreedy }
+
+// 逐个位置选择候选
+func (p *epsilonGreedy) Rank(candidates []Candidate, features []float64, slots int) []Ranked {
+	p.arms.mu.RLock()
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		scores[i] = p.arms.mean(p.arms.get(candidate.ID), candidate.Prior)
+	}
+	p.arms.mu.RUnlock()
+
+	remaining := sortByScore(scores)
+	ranked := make([]Ranked, 0, min(slots, len(candidates)))
+
+	p.rngMu.Lock()
+	defer p.rngMu.Unlock()
+	for len(ranked) < slots && len(remaining) > 0 {
+		pick := 0
+		if p.rng.Float64() < p.epsilon {
+			pick = p.rng.Intn(len(remaining))
+		}
+		// 随机选择以 ε/剩余数 的概率选中任一候选，得分最高的候选另有 1-ε 的概率被选中
+		propensity := p.epsilon / float64(len(remaining))
+		if pick == 0 {
+			propensity += 1 - p.epsilon
+		}
+		index := remaining[pick]
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+		ranked = append(ranked, Ranked{Index: index, Score: scores[index], Propensity: propensity})
+	}
+	return ranked
+}
+
+// 根据反馈更新
+func (p *epsilonGreedy) Update(observation Observation) { p.arms.update(observation) }
+
+// 获取统计信息
+func (p *epsilonGreedy) GetStats() map[string]interface{} {
+	stats := p.arms.stats()
+	stats["epsilon"] = p.epsilon
+	return stats
+}
+
+// UCB1：平均奖励加上随展示次数收缩的置信半径
+type ucb1 struct {
+	arms  *armTable
+	scale float64
+}
+
+// 策略类型
+func (p *ucb1) Type() PolicyType { return PolicyUCB1 }
+
+// 按置信上界排序
+func (p *ucb1) Rank(candidates []Candidate, features []float64, slots int) []Ranked {
+	p.arms.mu.RLock()
+	defer p.arms.mu.RUnlock()
+
+	logTotal := math.Log(p.arms.totalPulls + 1)
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		arm := p.arms.get(candidate.ID)
+		bonus := p.scale * math.Sqrt(2*logTotal/(arm.pulls+p.arms.priorWeight))
+		scores[i] = p.arms.mean(arm, candidate.Prior) + bonus
+	}
+	return topRanked(scores, slots)
+}
+
+// 根据反馈更新
+func (p *ucb1) Update(observation Observation) { p.arms.update(observation) }
+
+// 获取统计信息
+func (p *ucb1) GetStats() map[string]interface{} {
+	stats := p.arms.stats()
+	stats["ucb_scale"] = p.scale
+	return stats
+}
+
+// 汤普森采样：从以先验和反馈为参数的Beta分布中采样
+type thompson struct {
+	arms *armTable
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+}
+
+// 策略类型
+func (p *thompson) Type() PolicyType { return PolicyThompson }
+
+// 按采样值排序
+func (p *thompson) Rank(candidates []Candidate, features []float64, slots int) []Ranked {
+	p.arms.mu.RLock()
+	params := make([][2]float64, len(candidates))
+	for i, candidate := range candidates {
+		arm := p.arms.get(candidate.ID)
+		params[i][0] = 1 + p.arms.priorWeight*candidate.Prior + arm.reward
+		params[i][1] = 1 + p.arms.priorWeight*(1-candidate.Prior) + math.Max(arm.pulls-arm.reward, 0)
+	}
+	p.arms.mu.RUnlock()
+
+	p.rngMu.Lock()
+	scores := make([]float64, len(candidates))
+	for i := range candidates {
+		scores[i] = sampleBeta(p.rng, params[i][0], params[i][1])
+	}
+	p.rngMu.Unlock()
+
+	// 采样排序的选中概率没有解析形式
+	ranked := topRanked(scores, slots)
+	for i := range ranked {
+		ranked[i].Propensity = 0
+	}
+	return ranked
+}
+
+// 根据反馈更新
+func (p *thompson) Update(observation Observation) { p.arms.update(observation) }
+
+// 获取统计信息
+func (p *thompson) GetStats() map[string]interface{} { return p.arms.stats() }
+
+// 按得分降序排列的下标
+func sortByScore(scores []float64) []int {
+	indexes := make([]int, len(scores))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return scores[indexes[i]] > scores[indexes[j]]
+	})
+	return indexes
+}
+
+// 得分最高的 slots 个候选，确定性排序的选中概率为1
+func topRanked(scores []float64, slots int) []Ranked {
+	indexes := sortByScore(scores)
+	if len(indexes) > slots {
+		indexes = indexes[:slots]
+	}
+	ranked := make([]Ranked, len(indexes))
+	for i, index := range indexes {
+		ranked[i] = Ranked{Index: index, Score: scores[index], Propensity: 1}
+	}
+	return ranked
+}
+
+// Beta分布采样
+func sampleBeta(rng *rand.Rand, alpha float64, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// Gamma分布采样（Marsaglia-Tsang方法）
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// BPR推荐引擎适配器
+type BPRAdapter struct {
+	engine    *algorithms.BPREngine
+	retrainer *retrainer
+	log       *logrus.Logger
+}
+
+// 创建新的BPR推荐引擎适配器
+func NewBPRAdapter(engine *algorithms.BPREngine, log *logrus.Logger) *BPRAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewBPREngine(log)
+	}
+
+	return &BPRAdapter{
+		engine:    engine,
+		retrainer: newRetrainer(string(AlgorithmBPR), engine.Train, log),
+		log:       log,
+	}
+}
+
+// 生成推荐
+func (a *BPRAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.Recommend(request.UserID, resolveLimit(request.Limit))
+
+	// BPR得分是未校准的排序分，映射为(0,1)区间作为得分和置信度
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		probability := 1.0 / (1.0 + math.Exp(-rec.Score))
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      probability,
+			Reason:     "您可能更偏好此物品",
+			Algorithm:  AlgorithmBPR,
+			Confidence: probability,
+			Metadata: map[string]interface{}{
+				"ranking_score": rec.Score,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmBPR,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 为候选物品打分，供推荐流水线融合多个算法的得分
+func (a *BPRAdapter) ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scores := a.engine.PredictItems(userID, itemIDs)
+	results := make([]RecommendationResult, 0, len(scores))
+	for _, itemID := range itemIDs {
+		score, exists := scores[itemID]
+		if !exists {
+			continue
+		}
+		probability := 1.0 / (1.0 + math.Exp(-score))
+		results = append(results, RecommendationResult{
+			ItemID:     itemID,
+			Score:      probability,
+			Reason:     "您可能更偏好此物品",
+			Algorithm:  AlgorithmBPR,
+			Confidence: probability,
+		})
+	}
+	return results, nil
+}
+
+// 批量生成推荐
+func (a *BPRAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *BPRAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	score, exists := a.engine.Predict(userID, itemID)
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+
+	return fmt.Sprintf("推荐物品 %s 是因为相比您未接触过的其他物品，您更可能偏好它（排序得分%.2f）", itemID, score), nil
+}
+
+// 更新推荐模型：首次写入交互数据时同步训练，之后按新增交互数或间隔在后台重新训练，
+// 两次训练之间通过折叠更新涉及用户的隐向量
+func (a *BPRAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	users := make(map[string]bool)
+	for _, behavior := range update.Behaviors {
+		if rating := behaviorToRating(behavior); rating > 0 {
+			a.engine.AddInteraction(behavior.UserID, behavior.ItemID, rating)
+			users[behavior.UserID] = true
+			added++
+		}
+	}
+
+	if !a.retrainer.add(added) {
+		for userID := range users {
+			a.engine.FoldInUser(userID)
+		}
+	}
+
+	a.log.WithField("behaviors", added).Debug("更新BPR模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *BPRAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmBPR}, nil
+}
+
+// 获取算法参数
+func (a *BPRAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"factors":          config.Factors,
+		"epochs":           config.Epochs,
+		"learning_rate":    config.LearningRate,
+		"regularization":   config.Regularization,
+		"negative_samples": config.NegativeSamples,
+		"validation_ratio": config.ValidationRatio,
+		"patience":         config.Patience,
+	}, nil
+}
+
+// 设置算法参数
+func (a *BPRAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "factors", "epochs", "negative_samples", "patience":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "factors":
+				config.Factors = v
+			case "epochs":
+				config.Epochs = v
+			case "negative_samples":
+				config.NegativeSamples = v
+			default:
+				config.Patience = v
+			}
+		case "learning_rate":
+			v, ok := toFloat64(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.LearningRate = v
+		case "regularization":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			config.Regularization = v
+		case "validation_ratio":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v >= 1 {
+				return invalidParameterError(name, value)
+			}
+			config.ValidationRatio = v
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	// 隐向量维度变化会清空已训练的隐向量，需要立即重新训练
+	retrain := config.Factors != a.engine.GetConfig().Factors
+	a.engine.SetConfig(&config)
+	if retrain {
+		a.retrainer.retrain()
+	}
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *BPRAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_interaction_count"] = len(a.engine.GetUserInteractions(userID))
+	return stats, nil
+}
+
+// 记录用户反馈，通过折叠增量更新用户隐向量
+func (a *BPRAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	rating, err := feedbackToRating(feedback)
+	if err != nil {
+		return err
+	}
+	if rating <= 0 {
+		return nil
+	}
+
+	a.engine.AddInteraction(userID, itemID, rating)
+	if !a.retrainer.add(1) {
+		a.engine.FoldInUser(userID)
+	}
+	return nil
+}
+
+// 关闭推荐引擎，等待后台训练完成
+func (a *BPRAdapter) Close() error {
+	a.retrainer.wait()
+	a.log.Info("关闭BPR推荐引擎")
+	return nil
+}
@@ -0,0 +1,242 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 冷启动推荐引擎适配器
+type ColdStartAdapter struct {
+	engine *algorithms.ColdStartEngine
+	log    *logrus.Logger
+}
+
+// 冷启动推荐来源对应的推荐理由
+var coldStartReasons = map[string]string{
+	algorithms.ColdStartSourceDemographic: "与您相似的用户喜欢",
+	algorithms.ColdStartSourcePreference:  "符合您的兴趣偏好",
+	algorithms.ColdStartSourcePopularity:  "近期热门",
+	algorithms.ColdStartSourceNewItem:     "新品推荐",
+}
+
+// 创建新的冷启动推荐引擎适配器
+func NewColdStartAdapter(engine *algorithms.ColdStartEngine, log *logrus.Logger) *ColdStartAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewColdStartEngine(nil, nil, log)
+	}
+
+	return &ColdStartAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐
+func (a *ColdStartAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.Recommend(request.UserID, resolveLimit(request.Limit), time.Now())
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: a.toResults(recs),
+		TotalCount:      len(recs),
+		Algorithm:       AlgorithmColdStart,
+		Metadata: map[string]interface{}{
+			"interaction_count": a.engine.InteractionCount(request.UserID),
+			"segments":          a.engine.GetUserSegments(request.UserID),
+		},
+	}, nil
+}
+
+// 判断用户是否仍处于冷启动阶段
+func (a *ColdStartAdapter) IsColdUser(userID string) bool {
+	return !a.engine.IsWarm(userID)
+}
+
+// 按新物品比例为用户挑选需要曝光的新物品
+func (a *ColdStartAdapter) OnboardItems(userID string, limit int) []RecommendationResult {
+	slots := int(math.Round(float64(limit) * a.engine.GetConfig().NewItemRatio))
+	return a.toResults(a.engine.OnboardItems(userID, slots, time.Now()))
+}
+
+// 批量生成推荐
+func (a *ColdStartAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *ColdStartAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	if a.engine.IsNewItem(itemID) {
+		return fmt.Sprintf("推荐物品 %s 是因为它是与您兴趣相关的新品", itemID), nil
+	}
+
+	if segments := a.engine.GetUserSegments(userID); len(segments) > 0 {
+		return fmt.Sprintf("推荐物品 %s 是因为与您同属「%s」的用户喜欢它", itemID, strings.Join(segments, "、")), nil
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为它是近期的热门物品", itemID), nil
+}
+
+// 更新推荐模型，写入用户画像、物品库与用户交互
+func (a *ColdStartAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range update.Users {
+		a.engine.SetUserProfile(user.UserID, user.Demographics, user.Preferences)
+	}
+	now := time.Now()
+	for _, item := range update.Items {
+		a.engine.AddItem(item.ItemID, now)
+	}
+	for _, item := range update.ProcessedItems {
+		a.engine.AddItem(item.ItemID, now)
+	}
+	for _, behavior := range update.Behaviors {
+		a.engine.AddInteraction(behavior.UserID, behavior.ItemID, behaviorToRating(behavior), behavior.Timestamp)
+	}
+
+	a.log.WithFields(logrus.Fields{
+		"users":     len(update.Users),
+		"items":     len(update.Items) + len(update.ProcessedItems),
+		"behaviors": len(update.Behaviors),
+	}).Debug("更新冷启动模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *ColdStartAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmColdStart}, nil
+}
+
+// 获取算法参数
+func (a *ColdStartAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"warm_up_threshold":   config.WarmUpThreshold,
+		"new_item_threshold":  config.NewItemThreshold,
+		"new_item_ratio":      config.NewItemRatio,
+		"new_item_half_life":  config.NewItemHalfLife.String(),
+		"min_segment_users":   config.MinSegmentUsers,
+		"numeric_bucket_size": config.NumericBucketSize,
+		"demographic_weight":  config.DemographicWeight,
+		"preference_weight":   config.PreferenceWeight,
+		"popularity_weight":   config.PopularityWeight,
+	}, nil
+}
+
+// 设置算法参数
+func (a *ColdStartAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "warm_up_threshold", "new_item_threshold", "min_segment_users":
+			v, ok := toInt(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "warm_up_threshold":
+				config.WarmUpThreshold = v
+			case "new_item_threshold":
+				config.NewItemThreshold = v
+			default:
+				config.MinSegmentUsers = v
+			}
+		case "new_item_half_life":
+			text, _ := value.(string)
+			halfLife, err := time.ParseDuration(text)
+			if err != nil || halfLife <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.NewItemHalfLife = halfLife
+		case "new_item_ratio":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v > 1 {
+				return invalidParameterError(name, value)
+			}
+			config.NewItemRatio = v
+		case "numeric_bucket_size", "demographic_weight", "preference_weight", "popularity_weight":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "numeric_bucket_size":
+				config.NumericBucketSize = v
+			case "demographic_weight":
+				config.DemographicWeight = v
+			case "preference_weight":
+				config.PreferenceWeight = v
+			default:
+				config.PopularityWeight = v
+			}
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *ColdStartAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_interaction_count"] = a.engine.InteractionCount(userID)
+	stats["user_is_warm"] = a.engine.IsWarm(userID)
+	return stats, nil
+}
+
+// 记录用户反馈，正反馈计入用户交互
+func (a *ColdStartAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+	if parsed.IsNegative() {
+		return nil
+	}
+
+	a.engine.AddInteraction(userID, itemID, parsed.Rating(), parsed.Timestamp)
+	return nil
+}
+
+// 关闭推荐引擎
+func (a *ColdStartAdapter) Close() error {
+	a.log.Info("关闭冷启动推荐引擎")
+	return nil
+}
+
+// 转换为推荐结果
+func (a *ColdStartAdapter) toResults(recs []algorithms.ColdStartRecommendation) []RecommendationResult {
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     coldStartReasons[rec.Source],
+			Algorithm:  AlgorithmColdStart,
+			Confidence: math.Max(0, math.Min(1, rec.Score)),
+			Metadata: map[string]interface{}{
+				"cold_start_source": rec.Source,
+				"new_item":          rec.Source == algorithms.ColdStartSourceNewItem,
+			},
+		})
+	}
+	return results
+}
@@ -0,0 +1,312 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 协同过滤模式
+const (
+	CollaborativeModeUserBased = "user_based" // 基于用户
+	CollaborativeModeItemBased = "item_based" // 基于物品
+)
+
+// 协同过滤推荐引擎适配器
+type CollaborativeFilteringAdapter struct {
+	mu     sync.RWMutex
+	engine *algorithms.CollaborativeFilteringEngine
+	mode   string
+	log    *logrus.Logger
+}
+
+// 创建新的协同过滤推荐引擎适配器
+func NewCollaborativeFilteringAdapter(engine *algorithms.CollaborativeFilteringEngine, log *logrus.Logger) *CollaborativeFilteringAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewCollaborativeFilteringEngine(log)
+	}
+
+	return &CollaborativeFilteringAdapter{
+		engine: engine,
+		mode:   CollaborativeModeUserBased,
+		log:    log,
+	}
+}
+
+// 生成推荐
+func (a *CollaborativeFilteringAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	mode := a.getMode()
+	if value, ok := request.Parameters["mode"].(string); ok && value != "" {
+		mode = value
+	}
+
+	limit := resolveLimit(request.Limit)
+	var recs []algorithms.Recommendation
+	var reason string
+	switch mode {
+	case CollaborativeModeUserBased:
+		recs = a.engine.UserBasedRecommend(request.UserID, limit)
+		reason = "与您相似的用户也喜欢"
+	case CollaborativeModeItemBased:
+		recs = a.engine.ItemBasedRecommend(request.UserID, limit)
+		reason = "与您喜欢的物品相似"
+	default:
+		return nil, invalidParameterError("mode", mode)
+	}
+
+	// 以最高得分为基准计算置信度
+	maxScore := 0.0
+	for _, rec := range recs {
+		if rec.Score > maxScore {
+			maxScore = rec.Score
+		}
+	}
+
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		if rec.Score <= 0 {
+			continue
+		}
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     reason,
+			Algorithm:  AlgorithmCollaborativeFiltering,
+			Confidence: 0.5 + 0.5*rec.Score/maxScore,
+			Metadata: map[string]interface{}{
+				"mode": mode,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmCollaborativeFiltering,
+		Metadata: map[string]interface{}{
+			"mode": mode,
+		},
+	}, nil
+}
+
+// 批量生成推荐
+func (a *CollaborativeFilteringAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *CollaborativeFilteringAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	userRatings := a.engine.GetUserRatings(userID)
+	if len(userRatings) == 0 {
+		return "", &RecommendationError{Message: fmt.Sprintf("用户没有评分记录: %s", userID)}
+	}
+
+	if a.getMode() == CollaborativeModeItemBased {
+		// 找出与目标物品最相似的已评分物品
+		similar := make([]algorithms.SimilarItem, 0)
+		for ratedItemID := range userRatings {
+			similarity := a.engine.CalculateItemSimilarity(itemID, ratedItemID)
+			if similarity > 0 {
+				similar = append(similar, algorithms.SimilarItem{ItemID: ratedItemID, Similarity: similarity})
+			}
+		}
+		if len(similar) == 0 {
+			return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+		}
+		sort.Slice(similar, func(i, j int) bool {
+			return similar[i].Similarity > similar[j].Similarity
+		})
+		if len(similar) > 3 {
+			similar = similar[:3]
+		}
+		names := make([]string, 0, len(similar))
+		for _, item := range similar {
+			names = append(names, fmt.Sprintf("%s(相似度%.2f)", item.ItemID, item.Similarity))
+		}
+		return fmt.Sprintf("推荐物品 %s 是因为它与您评价过的 %s 相似", itemID, strings.Join(names, "、")), nil
+	}
+
+	// 统计对目标物品有评分的相似用户
+	count := 0
+	for otherUserID, rating := range a.engine.GetItemRatings(itemID) {
+		if otherUserID == userID || rating <= 0 {
+			continue
+		}
+		if a.engine.CalculateUserSimilarity(userID, otherUserID) >= a.engine.GetConfig().SimilarityThreshold {
+			count++
+		}
+	}
+	if count == 0 {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为与您兴趣相似的 %d 位用户对它给出了好评", itemID, count), nil
+}
+
+// 更新推荐模型
+func (a *CollaborativeFilteringAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	for _, behavior := range update.Behaviors {
+		a.addRating(behavior.UserID, behavior.ItemID, behaviorToRating(behavior), behavior.Behavior == datacollection.BehaviorRating)
+	}
+
+	a.log.WithField("behaviors", len(update.Behaviors)).Debug("更新协同过滤模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *CollaborativeFilteringAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmCollaborativeFiltering}, nil
+}
+
+// 获取算法参数
+func (a *CollaborativeFilteringAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"mode":                 a.getMode(),
+		"similarity_threshold": config.SimilarityThreshold,
+		"max_neighbors":        config.MaxNeighbors,
+		"min_common_items":     config.MinCommonItems,
+		"normalization_method": config.NormalizationMethod,
+		"user_similarity":      config.UserSimilarity,
+		"item_similarity":      config.ItemSimilarity,
+		"shrinkage":            config.Shrinkage,
+		"bm25_k1":              config.BM25K1,
+		"bm25_b":               config.BM25B,
+	}, nil
+}
+
+// 设置算法参数
+func (a *CollaborativeFilteringAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+	mode := a.getMode()
+
+	for name, value := range parameters {
+		switch name {
+		case "mode":
+			v, ok := value.(string)
+			if !ok || (v != CollaborativeModeUserBased && v != CollaborativeModeItemBased) {
+				return invalidParameterError(name, value)
+			}
+			mode = v
+		case "similarity_threshold":
+			v, ok := toFloat64(value)
+			if !ok {
+				return invalidParameterError(name, value)
+			}
+			config.SimilarityThreshold = v
+		case "max_neighbors":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.MaxNeighbors = v
+		case "min_common_items":
+			v, ok := toInt(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			config.MinCommonItems = v
+		case "normalization_method":
+			v, ok := value.(string)
+			if !ok || !algorithms.IsValidNormalization(v) {
+				return invalidParameterError(name, value)
+			}
+			config.NormalizationMethod = v
+		case "user_similarity", "item_similarity":
+			v, ok := value.(string)
+			if !ok {
+				return invalidParameterError(name, value)
+			}
+			if _, exists := algorithms.GetSimilarityMetric(v); !exists {
+				return invalidParameterError(name, value)
+			}
+			if name == "user_similarity" {
+				config.UserSimilarity = v
+			} else {
+				config.ItemSimilarity = v
+			}
+		case "shrinkage", "bm25_k1":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			if name == "shrinkage" {
+				config.Shrinkage = v
+			} else {
+				config.BM25K1 = v
+			}
+		case "bm25_b":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v > 1 {
+				return invalidParameterError(name, value)
+			}
+			config.BM25B = v
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.mu.Lock()
+	a.mode = mode
+	a.mu.Unlock()
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *CollaborativeFilteringAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_rating_count"] = len(a.engine.GetUserRatings(userID))
+	return stats, nil
+}
+
+// 记录用户反馈
+func (a *CollaborativeFilteringAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+
+	a.addRating(userID, itemID, parsed.Rating(), parsed.Type == FeedbackRating)
+	return nil
+}
+
+// 写入评分：显式评分与负反馈覆盖已有评分，隐式正反馈保留同一用户物品的最高评分
+func (a *CollaborativeFilteringAdapter) addRating(userID string, itemID string, rating float64, explicit bool) {
+	if explicit || rating < 0 {
+		a.engine.AddUserRating(userID, itemID, rating)
+		return
+	}
+	a.engine.AddImplicitRating(userID, itemID, rating)
+}
+
+// 获取协同过滤模式
+func (a *CollaborativeFilteringAdapter) getMode() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.mode
+}
+
+// 关闭推荐引擎
+func (a *CollaborativeFilteringAdapter) Close() error {
+	a.log.Info("关闭协同过滤推荐引擎")
+	return nil
+}
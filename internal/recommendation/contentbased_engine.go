@@ -0,0 +1,251 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 基于内容过滤推荐引擎适配器
+type ContentBasedFilteringAdapter struct {
+	engine *algorithms.ContentBasedFilteringEngine
+	log    *logrus.Logger
+}
+
+// 创建新的基于内容过滤推荐引擎适配器
+func NewContentBasedFilteringAdapter(engine *algorithms.ContentBasedFilteringEngine, log *logrus.Logger) *ContentBasedFilteringAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewContentBasedFilteringEngine(log)
+	}
+
+	return &ContentBasedFilteringAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐
+func (a *ContentBasedFilteringAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.GenerateRecommendations(request.UserID, resolveLimit(request.Limit))
+
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		metadata := make(map[string]interface{})
+		if item, exists := a.engine.GetItemFeatures(rec.ItemID); exists {
+			metadata["category"] = item.Category
+		}
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     "与您喜欢的内容相似",
+			Algorithm:  AlgorithmContentBasedFiltering,
+			Confidence: math.Max(0, math.Min(1, rec.Score)),
+			Metadata:   metadata,
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmContentBasedFiltering,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 为候选物品打分，供推荐流水线融合多个算法的得分
+func (a *ContentBasedFilteringAdapter) ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scores := a.engine.ScoreItems(userID, itemIDs)
+	results := make([]RecommendationResult, 0, len(scores))
+	for _, itemID := range itemIDs {
+		score, exists := scores[itemID]
+		if !exists {
+			continue
+		}
+		results = append(results, RecommendationResult{
+			ItemID:     itemID,
+			Score:      score,
+			Reason:     "与您喜欢的内容相似",
+			Algorithm:  AlgorithmContentBasedFiltering,
+			Confidence: math.Max(0, math.Min(1, score)),
+		})
+	}
+	return results, nil
+}
+
+// 批量生成推荐
+func (a *ContentBasedFilteringAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *ContentBasedFilteringAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	profile, exists := a.engine.GetUserProfile(userID)
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("用户画像不存在: %s", userID)}
+	}
+
+	item, exists := a.engine.GetItemFeatures(itemID)
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("物品特征不存在: %s", itemID)}
+	}
+
+	// 找出用户偏好中与物品匹配的关键词
+	matched := make([]string, 0)
+	for _, keyword := range item.Keywords {
+		if profile.Preferences[keyword] > 0 {
+			matched = append(matched, keyword)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return profile.Preferences[matched[i]] > profile.Preferences[matched[j]]
+	})
+	if len(matched) > 3 {
+		matched = matched[:3]
+	}
+
+	parts := make([]string, 0, 2)
+	if item.Category != "" && profile.Preferences[item.Category] > 0 {
+		parts = append(parts, fmt.Sprintf("您偏好「%s」类别", item.Category))
+	}
+	if len(matched) > 0 {
+		parts = append(parts, fmt.Sprintf("您关注「%s」", strings.Join(matched, "、")))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("推荐物品 %s 是因为它的特征与您的兴趣画像相近", itemID), nil
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为%s", itemID, strings.Join(parts, "，")), nil
+}
+
+// 更新推荐模型
+func (a *ContentBasedFilteringAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	// 先更新物品特征，再根据用户行为更新画像
+	for _, item := range update.Items {
+		tags, features := extractItemContent(item)
+		a.engine.AddItemContent(item.ItemID, item.Category, item.Title, item.Description, tags, features)
+	}
+	for _, behavior := range update.Behaviors {
+		a.engine.AddUserBehavior(behavior.UserID, behavior.ItemID, behaviorToRating(behavior))
+	}
+
+	a.log.WithFields(logrus.Fields{
+		"items":     len(update.Items),
+		"behaviors": len(update.Behaviors),
+	}).Debug("更新内容过滤模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *ContentBasedFilteringAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmContentBasedFiltering}, nil
+}
+
+// 获取算法参数
+func (a *ContentBasedFilteringAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"feature_weight_threshold": config.FeatureWeightThreshold,
+		"max_features":             config.MaxFeatures,
+		"similarity_threshold":     config.SimilarityThreshold,
+		"learning_rate":            config.LearningRate,
+		"decay_factor":             config.DecayFactor,
+	}, nil
+}
+
+// 设置算法参数
+func (a *ContentBasedFilteringAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "max_features":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.MaxFeatures = v
+		case "feature_weight_threshold", "similarity_threshold", "learning_rate", "decay_factor":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "feature_weight_threshold":
+				config.FeatureWeightThreshold = v
+			case "similarity_threshold":
+				config.SimilarityThreshold = v
+			case "learning_rate":
+				config.LearningRate = v
+			case "decay_factor":
+				if v > 1 {
+					return invalidParameterError(name, value)
+				}
+				config.DecayFactor = v
+			}
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *ContentBasedFilteringAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_history_count"] = len(a.engine.GetUserHistory(userID))
+	if profile, exists := a.engine.GetUserProfile(userID); exists {
+		stats["user_preference_count"] = len(profile.Preferences)
+	}
+	return stats, nil
+}
+
+// 记录用户反馈
+func (a *ContentBasedFilteringAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	rating, err := feedbackToRating(feedback)
+	if err != nil {
+		return err
+	}
+
+	a.engine.AddUserBehavior(userID, itemID, rating)
+	return nil
+}
+
+// 获取物品特征向量，供多样性排序策略计算物品之间的相似度，没有内容特征的物品不返回
+func (a *ContentBasedFilteringAdapter) ItemVectors(ctx context.Context, itemIDs []string) (map[string]map[string]float64, error) {
+	vectors := make(map[string]map[string]float64, len(itemIDs))
+	for _, itemID := range itemIDs {
+		if vector, exists := a.engine.ItemVector(itemID); exists {
+			vectors[itemID] = vector
+		}
+	}
+	return vectors, nil
+}
+
+// 关闭推荐引擎
+func (a *ContentBasedFilteringAdapter) Close() error {
+	a.log.Info("关闭内容过滤推荐引擎")
+	return nil
+}
@@ -0,0 +1,58 @@
+package diversity
+
+import "math"
+
+// 行列式低于该值时剩余候选与已选候选线性相关，不再贪心选择
+const dppEpsilon = 1e-10
+
+// 行列式点过程贪心 MAP（Chen et al. 2018）：核矩阵 L = diag(q)·S·diag(q)，S 为余弦相似度矩阵，
+// 质量 q = exp(α·(相关性−1))，α = λ/(2(1−λ))，缩放到(0,1]避免λ接近1时溢出；
+// 每步选择使 log det(L_Y) 增量最大的候选，以增量 Cholesky 分解维护每个候选的边际增益，返回依次选中的位置
+func (s *itemSet) dpp(lambda float64, window int) []int {
+	n := len(s.relevance)
+	alpha := lambda / (2 * (1 - lambda))
+	quality := make([]float64, n)
+	gains := make([]float64, n)
+	for i := range quality {
+		quality[i] = math.Exp(alpha * (s.relevance[i] - 1))
+		gains[i] = quality[i] * quality[i]
+	}
+
+	selected := make([]bool, n)
+	cholesky := make([][]float64, n)
+	picked := make([]int, 0, window)
+
+	for len(picked) < window {
+		best := -1
+		for i := 0; i < n; i++ {
+			if !selected[i] && (best == -1 || gains[i] > gains[best]) {
+				best = i
+			}
+		}
+		if gains[best] < dppEpsilon {
+			break
+		}
+
+		selected[best] = true
+		picked = append(picked, best)
+		norm := math.Sqrt(gains[best])
+		for i := 0; i < n; i++ {
+			if selected[i] {
+				continue
+			}
+			kernel := quality[best] * quality[i] * s.similarity(best, i)
+			e := (kernel - dot(cholesky[best], cholesky[i])) / norm
+			cholesky[i] = append(cholesky[i], e)
+			gains[i] -= e * e
+		}
+	}
+	return picked
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,39 @@
+package diversity
+
+import "math"
+
+// 最大边际相关：每步选择 λ·相关性 − (1−λ)·与已选候选的最大相似度 最高的候选，
+// 返回依次选中的位置，得分相同时选相关性更高的候选
+func (s *itemSet) mmr(lambda float64, window int) []int {
+	n := len(s.relevance)
+	selected := make([]bool, n)
+	maxSimilarity := make([]float64, n)
+	picked := make([]int, 0, window)
+
+	for len(picked) < window {
+		best := -1
+		bestScore := math.Inf(-1)
+		for i := 0; i < n; i++ {
+			if selected[i] {
+				continue
+			}
+			score := lambda*s.relevance[i] - (1-lambda)*maxSimilarity[i]
+			if score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+
+		selected[best] = true
+		picked = append(picked, best)
+		for i := 0; i < n; i++ {
+			if selected[i] {
+				continue
+			}
+			if similarity := s.similarity(best, i); len(picked) == 1 || similarity > maxSimilarity[i] {
+				maxSimilarity[i] = similarity
+			}
+		}
+	}
+	return picked
+}
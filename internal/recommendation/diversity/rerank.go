@@ -0,0 +1,175 @@
+// Package diversity 多样性重排：以物品特征向量的余弦相似度衡量候选之间的冗余，
+// 按最大边际相关（MMR）或行列式点过程（DPP）贪心 MAP 调整候选顺序，不丢弃任何候选
+package diversity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Method 多样性重排方法
+type Method string
+
+const (
+	MethodMMR Method = "mmr" // 最大边际相关
+	MethodDPP Method = "dpp" // 行列式点过程贪心 MAP
+)
+
+// ErrUnsupportedMethod 不支持的多样性重排方法
+var ErrUnsupportedMethod = errors.New("不支持的多样性重排方法")
+
+// Config 多样性重排配置
+type Config struct {
+	Method Method
+	Lambda float64 // 相关性与多样性的权衡，取值[0,1]，1 时保持相关性顺序，0 时只考虑多样性
+	Window int     // 参与重排的前 Window 个位置，之后的候选按相关性顺序排在末尾；0 表示全部候选
+}
+
+// DefaultConfig 默认多样性重排配置
+func DefaultConfig() Config {
+	return Config{
+		Method: MethodMMR,
+		Lambda: 0.7,
+	}
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.Method != MethodMMR && c.Method != MethodDPP {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMethod, c.Method)
+	}
+	if c.Lambda < 0 || c.Lambda > 1 || math.IsNaN(c.Lambda) {
+		return fmt.Errorf("多样性权衡系数应在[0,1]之间: %v", c.Lambda)
+	}
+	if c.Window < 0 {
+		return fmt.Errorf("重排窗口不能为负数: %d", c.Window)
+	}
+	return nil
+}
+
+// Candidate 待重排的候选
+type Candidate struct {
+	ID        string
+	Relevance float64            // 相关性得分，通常为召回或排序得分
+	Vector    map[string]float64 // 物品特征向量，为空时与其他候选的相似度为0
+}
+
+// Rerank 返回重排后的候选下标序列，包含全部候选；候选先按相关性降序，
+// 再在前 Window 个位置上按配置的方法兼顾相关性与多样性
+func Rerank(candidates []Candidate, config Config) ([]int, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return candidates[order[i]].Relevance > candidates[order[j]].Relevance
+	})
+	if len(candidates) <= 1 || config.Lambda == 1 {
+		return order, nil
+	}
+
+	window := config.Window
+	if window == 0 || window > len(candidates) {
+		window = len(candidates)
+	}
+	items := newItemSet(candidates, order)
+
+	var picked []int
+	switch config.Method {
+	case MethodDPP:
+		picked = items.dpp(config.Lambda, window)
+	default:
+		picked = items.mmr(config.Lambda, window)
+	}
+
+	// 未选中的候选保持相关性顺序排在末尾
+	chosen := make([]bool, len(order))
+	result := make([]int, 0, len(order))
+	for _, position := range picked {
+		chosen[position] = true
+		result = append(result, order[position])
+	}
+	for position, index := range order {
+		if !chosen[position] {
+			result = append(result, index)
+		}
+	}
+	return result, nil
+}
+
+// PositionScores 重排后每个位置的得分：第k个位置取候选中第k高的相关性，
+// 得分分布不变，下游按得分排序时保持重排后的顺序
+func PositionScores(candidates []Candidate) []float64 {
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		scores[i] = candidate.Relevance
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	return scores
+}
+
+// 按相关性降序排列的候选，相关性归一化到[0,1]，向量归一化为单位向量
+type itemSet struct {
+	relevance []float64
+	vectors   []map[string]float64
+}
+
+func newItemSet(candidates []Candidate, order []int) *itemSet {
+	items := &itemSet{
+		relevance: make([]float64, len(order)),
+		vectors:   make([]map[string]float64, len(order)),
+	}
+
+	highest := candidates[order[0]].Relevance
+	lowest := candidates[order[len(order)-1]].Relevance
+	for position, index := range order {
+		items.relevance[position] = 1
+		if highest > lowest {
+			items.relevance[position] = (candidates[index].Relevance - lowest) / (highest - lowest)
+		}
+		items.vectors[position] = normalize(candidates[index].Vector)
+	}
+	return items
+}
+
+// 两个候选的余弦相似度，没有特征向量的候选与其他候选的相似度为0
+func (s *itemSet) similarity(i, j int) float64 {
+	if i == j {
+		return 1
+	}
+	a, b := s.vectors[i], s.vectors[j]
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for key, value := range a {
+		dot += value * b[key]
+	}
+	return dot
+}
+
+// 归一化为单位向量，零向量返回 nil
+func normalize(vector map[string]float64) map[string]float64 {
+	norm := 0.0
+	for _, value := range vector {
+		norm += value * value
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+
+	normalized := make(map[string]float64, len(vector))
+	for key, value := range vector {
+		if value != 0 {
+			normalized[key] = value / norm
+		}
+	}
+	return normalized
+}
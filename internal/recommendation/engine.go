@@ -3,10 +3,16 @@ package recommendation
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/guanguoyintao/luban/internal/recommendation/bandit"
+	"github.com/guanguoyintao/luban/internal/recommendation/experiment"
+	"github.com/guanguoyintao/luban/internal/recommendation/exposurelog"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,6 +22,11 @@ type RecommendationEngineManager struct {
 	engines   map[AlgorithmType]RecommendationEngine // 算法引擎映射
 	log       *logrus.Logger
 	config    *EngineConfig
+	explorer  *bandit.Explorer // 探索层，为空时不探索
+	experiments *experiment.Runner // A/B实验，为空时不分流
+	interleaving *experiment.Interleaving // 交错对比，为空时不交错
+	exposureLog exposurelog.Logger // 曝光日志，为空时不记录
+	suppressions *suppressionList // 用户负反馈屏蔽的物品
 }
 
 // 引擎配置
@@ -25,6 +36,11 @@ type EngineConfig struct {
 	MinConfidenceScore    float64
 	EnableFallback        bool
 	FallbackAlgorithm     AlgorithmType
+	ColdStartAlgorithm    AlgorithmType // 冷启动用户以及算法没有返回结果时使用的算法
+	ScenarioAlgorithms    map[RecommendationScenario]AlgorithmType // 未指定算法时各场景使用的算法
+	SuppressionTTL        time.Duration // 负反馈屏蔽物品的时长
+	IgnoreSuppressionLimit int          // 同一物品被忽略达到该次数后屏蔽，为0时忽略不屏蔽
+	IgnoreWindow          time.Duration // 忽略次数的统计窗口，超过窗口未达到次数的忽略记录过期清零
 }
 
 // 创建新的推荐引擎管理器
@@ -39,12 +55,21 @@ func NewRecommendationEngineManager(log *logrus.Logger) *RecommendationEngineMan
 		MinConfidenceScore: 0.1,
 		EnableFallback:     true,
 		FallbackAlgorithm:  AlgorithmContentBasedFiltering,
+		ColdStartAlgorithm: AlgorithmColdStart,
+		ScenarioAlgorithms: map[RecommendationScenario]AlgorithmType{
+			ScenarioShoppingCart:  AlgorithmAssociationRules,
+			ScenarioProductDetail: AlgorithmAssociationRules,
+		},
+		SuppressionTTL:         30 * 24 * time.Hour,
+		IgnoreSuppressionLimit: 3,
+		IgnoreWindow:           7 * 24 * time.Hour,
 	}
 	
 	manager := &RecommendationEngineManager{
-		engines: make(map[AlgorithmType]RecommendationEngine),
-		log:     log,
-		config:  config,
+		engines:      make(map[AlgorithmType]RecommendationEngine),
+		log:          log,
+		config:       config,
+		suppressions: newSuppressionList(),
 	}
 	
 	// 注册默认算法引擎
@@ -55,9 +80,31 @@ func NewRecommendationEngineManager(log *logrus.Logger) *RecommendationEngineMan
 
 // 注册默认算法引擎
 func (m *RecommendationEngineManager) registerDefaultEngines() {
-	// 这里可以注册具体的算法实现
-	// 例如：m.RegisterEngine(AlgorithmCollaborativeFiltering, NewCollaborativeFilteringEngine(m.log))
 	m.log.Info("注册默认推荐算法引擎")
+	
+	// 混合过滤与协同过滤、内容过滤共享同一份模型数据
+	collaborativeEngine := algorithms.NewCollaborativeFilteringEngine(m.log)
+	contentBasedEngine := algorithms.NewContentBasedFilteringEngine(m.log)
+	hybridEngine := algorithms.NewHybridFilteringEngine(collaborativeEngine, contentBasedEngine, m.log)
+	
+	// 混合过滤的流行度与时效性得分使用趋势热度引擎的时间衰减计数
+	trendingEngine := algorithms.NewTrendingEngine(m.log)
+	hybridEngine.SetTrendingEngine(trendingEngine)
+	
+	collaborative := NewCollaborativeFilteringAdapter(collaborativeEngine, m.log)
+	contentBased := NewContentBasedFilteringAdapter(contentBasedEngine, m.log)
+	
+	m.RegisterEngine(AlgorithmCollaborativeFiltering, collaborative)
+	m.RegisterEngine(AlgorithmContentBasedFiltering, contentBased)
+	m.RegisterEngine(AlgorithmHybridFiltering, NewHybridFilteringAdapter(hybridEngine, collaborative, contentBased, m.log))
+	m.RegisterEngine(AlgorithmMatrixFactorization, NewMatrixFactorizationAdapter(algorithms.NewMatrixFactorizationEngine(m.log), m.log))
+	m.RegisterEngine(AlgorithmBPR, NewBPRAdapter(algorithms.NewBPREngine(m.log), m.log))
+	m.RegisterEngine(AlgorithmRuleBased, NewRuleBasedAdapter(algorithms.NewRuleBasedEngine(m.log), m.log))
+	m.RegisterEngine(AlgorithmDeepLearning, NewTwoTowerAdapter(algorithms.NewTwoTowerEngine(m.log), nil, m.log))
+	m.RegisterEngine(AlgorithmSessionBased, NewSessionBasedAdapter(algorithms.NewSessionBasedEngine(m.log), m.log))
+	m.RegisterEngine(AlgorithmPopularity, NewTrendingAdapter(trendingEngine, m.log))
+	m.RegisterEngine(AlgorithmAssociationRules, NewAssociationRuleAdapter(algorithms.NewAssociationRuleEngine(m.log), m.log))
+	m.RegisterEngine(AlgorithmColdStart, NewColdStartAdapter(algorithms.NewColdStartEngine(contentBasedEngine, trendingEngine, m.log), m.log))
 }
 
 // 注册算法引擎
@@ -69,6 +116,15 @@ func (m *RecommendationEngineManager) RegisterEngine(algorithm AlgorithmType, en
 	m.log.WithField("algorithm", algorithm).Info("注册推荐算法引擎成功")
 }
 
+// 获取已注册的算法引擎
+func (m *RecommendationEngineManager) GetEngine(algorithm AlgorithmType) (RecommendationEngine, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	engine, exists := m.engines[algorithm]
+	return engine, exists
+}
+
 // 生成推荐
 func (m *RecommendationEngineManager) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
 	startTime := time.Now()
@@ -76,10 +132,22 @@ func (m *RecommendationEngineManager) Recommend(ctx context.Context, request Rec
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
-	// 确定使用的算法
+	// 确定使用的算法：请求指定的算法优先，其次是用户所在的实验分组
 	algorithm := request.Algorithm
+	coldStart := false
+	assignment, inExperiment := m.assignExperiment(request)
+	if inExperiment {
+		algorithm = AlgorithmType(assignment.Arm)
+	}
 	if algorithm == "" {
 		algorithm = m.config.DefaultAlgorithm
+		if scenarioAlgorithm, exists := m.config.ScenarioAlgorithms[request.Scenario]; exists {
+			algorithm = scenarioAlgorithm
+		} else if m.isColdUser(request.UserID) {
+			// 交互数未达到预热阈值的用户使用冷启动算法
+			algorithm = m.config.ColdStartAlgorithm
+			coldStart = true
+		}
 	}
 	
 	// 获取对应的引擎
@@ -88,26 +156,113 @@ func (m *RecommendationEngineManager) Recommend(ctx context.Context, request Rec
 		return nil, &RecommendationError{Message: fmt.Sprintf("算法引擎不存在: %s", algorithm)}
 	}
 	
+	// 未进入A/B实验的用户按交错对比合并两个算法的结果
+	interleaving := !inExperiment && m.interleavingEnabled(request)
+	
+	// 启用探索时向引擎请求更大的候选池，由探索层选择最终位置，交错列表不参与探索
+	exploring := m.explorer != nil && !interleaving && explorationEnabled(request)
+	engineRequest := request
+	if exploring && engineRequest.Limit < m.config.MaxRecommendations {
+		engineRequest.Limit = m.config.MaxRecommendations
+	}
+	
+	// 用户屏蔽的物品会被过滤，向引擎多请求相应数量的结果
+	suppressed := m.suppressions.suppressed(request.UserID, startTime)
+	if len(suppressed) > 0 {
+		engineRequest.Limit = resolveLimit(engineRequest.Limit) + len(suppressed)
+	}
+	
 	// 生成推荐
-	response, err := engine.Recommend(ctx, request)
+	var response *RecommendationResponse
+	var err error
+	if interleaving {
+		if response, err = m.recommendInterleaved(ctx, engineRequest); err != nil {
+			m.log.WithError(err).WithField("user_id", request.UserID).Warn("交错对比生成推荐失败，使用单一算法")
+			interleaving = false
+		}
+	}
+	if !interleaving {
+		response, err = engine.Recommend(ctx, engineRequest)
+	}
+	substituted := false
 	if err != nil {
 		m.log.WithError(err).WithField("algorithm", algorithm).Error("推荐生成失败")
 		
-		// 如果启用回退算法，尝试使用回退算法
+		// 如果启用回退算法，尝试使用回退算法，回退结果与正常结果一样经过屏蔽、置信度过滤、截取与曝光记录
 		if m.config.EnableFallback && algorithm != m.config.FallbackAlgorithm {
 			m.log.WithField("fallback_algorithm", m.config.FallbackAlgorithm).Info("使用回退算法")
 			fallbackEngine, fallbackExists := m.engines[m.config.FallbackAlgorithm]
 			if fallbackExists {
-				request.Algorithm = m.config.FallbackAlgorithm
-				return fallbackEngine.Recommend(ctx, request)
+				fallbackRequest := engineRequest
+				fallbackRequest.Algorithm = m.config.FallbackAlgorithm
+				response, err = fallbackEngine.Recommend(ctx, fallbackRequest)
+				algorithm = m.config.FallbackAlgorithm
+				interleaving = false
+				substituted = true
 			}
 		}
 		
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata["fallback"] = true
+	}
+	
+	// 冷启动用户没有推荐结果时使用冷启动算法，替换后的结果不计入实验分组；只用于打分的请求保持算法自身的结果，
+	// 避免多个算法把同一份冷启动列表当作各自的打分
+	if len(response.Recommendations) == 0 && !scoringOnly(request) && m.config.ColdStartAlgorithm != "" && algorithm != m.config.ColdStartAlgorithm {
+		if coldStartEngine, coldStartExists := m.engines[m.config.ColdStartAlgorithm]; coldStartExists {
+			coldStartRequest := engineRequest
+			coldStartRequest.Algorithm = m.config.ColdStartAlgorithm
+			if coldStartResponse, coldStartErr := coldStartEngine.Recommend(ctx, coldStartRequest); coldStartErr == nil {
+				m.log.WithFields(logrus.Fields{
+					"user_id":             request.UserID,
+					"algorithm":           algorithm,
+					"cold_start_algorithm": m.config.ColdStartAlgorithm,
+				}).Info("使用冷启动算法")
+				response = coldStartResponse
+				interleaving = false
+				substituted = true
+				if response.Metadata == nil {
+					response.Metadata = make(map[string]interface{})
+				}
+				response.Metadata["cold_start"] = true
+			}
+		}
+	}
+	if coldStart {
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata["cold_start"] = true
+	}
+	
+	// 过滤低置信度推荐，交错列表在合并前已按来源算法过滤，保持交错顺序
+	filteredRecommendations := response.Recommendations
+	if !interleaving {
+		filteredRecommendations = m.filterLowConfidenceRecommendations(response.Recommendations)
+	}
+	
+	// 使用默认算法时为新物品保留位置，实验分组与交错列表不做额外干预
+	if request.Algorithm == "" && !inExperiment && !interleaving && algorithm == m.config.DefaultAlgorithm {
+		filteredRecommendations = m.onboardNewItems(request, filteredRecommendations)
+	}
+	
+	// 过滤用户屏蔽的物品
+	if len(suppressed) > 0 {
+		filteredRecommendations = removeSuppressed(filteredRecommendations, suppressed)
 	}
 	
-	// 过滤低置信度推荐
-	filteredRecommendations := m.filterLowConfidenceRecommendations(response.Recommendations)
+	// 探索层重排前的结果作为曝光日志中的候选列表
+	candidates := filteredRecommendations
+	
+	// 探索层重排
+	if exploring {
+		filteredRecommendations = m.explore(request, filteredRecommendations)
+	}
 	
 	// 限制推荐数量
 	if len(filteredRecommendations) > request.Limit && request.Limit > 0 {
@@ -118,6 +273,19 @@ func (m *RecommendationEngineManager) Recommend(ctx context.Context, request Rec
 	response.TotalCount = len(filteredRecommendations)
 	response.ProcessingTime = time.Since(startTime).Milliseconds()
 	
+	// 记录实验曝光，回退算法与冷启动算法替换的结果不计入实验分组
+	if inExperiment && !substituted {
+		m.exposeExperiment(request, assignment, response)
+	}
+	if interleaving {
+		m.exposeInterleaving(request, response)
+	}
+	
+	// 写入曝光日志，只用于打分的结果由上层流水线在最终列表上记录
+	if m.exposureLog != nil && !scoringOnly(request) {
+		m.logExposure(request, interleaving, candidates, response)
+	}
+	
 	m.log.WithFields(logrus.Fields{
 		"user_id":      request.UserID,
 		"algorithm":    algorithm,
@@ -243,18 +411,34 @@ func (m *RecommendationEngineManager) GetRecommendationStats(ctx context.Context
 		stats[string(algorithm)] = engineStats
 	}
 	
+	if m.explorer != nil {
+		stats["exploration"] = m.explorer.GetStats()
+	}
+	if m.experiments != nil {
+		stats["experiments"] = m.experiments.GetStats()
+	}
+	if m.interleaving != nil {
+		stats["interleaving"] = m.interleaving.Result()
+	}
+	stats["suppressed_items"] = m.suppressions.count()
+	
 	return stats, nil
 }
 
-// 记录用户反馈
+// 记录用户反馈，反馈解析为类型化的 Feedback 后分发给各算法引擎
 func (m *RecommendationEngineManager) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+	
 	// 记录到所有算法引擎
 	var lastError error
 	for algorithm, engine := range m.engines {
-		if err := engine.RecordFeedback(ctx, userID, itemID, feedback); err != nil {
+		if err := engine.RecordFeedback(ctx, userID, itemID, parsed); err != nil {
 			m.log.WithError(err).WithFields(logrus.Fields{
 				"algorithm": algorithm,
 				"user_id":   userID,
@@ -264,6 +448,35 @@ func (m *RecommendationEngineManager) RecordFeedback(ctx context.Context, userID
 		}
 	}
 	
+	// 负反馈屏蔽物品，正反馈解除屏蔽
+	if m.suppressions.record(parsed, m.config.SuppressionTTL, m.config.IgnoreSuppressionLimit, m.config.IgnoreWindow) {
+		m.log.WithFields(logrus.Fields{
+			"user_id":       userID,
+			"item_id":       itemID,
+			"feedback_type": parsed.Type,
+		}).Info("屏蔽用户负反馈的物品")
+	}
+	
+	// 将反馈作为探索层的奖励
+	if m.explorer != nil {
+		m.explorer.Reward(userID, itemID, feedbackReward(parsed))
+	}
+	
+	// 将反馈归因到曝光该物品的实验分组
+	if m.experiments != nil {
+		m.experiments.RecordFeedback(userID, itemID, feedbackReward(parsed))
+	}
+	
+	// 将点击计入交错列表中选出该物品的算法
+	if m.interleaving != nil {
+		m.interleaving.RecordFeedback(userID, itemID, feedbackReward(parsed))
+	}
+	
+	// 反馈写入曝光日志，离线评估时与之前的展示关联
+	if m.exposureLog != nil {
+		m.logFeedback(parsed)
+	}
+	
 	if lastError != nil {
 		return lastError
 	}
@@ -271,6 +484,7 @@ func (m *RecommendationEngineManager) RecordFeedback(ctx context.Context, userID
 	m.log.WithFields(logrus.Fields{
 		"user_id":  userID,
 		"item_id":  itemID,
+		"feedback_type": parsed.Type,
 	}).Info("记录用户反馈成功")
 	
 	return nil
@@ -316,6 +530,457 @@ func (m *RecommendationEngineManager) filterLowConfidenceRecommendations(recomme
 	return filtered
 }
 
+// 移除用户屏蔽的物品
+func removeSuppressed(recommendations []RecommendationResult, suppressed map[string]bool) []RecommendationResult {
+	filtered := make([]RecommendationResult, 0, len(recommendations))
+	for _, rec := range recommendations {
+		if !suppressed[rec.ItemID] {
+			filtered = append(filtered, rec)
+		}
+	}
+	return filtered
+}
+
+// 冷启动判定，由冷启动算法引擎实现
+type coldUserDetector interface {
+	IsColdUser(userID string) bool
+}
+
+// 为指定候选物品打分，由支持逐物品打分的算法引擎实现
+type candidateScorer interface {
+	ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error)
+}
+
+// 新物品曝光，由冷启动算法引擎实现
+type newItemOnboarder interface {
+	OnboardItems(userID string, limit int) []RecommendationResult
+}
+
+// 判断用户是否仍处于冷启动阶段，冷启动算法不支持判定时视为已完成冷启动
+func (m *RecommendationEngineManager) isColdUser(userID string) bool {
+	detector, ok := m.engines[m.config.ColdStartAlgorithm].(coldUserDetector)
+	return ok && detector.IsColdUser(userID)
+}
+
+// 将冷启动算法挑选的新物品放入前 Limit 个位置，位置不足时替换末尾的结果，
+// 新物品沿用所占位置原有的得分以保持结果有序
+func (m *RecommendationEngineManager) onboardNewItems(request RecommendationRequest, recommendations []RecommendationResult) []RecommendationResult {
+	onboarder, ok := m.engines[m.config.ColdStartAlgorithm].(newItemOnboarder)
+	if !ok || len(recommendations) == 0 {
+		return recommendations
+	}
+	
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+	shown := limit
+	if shown > len(recommendations) {
+		shown = len(recommendations)
+	}
+	
+	// 跳过已在展示位置中的物品
+	existing := make(map[string]bool, shown)
+	for _, rec := range recommendations[:shown] {
+		existing[rec.ItemID] = true
+	}
+	newItems := make([]RecommendationResult, 0)
+	selected := make(map[string]bool)
+	for _, item := range onboarder.OnboardItems(request.UserID, limit) {
+		if !existing[item.ItemID] {
+			newItems = append(newItems, item)
+			selected[item.ItemID] = true
+		}
+	}
+	if len(newItems) == 0 {
+		return recommendations
+	}
+	
+	position := shown - (len(newItems) - (limit - shown))
+	if position > shown {
+		position = shown
+	}
+	if position < 0 {
+		position = 0
+	}
+	
+	merged := make([]RecommendationResult, 0, len(recommendations)+len(newItems))
+	merged = append(merged, recommendations[:position]...)
+	for i, item := range newItems {
+		reference := position + i
+		if reference >= len(recommendations) {
+			reference = len(recommendations) - 1
+		}
+		item.Metadata["content_score"] = item.Score
+		item.Score = recommendations[reference].Score
+		merged = append(merged, item)
+	}
+	for _, rec := range recommendations[position:] {
+		if !selected[rec.ItemID] {
+			merged = append(merged, rec)
+		}
+	}
+	
+	return merged
+}
+
+// 按探索策略选择前 Limit 个位置，探索带入的结果在元数据中标记 explored
+func (m *RecommendationEngineManager) explore(request RecommendationRequest, recommendations []RecommendationResult) []RecommendationResult {
+	if len(recommendations) == 0 {
+		return recommendations
+	}
+	
+	slots := request.Limit
+	if slots <= 0 {
+		slots = defaultRecommendationLimit
+	}
+	if slots > len(recommendations) {
+		slots = len(recommendations)
+	}
+	
+	// 引擎得分归一化到[0,1]作为策略先验
+	minScore, maxScore := recommendations[0].Score, recommendations[0].Score
+	for _, rec := range recommendations {
+		minScore = math.Min(minScore, rec.Score)
+		maxScore = math.Max(maxScore, rec.Score)
+	}
+	candidates := make([]bandit.Candidate, len(recommendations))
+	for i, rec := range recommendations {
+		prior := 1.0
+		if maxScore > minScore {
+			prior = (rec.Score - minScore) / (maxScore - minScore)
+		}
+		candidates[i] = bandit.Candidate{ID: rec.ItemID, Prior: prior}
+	}
+	
+	policy := string(m.explorer.Policy().Type())
+	placements := m.explorer.Rerank(request.UserID, request.Context, candidates, slots)
+	explored := make([]RecommendationResult, len(placements))
+	for i, placement := range placements {
+		rec := recommendations[placement.Index]
+		metadata := make(map[string]interface{}, len(rec.Metadata)+4)
+		for key, value := range rec.Metadata {
+			metadata[key] = value
+		}
+		metadata["explored"] = placement.Explored
+		metadata["exploration_policy"] = policy
+		metadata["exploration_score"] = placement.Score
+		metadata["exploit_rank"] = placement.Index + 1
+		metadata["exploration_propensity"] = placement.Propensity
+		rec.Metadata = metadata
+		explored[i] = rec
+	}
+	
+	return explored
+}
+
+// 在上层流水线最终展示的列表上探索并写入曝光日志，返回前 Limit 个位置；
+// 流水线打分阶段的请求设置 ParameterScoringOnly，探索与曝光只在这里记录，避免记录不会展示的物品
+func (m *RecommendationEngineManager) ServeList(request RecommendationRequest, recommendations []RecommendationResult) []RecommendationResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	// 过滤用户屏蔽的物品
+	if suppressed := m.suppressions.suppressed(request.UserID, time.Now()); len(suppressed) > 0 {
+		recommendations = removeSuppressed(recommendations, suppressed)
+	}
+	
+	served := recommendations
+	if m.explorer != nil && explorationEnabled(request) {
+		served = m.explore(request, recommendations)
+	}
+	if request.Limit > 0 && len(served) > request.Limit {
+		served = served[:request.Limit]
+	}
+	
+	if m.exposureLog != nil {
+		response := &RecommendationResponse{
+			UserID:          request.UserID,
+			Recommendations: served,
+			TotalCount:      len(served),
+			Algorithm:       pipelineAlgorithm,
+		}
+		m.logExposure(request, false, recommendations, response)
+	}
+	return served
+}
+
+// 用 request.Algorithm 指定的算法为推荐流水线召回的候选物品打分：
+// 引擎支持逐物品打分时直接为候选打分，否则返回引擎自身的前 Limit 个推荐结果，由调用方与候选取交集；
+// 直接调用算法引擎，不做冷启动替换、探索、实验分流与曝光记录
+func (m *RecommendationEngineManager) ScoreCandidates(ctx context.Context, request RecommendationRequest, itemIDs []string) ([]RecommendationResult, error) {
+	engine, exists := m.GetEngine(request.Algorithm)
+	if !exists {
+		return nil, &RecommendationError{Message: fmt.Sprintf("算法引擎不存在: %s", request.Algorithm)}
+	}
+	
+	if scorer, ok := engine.(candidateScorer); ok {
+		return scorer.ScoreCandidates(ctx, request.UserID, itemIDs)
+	}
+	
+	parameters := make(map[string]interface{}, len(request.Parameters)+1)
+	for key, value := range request.Parameters {
+		parameters[key] = value
+	}
+	parameters[ParameterScoringOnly] = true
+	request.Parameters = parameters
+	response, err := engine.Recommend(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.Recommendations, nil
+}
+
+// 设置探索层，传入nil关闭探索
+func (m *RecommendationEngineManager) SetExplorer(explorer *bandit.Explorer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.explorer = explorer
+}
+
+// 获取探索层
+func (m *RecommendationEngineManager) GetExplorer() *bandit.Explorer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.explorer
+}
+
+// 设置A/B实验运行时，传入nil停止实验分流
+func (m *RecommendationEngineManager) SetExperiments(experiments *experiment.Runner) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.experiments = experiments
+}
+
+// 获取A/B实验运行时
+func (m *RecommendationEngineManager) GetExperiments() *experiment.Runner {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.experiments
+}
+
+// 未指定算法的请求按实验分流，分组算法未注册时不进入实验
+// 推荐流水线为召回候选打分时总是指定算法，因此流水线的推荐接口不参与A/B实验与交错对比，
+// 实验只覆盖直接调用引擎管理器且未指定算法的请求（gRPC Recommend、HTTP POST /api/v1/recommendations）
+func (m *RecommendationEngineManager) assignExperiment(request RecommendationRequest) (experiment.Assignment, bool) {
+	if request.Algorithm != "" || m.experiments == nil {
+		return experiment.Assignment{}, false
+	}
+	assignment, ok := m.experiments.Assign(request.UserID, string(request.Scenario))
+	if !ok {
+		return assignment, false
+	}
+	if _, exists := m.engines[AlgorithmType(assignment.Arm)]; !exists {
+		m.log.WithFields(logrus.Fields{
+			"experiment_id": assignment.ExperimentID,
+			"arm":           assignment.Arm,
+		}).Warn("实验分组的算法引擎不存在")
+		return assignment, false
+	}
+	return assignment, true
+}
+
+// 记录实验曝光，并在响应元数据中标记实验与分组
+func (m *RecommendationEngineManager) exposeExperiment(request RecommendationRequest, assignment experiment.Assignment, response *RecommendationResponse) {
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata["experiment_id"] = assignment.ExperimentID
+	response.Metadata["experiment_arm"] = assignment.Arm
+	
+	itemIDs := make([]string, len(response.Recommendations))
+	for i, rec := range response.Recommendations {
+		itemIDs[i] = rec.ItemID
+	}
+	if err := m.experiments.Expose(assignment, request.UserID, string(request.Scenario), itemIDs); err != nil {
+		m.log.WithError(err).WithField("experiment_id", assignment.ExperimentID).Warn("记录实验曝光失败")
+	}
+}
+
+// 设置交错对比，传入nil停止交错
+func (m *RecommendationEngineManager) SetInterleaving(interleaving *experiment.Interleaving) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interleaving = interleaving
+}
+
+// 获取交错对比
+func (m *RecommendationEngineManager) GetInterleaving() *experiment.Interleaving {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.interleaving
+}
+
+// 未指定算法、用户在交错流量中且两个算法引擎都已注册时交错
+func (m *RecommendationEngineManager) interleavingEnabled(request RecommendationRequest) bool {
+	if request.Algorithm != "" || m.interleaving == nil || !m.interleaving.Covers(request.UserID, string(request.Scenario)) {
+		return false
+	}
+	for _, algorithm := range m.interleaving.Algorithms() {
+		if _, exists := m.engines[AlgorithmType(algorithm)]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// 两个算法分别生成推荐并过滤低置信度结果，按团队选拔合并，每个位置在元数据中标记来源算法
+func (m *RecommendationEngineManager) recommendInterleaved(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	algorithms := m.interleaving.Algorithms()
+	var lists [2][]string
+	var results [2]map[string]RecommendationResult
+	
+	for team, algorithm := range algorithms {
+		teamRequest := request
+		teamRequest.Algorithm = AlgorithmType(algorithm)
+		response, err := m.engines[teamRequest.Algorithm].Recommend(ctx, teamRequest)
+		if err != nil {
+			return nil, err
+		}
+		
+		recommendations := m.filterLowConfidenceRecommendations(response.Recommendations)
+		results[team] = make(map[string]RecommendationResult, len(recommendations))
+		for _, rec := range recommendations {
+			lists[team] = append(lists[team], rec.ItemID)
+			results[team][rec.ItemID] = rec
+		}
+	}
+	
+	slots := m.interleaving.Interleave(lists, resolveLimit(request.Limit))
+	recommendations := make([]RecommendationResult, len(slots))
+	for i, slot := range slots {
+		team := 0
+		if slot.Team == algorithms[1] {
+			team = 1
+		}
+		rec := results[team][slot.ItemID]
+		metadata := make(map[string]interface{}, len(rec.Metadata)+3)
+		for key, value := range rec.Metadata {
+			metadata[key] = value
+		}
+		metadata["interleaving_id"] = m.interleaving.ID()
+		metadata["interleaving_team"] = slot.Team
+		metadata["interleaving_rank"] = slot.SourceRank
+		metadata["interleaving_propensity"] = slot.Propensity
+		rec.Metadata = metadata
+		if rec.Algorithm == "" {
+			rec.Algorithm = AlgorithmType(slot.Team)
+		}
+		recommendations[i] = rec
+	}
+	
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: recommendations,
+		TotalCount:      len(recommendations),
+		Metadata: map[string]interface{}{
+			"interleaving_id":         m.interleaving.ID(),
+			"interleaving_algorithms": []string{algorithms[0], algorithms[1]},
+		},
+	}, nil
+}
+
+// 记录实际展示的交错列表
+func (m *RecommendationEngineManager) exposeInterleaving(request RecommendationRequest, response *RecommendationResponse) {
+	slots := make([]experiment.Slot, 0, len(response.Recommendations))
+	for _, rec := range response.Recommendations {
+		team, _ := rec.Metadata["interleaving_team"].(string)
+		rank, _ := rec.Metadata["interleaving_rank"].(int)
+		slots = append(slots, experiment.Slot{ItemID: rec.ItemID, Team: team, SourceRank: rank})
+	}
+	m.interleaving.Expose(request.UserID, slots)
+}
+
+// 设置曝光日志，传入nil停止记录
+func (m *RecommendationEngineManager) SetExposureLog(exposureLog exposurelog.Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exposureLog = exposureLog
+}
+
+// 记录一次请求的候选列表与实际展示的位置，位置的倾向性来自探索策略或交错对比，确定性排序为1
+func (m *RecommendationEngineManager) logExposure(request RecommendationRequest, interleaving bool, candidates []RecommendationResult, response *RecommendationResponse) {
+	requestID := uuid.New().String()
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata["request_id"] = requestID
+	
+	entry := exposurelog.Entry{
+		Type:       exposurelog.EntryExposure,
+		RequestID:  requestID,
+		UserID:     request.UserID,
+		Timestamp:  time.Now(),
+		Scenario:   string(request.Scenario),
+		Context:    request.Context,
+		Algorithm:  string(response.Algorithm),
+		Candidates: make([]exposurelog.Candidate, len(candidates)),
+		Shown:      make([]exposurelog.Position, len(response.Recommendations)),
+	}
+	if interleaving {
+		entry.Algorithm = "interleaving:" + m.interleaving.ID()
+	}
+	for i, rec := range candidates {
+		entry.Candidates[i] = exposurelog.Candidate{ItemID: rec.ItemID, Score: rec.Score}
+	}
+	for i, rec := range response.Recommendations {
+		propensity := 1.0
+		if value, ok := rec.Metadata["exploration_propensity"].(float64); ok {
+			propensity = value
+		} else if value, ok := rec.Metadata["interleaving_propensity"].(float64); ok {
+			propensity = value
+		}
+		entry.Shown[i] = exposurelog.Position{
+			ItemID:     rec.ItemID,
+			Position:   i + 1,
+			Propensity: propensity,
+			Algorithm:  string(rec.Algorithm),
+		}
+	}
+	
+	if err := m.exposureLog.Log(entry); err != nil {
+		m.log.WithError(err).WithField("user_id", request.UserID).Warn("写入曝光日志失败")
+	}
+}
+
+// 记录反馈
+func (m *RecommendationEngineManager) logFeedback(feedback Feedback) {
+	timestamp := feedback.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	entry := exposurelog.Entry{
+		Type:      exposurelog.EntryFeedback,
+		UserID:    feedback.UserID,
+		ItemID:    feedback.ItemID,
+		Timestamp: timestamp,
+		Reward:    feedbackReward(feedback),
+	}
+	if err := m.exposureLog.Log(entry); err != nil {
+		m.log.WithError(err).WithField("user_id", feedback.UserID).Warn("写入曝光日志失败")
+	}
+}
+
+// 请求参数 exploration 为 false 或只用于打分时不探索
+func explorationEnabled(request RecommendationRequest) bool {
+	if scoringOnly(request) {
+		return false
+	}
+	if enabled, ok := request.Parameters["exploration"].(bool); ok {
+		return enabled
+	}
+	return true
+}
+
+// 曝光日志中推荐流水线最终列表的记录策略名称
+const pipelineAlgorithm AlgorithmType = "pipeline"
+
+// 请求参数 ParameterScoringOnly 为 true 时结果只用于上层打分
+func scoringOnly(request RecommendationRequest) bool {
+	only, _ := request.Parameters[ParameterScoringOnly].(bool)
+	return only
+}
+
 // 设置引擎配置
 func (m *RecommendationEngineManager) SetConfig(config *EngineConfig) {
 	m.mu.Lock()
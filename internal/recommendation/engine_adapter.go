@@ -0,0 +1,171 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/dataprocessing"
+)
+
+// 默认推荐数量
+const defaultRecommendationLimit = 10
+
+// 行为类型对应的隐式评分
+var behaviorRatings = map[datacollection.UserBehaviorType]float64{
+	datacollection.BehaviorView:     0.5,
+	datacollection.BehaviorClick:    1.0,
+	datacollection.BehaviorShare:    2.0,
+	datacollection.BehaviorFavorite: 3.0,
+	datacollection.BehaviorPurchase: 5.0,
+}
+
+// 模型更新数据
+type ModelUpdate struct {
+	Behaviors []datacollection.UserBehavior // 用户行为
+	Items     []datacollection.ItemData     // 物品数据
+	Users     []datacollection.UserData     // 用户画像数据
+
+	ProcessedItems []dataprocessing.ProcessedItemData // 已提取特征向量的物品数据
+}
+
+// 解析模型更新数据，支持 ModelUpdate、用户行为、物品数据、用户数据和处理后的物品数据（单条或切片）
+func parseModelUpdate(data interface{}) (*ModelUpdate, error) {
+	switch d := data.(type) {
+	case ModelUpdate:
+		return &d, nil
+	case *ModelUpdate:
+		return d, nil
+	case datacollection.UserBehavior:
+		return &ModelUpdate{Behaviors: []datacollection.UserBehavior{d}}, nil
+	case []datacollection.UserBehavior:
+		return &ModelUpdate{Behaviors: d}, nil
+	case datacollection.ItemData:
+		return &ModelUpdate{Items: []datacollection.ItemData{d}}, nil
+	case []datacollection.ItemData:
+		return &ModelUpdate{Items: d}, nil
+	case datacollection.UserData:
+		return &ModelUpdate{Users: []datacollection.UserData{d}}, nil
+	case []datacollection.UserData:
+		return &ModelUpdate{Users: d}, nil
+	case dataprocessing.ProcessedItemData:
+		return &ModelUpdate{ProcessedItems: []dataprocessing.ProcessedItemData{d}}, nil
+	case []dataprocessing.ProcessedItemData:
+		return &ModelUpdate{ProcessedItems: d}, nil
+	default:
+		return nil, &RecommendationError{Message: fmt.Sprintf("不支持的模型更新数据类型: %T", data)}
+	}
+}
+
+// 将用户行为转换为隐式评分，评分行为直接使用行为数值
+func behaviorToRating(behavior datacollection.UserBehavior) float64 {
+	if behavior.Behavior == datacollection.BehaviorRating {
+		return behavior.Value
+	}
+	if rating, exists := behaviorRatings[behavior.Behavior]; exists {
+		return rating
+	}
+	return 1.0
+}
+
+// 将反馈转换为评分
+func feedbackToRating(feedback interface{}) (float64, error) {
+	parsed, err := ParseFeedback("", "", feedback)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Rating(), nil
+}
+
+// 将反馈转换为[0,1]的奖励，按购买或满分评分归一化
+func feedbackReward(feedback interface{}) float64 {
+	rating, err := feedbackToRating(feedback)
+	if err != nil || rating <= 0 {
+		return 0
+	}
+	return math.Min(rating/behaviorRatings[datacollection.BehaviorPurchase], 1)
+}
+
+// 从物品数据中提取标签和数值特征
+func extractItemContent(item datacollection.ItemData) ([]string, map[string]float64) {
+	tags := make([]string, 0)
+	features := make(map[string]float64)
+
+	for key, value := range item.Features {
+		switch v := value.(type) {
+		case string:
+			// 类别型特征使用独热编码，同时作为关键词
+			features[key+":"+strings.ToLower(v)] = 1.0
+			tags = append(tags, v)
+		case []string:
+			tags = append(tags, v...)
+		case []interface{}:
+			for _, tag := range v {
+				if s, ok := tag.(string); ok {
+					tags = append(tags, s)
+				}
+			}
+		default:
+			// 数值型特征取对数压缩量纲
+			if number, ok := toFloat64(v); ok && number >= 0 {
+				features[key] = math.Log1p(number)
+			}
+		}
+	}
+
+	return tags, features
+}
+
+// 确定推荐数量
+func resolveLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRecommendationLimit
+	}
+	return limit
+}
+
+// 依次生成批量推荐
+func recommendBatch(ctx context.Context, engine RecommendationEngine, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	responses := make([]*RecommendationResponse, 0, len(requests))
+	for _, request := range requests {
+		response, err := engine.Recommend(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// 转换为浮点数
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// 转换为整数
+func toInt(value interface{}) (int, bool) {
+	if v, ok := toFloat64(value); ok {
+		return int(v), true
+	}
+	return 0, false
+}
+
+// 参数错误
+func invalidParameterError(name string, value interface{}) error {
+	return &RecommendationError{Message: fmt.Sprintf("无效的算法参数 %s: %v", name, value)}
+}
@@ -13,6 +13,12 @@ const (
 	AlgorithmHybridFiltering        AlgorithmType = "hybrid_filtering"        // 混合过滤
 	AlgorithmDeepLearning           AlgorithmType = "deep_learning"           // 深度学习
 	AlgorithmRuleBased              AlgorithmType = "rule_based"              // 基于规则
+	AlgorithmMatrixFactorization    AlgorithmType = "matrix_factorization"    // 矩阵分解
+	AlgorithmBPR                    AlgorithmType = "bpr"                     // 贝叶斯个性化排序
+	AlgorithmSessionBased           AlgorithmType = "session_based"           // 基于会话
+	AlgorithmPopularity             AlgorithmType = "popularity"              // 趋势热度
+	AlgorithmAssociationRules       AlgorithmType = "association_rules"       // 关联规则
+	AlgorithmColdStart              AlgorithmType = "cold_start"              // 冷启动
 )
 
 // 推荐场景
@@ -26,6 +32,19 @@ const (
 	ScenarioEmailMarketing RecommendationScenario = "email_marketing" // 邮件营销推荐
 )
 
+// 判断推荐场景是否有效
+func (s RecommendationScenario) IsValid() bool {
+	switch s {
+	case ScenarioHomePage, ScenarioProductDetail, ScenarioShoppingCart, ScenarioSearchResult, ScenarioEmailMarketing:
+		return true
+	default:
+		return false
+	}
+}
+
+// 请求参数：为 true 时结果只供上层流水线打分，不直接展示给用户，管理器不探索也不记录曝光
+const ParameterScoringOnly = "scoring_only"
+
 // 推荐请求
 type RecommendationRequest struct {
 	UserID     string                 // 用户ID
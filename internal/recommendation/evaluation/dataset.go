@@ -0,0 +1,101 @@
+package evaluation
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+)
+
+// 行为日志中的一行
+type behaviorRecord struct {
+	UserID    string                 `json:"user_id"`
+	ItemID    string                 `json:"item_id"`
+	Behavior  string                 `json:"behavior"`
+	Value     float64                `json:"value"`
+	Timestamp time.Time              `json:"timestamp"`
+	Context   map[string]interface{} `json:"context"`
+}
+
+// 物品数据中的一行
+type itemRecord struct {
+	ItemID      string                 `json:"item_id"`
+	Category    string                 `json:"category"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Features    map[string]interface{} `json:"features"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// LoadBehaviors 读取 JSON Lines 格式的行为日志，每行包含 user_id、item_id、behavior、value、timestamp（RFC3339）与 context
+func LoadBehaviors(r io.Reader) ([]datacollection.UserBehavior, error) {
+	var behaviors []datacollection.UserBehavior
+	err := readJSONLines(r, func(line int, data []byte) error {
+		var record behaviorRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("解析行为日志第 %d 行失败: %w", line, err)
+		}
+		if record.UserID == "" || record.ItemID == "" {
+			return fmt.Errorf("行为日志第 %d 行缺少 user_id 或 item_id", line)
+		}
+		behaviors = append(behaviors, datacollection.UserBehavior{
+			UserID:    record.UserID,
+			ItemID:    record.ItemID,
+			Behavior:  datacollection.UserBehaviorType(record.Behavior),
+			Value:     record.Value,
+			Timestamp: record.Timestamp,
+			Context:   record.Context,
+		})
+		return nil
+	})
+	return behaviors, err
+}
+
+// LoadItems 读取 JSON Lines 格式的物品数据，每行包含 item_id、category、title、description、features 与 metadata
+func LoadItems(r io.Reader) ([]datacollection.ItemData, error) {
+	var items []datacollection.ItemData
+	err := readJSONLines(r, func(line int, data []byte) error {
+		var record itemRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("解析物品数据第 %d 行失败: %w", line, err)
+		}
+		if record.ItemID == "" {
+			return fmt.Errorf("物品数据第 %d 行缺少 item_id", line)
+		}
+		items = append(items, datacollection.ItemData{
+			ItemID:      record.ItemID,
+			Category:    record.Category,
+			Title:       record.Title,
+			Description: record.Description,
+			Features:    record.Features,
+			Metadata:    record.Metadata,
+		})
+		return nil
+	})
+	return items, err
+}
+
+// 逐行读取 JSON Lines，跳过空行
+func readJSONLines(r io.Reader, handle func(line int, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := []byte(strings.TrimSpace(scanner.Text()))
+		if len(data) == 0 {
+			continue
+		}
+		if err := handle(line, data); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取数据失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Config 离线评估配置
+type Config struct {
+	Split      SplitConfig
+	K          int                            // 推荐列表长度
+	Algorithms []recommendation.AlgorithmType // 评估的算法，为空时评估所有注册的算法
+	MaxUsers   int                            // 测试用户上限，0表示不限制
+}
+
+// DefaultConfig 默认评估配置：按时间切分出最近20%的行为，评估前10个推荐
+func DefaultConfig() Config {
+	return Config{
+		Split: SplitConfig{
+			Method:    SplitTemporal,
+			TestRatio: 0.2,
+			Seed:      42,
+		},
+		K: 10,
+	}
+}
+
+// Dataset 评估数据
+type Dataset struct {
+	Behaviors []datacollection.UserBehavior
+	Items     []datacollection.ItemData // 物品库，用于内容特征与覆盖率
+	Users     []datacollection.UserData // 用户画像，用于冷启动
+}
+
+// EngineFactory 创建待评估的推荐引擎管理器，每次评估使用新的实例
+type EngineFactory func() *recommendation.RecommendationEngineManager
+
+// Evaluator 离线评估器
+type Evaluator struct {
+	config  Config
+	factory EngineFactory
+	log     *logrus.Logger
+}
+
+// NewEvaluator 创建离线评估器，factory 为空时使用默认注册的算法引擎
+func NewEvaluator(config Config, factory EngineFactory, log *logrus.Logger) *Evaluator {
+	if log == nil {
+		log = logrus.New()
+	}
+	if factory == nil {
+		factory = func() *recommendation.RecommendationEngineManager {
+			return recommendation.NewRecommendationEngineManager(log)
+		}
+	}
+	if config.K <= 0 {
+		config.K = DefaultConfig().K
+	}
+
+	return &Evaluator{
+		config:  config,
+		factory: factory,
+		log:     log,
+	}
+}
+
+// Evaluate 切分行为日志，用训练集训练各算法引擎，并在测试集上计算各算法的指标
+func (e *Evaluator) Evaluate(ctx context.Context, dataset Dataset) ([]models.AlgorithmMetrics, error) {
+	train, test, err := Split(dataset.Behaviors, e.config.Split)
+	if err != nil {
+		return nil, err
+	}
+	if len(train) == 0 || len(test) == 0 {
+		return nil, fmt.Errorf("训练集或测试集为空: 训练集 %d 条，测试集 %d 条", len(train), len(test))
+	}
+
+	manager := e.factory()
+	defer manager.Close()
+	update := recommendation.ModelUpdate{Behaviors: train, Items: dataset.Items, Users: dataset.Users}
+	if err := manager.UpdateModel(ctx, update); err != nil {
+		// 个别引擎训练失败时其余引擎仍可评估
+		e.log.WithError(err).Warn("部分算法引擎训练失败")
+	}
+
+	relevant := relevantItems(train, test)
+	users := make([]string, 0, len(relevant))
+	for userID := range relevant {
+		users = append(users, userID)
+	}
+	sort.Strings(users)
+	if e.config.MaxUsers > 0 && len(users) > e.config.MaxUsers {
+		users = users[:e.config.MaxUsers]
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("测试集中没有正反馈行为")
+	}
+
+	algorithms := e.config.Algorithms
+	if len(algorithms) == 0 {
+		if algorithms, err = manager.GetAvailableAlgorithms(ctx); err != nil {
+			return nil, err
+		}
+		sort.Slice(algorithms, func(i, j int) bool { return algorithms[i] < algorithms[j] })
+	}
+
+	stats := newItemStatistics(train, dataset.Items)
+	catalogSize := catalogSize(dataset)
+	period := timePeriod(test)
+
+	results := make([]models.AlgorithmMetrics, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		engine, exists := manager.GetEngine(algorithm)
+		if !exists {
+			return nil, fmt.Errorf("算法未注册: %s", algorithm)
+		}
+
+		metrics := e.evaluateAlgorithm(ctx, algorithm, engine, users, relevant, stats, catalogSize)
+		metrics.TimePeriod = period
+		metrics.Metadata["split"] = string(e.config.Split.Method)
+		metrics.Metadata["train_size"] = len(train)
+		metrics.Metadata["test_size"] = len(test)
+		results = append(results, metrics)
+	}
+	return results, nil
+}
+
+// 评估单个算法
+func (e *Evaluator) evaluateAlgorithm(ctx context.Context, algorithm recommendation.AlgorithmType, engine recommendation.RecommendationEngine, users []string, relevant map[string]map[string]bool, stats *itemStatistics, catalogSize int) models.AlgorithmMetrics {
+	started := time.Now()
+	k := e.config.K
+
+	var ranking rankingMetrics
+	var diversity, novelty, scoreSum float64
+	scoreCount, failures, emptyLists := 0, 0, 0
+	recommendedItems := make(map[string]bool)
+
+	for _, userID := range users {
+		response, err := engine.Recommend(ctx, recommendation.RecommendationRequest{
+			UserID:    userID,
+			Algorithm: algorithm,
+			Limit:     k,
+		})
+		if err != nil {
+			failures++
+			continue
+		}
+
+		recommended := make([]string, 0, k)
+		for _, result := range response.Recommendations {
+			if len(recommended) == k {
+				break
+			}
+			recommended = append(recommended, result.ItemID)
+			recommendedItems[result.ItemID] = true
+			scoreSum += result.Score
+			scoreCount++
+		}
+		if len(recommended) == 0 {
+			emptyLists++
+			continue
+		}
+
+		ranking.add(computeRankingMetrics(recommended, relevant[userID], k))
+		diversity += stats.intraListDiversity(recommended)
+		novelty += stats.novelty(recommended)
+	}
+
+	ranking = ranking.average(len(users))
+	metrics := models.AlgorithmMetrics{
+		Algorithm:    string(algorithm),
+		Precision:    ranking.precision,
+		Recall:       ranking.recall,
+		F1Score:      f1Score(ranking.precision, ranking.recall),
+		NDCG:         ranking.ndcg,
+		MAP:          ranking.ap,
+		MRR:          ranking.rr,
+		HitRate:      ranking.hit,
+		CalculatedAt: time.Now(),
+		Metadata: map[string]interface{}{
+			"k":           k,
+			"users":       len(users),
+			"errors":      failures,
+			"empty_lists": emptyLists,
+			"duration_ms": time.Since(started).Milliseconds(),
+		},
+	}
+	if catalogSize > 0 {
+		metrics.Coverage = float64(len(recommendedItems)) / float64(catalogSize)
+	}
+	if served := len(users) - failures - emptyLists; served > 0 {
+		metrics.Diversity = diversity / float64(served)
+		metrics.Novelty = novelty / float64(served)
+	}
+	if scoreCount > 0 {
+		metrics.AverageScore = scoreSum / float64(scoreCount)
+	}
+
+	e.log.WithFields(logrus.Fields{
+		"algorithm": algorithm,
+		"precision": metrics.Precision,
+		"recall":    metrics.Recall,
+		"ndcg":      metrics.NDCG,
+	}).Info("完成算法离线评估")
+	return metrics
+}
+
+// 测试集中每个用户的相关物品：正反馈且不在该用户训练集中的物品
+func relevantItems(train []datacollection.UserBehavior, test []datacollection.UserBehavior) map[string]map[string]bool {
+	seen := make(map[string]map[string]bool)
+	for _, behavior := range train {
+		if seen[behavior.UserID] == nil {
+			seen[behavior.UserID] = make(map[string]bool)
+		}
+		seen[behavior.UserID][behavior.ItemID] = true
+	}
+
+	relevant := make(map[string]map[string]bool)
+	for _, behavior := range test {
+		feedback, err := recommendation.ParseFeedback(behavior.UserID, behavior.ItemID, behavior)
+		if err != nil || feedback.Rating() <= 0 || seen[behavior.UserID][behavior.ItemID] {
+			continue
+		}
+		if relevant[behavior.UserID] == nil {
+			relevant[behavior.UserID] = make(map[string]bool)
+		}
+		relevant[behavior.UserID][behavior.ItemID] = true
+	}
+	return relevant
+}
+
+// 统计训练集上的物品交互用户与物品类别
+func newItemStatistics(train []datacollection.UserBehavior, items []datacollection.ItemData) *itemStatistics {
+	stats := &itemStatistics{
+		users:      make(map[string]map[string]bool),
+		categories: make(map[string]string, len(items)),
+	}
+	userSet := make(map[string]bool)
+	for _, behavior := range train {
+		if stats.users[behavior.ItemID] == nil {
+			stats.users[behavior.ItemID] = make(map[string]bool)
+		}
+		stats.users[behavior.ItemID][behavior.UserID] = true
+		userSet[behavior.UserID] = true
+	}
+	stats.userCount = len(userSet)
+
+	for _, item := range items {
+		stats.categories[item.ItemID] = item.Category
+	}
+	return stats
+}
+
+// 物品库大小：物品数据与行为日志中出现的物品总数
+func catalogSize(dataset Dataset) int {
+	catalog := make(map[string]bool, len(dataset.Items))
+	for _, item := range dataset.Items {
+		catalog[item.ItemID] = true
+	}
+	for _, behavior := range dataset.Behaviors {
+		catalog[behavior.ItemID] = true
+	}
+	return len(catalog)
+}
+
+// 测试集的时间范围
+func timePeriod(test []datacollection.UserBehavior) string {
+	var start, end time.Time
+	for _, behavior := range test {
+		if behavior.Timestamp.IsZero() {
+			continue
+		}
+		if start.IsZero() || behavior.Timestamp.Before(start) {
+			start = behavior.Timestamp
+		}
+		if behavior.Timestamp.After(end) {
+			end = behavior.Timestamp
+		}
+	}
+	if start.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+}
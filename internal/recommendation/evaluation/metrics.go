@@ -0,0 +1,152 @@
+package evaluation
+
+import (
+	"math"
+)
+
+// 单个用户推荐列表的排序指标
+type rankingMetrics struct {
+	precision float64
+	recall    float64
+	ndcg      float64
+	ap        float64
+	rr        float64
+	hit       float64
+}
+
+// 计算推荐列表前 k 个位置的排序指标，relevant 为测试集中的相关物品
+func computeRankingMetrics(recommended []string, relevant map[string]bool, k int) rankingMetrics {
+	if len(recommended) > k {
+		recommended = recommended[:k]
+	}
+
+	var metrics rankingMetrics
+	hits := 0
+	dcg := 0.0
+	precisionSum := 0.0
+	for i, itemID := range recommended {
+		if !relevant[itemID] {
+			continue
+		}
+		hits++
+		dcg += 1 / math.Log2(float64(i+2))
+		precisionSum += float64(hits) / float64(i+1)
+		if metrics.rr == 0 {
+			metrics.rr = 1 / float64(i+1)
+		}
+	}
+
+	idcg := 0.0
+	for i := 0; i < min(len(relevant), k); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+
+	metrics.precision = float64(hits) / float64(k)
+	if len(relevant) > 0 {
+		metrics.recall = float64(hits) / float64(len(relevant))
+		metrics.ap = precisionSum / float64(min(len(relevant), k))
+	}
+	if idcg > 0 {
+		metrics.ndcg = dcg / idcg
+	}
+	if hits > 0 {
+		metrics.hit = 1
+	}
+	return metrics
+}
+
+// 累加
+func (m *rankingMetrics) add(other rankingMetrics) {
+	m.precision += other.precision
+	m.recall += other.recall
+	m.ndcg += other.ndcg
+	m.ap += other.ap
+	m.rr += other.rr
+	m.hit += other.hit
+}
+
+// 按用户数求平均
+func (m rankingMetrics) average(users int) rankingMetrics {
+	if users == 0 {
+		return rankingMetrics{}
+	}
+	n := float64(users)
+	return rankingMetrics{
+		precision: m.precision / n,
+		recall:    m.recall / n,
+		ndcg:      m.ndcg / n,
+		ap:        m.ap / n,
+		rr:        m.rr / n,
+		hit:       m.hit / n,
+	}
+}
+
+// F1值
+func f1Score(precision float64, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+// 训练集上的物品统计，用于计算列表多样性与新颖性
+type itemStatistics struct {
+	users      map[string]map[string]bool // 物品-交互用户
+	categories map[string]string          // 物品-类别
+	userCount  int
+}
+
+// 物品间的距离：双方都有类别时按类别是否相同，否则为1减去交互用户集合的余弦相似度
+func (s *itemStatistics) distance(itemID1 string, itemID2 string) float64 {
+	category1, exists1 := s.categories[itemID1]
+	category2, exists2 := s.categories[itemID2]
+	if exists1 && exists2 && category1 != "" && category2 != "" {
+		if category1 == category2 {
+			return 0
+		}
+		return 1
+	}
+
+	users1, users2 := s.users[itemID1], s.users[itemID2]
+	if len(users1) == 0 || len(users2) == 0 {
+		return 1
+	}
+	common := 0
+	for userID := range users1 {
+		if users2[userID] {
+			common++
+		}
+	}
+	return 1 - float64(common)/math.Sqrt(float64(len(users1)*len(users2)))
+}
+
+// 列表内多样性：推荐物品两两距离的平均值
+func (s *itemStatistics) intraListDiversity(recommended []string) float64 {
+	if len(recommended) < 2 {
+		return 0
+	}
+
+	total := 0.0
+	pairs := 0
+	for i := 0; i < len(recommended); i++ {
+		for j := i + 1; j < len(recommended); j++ {
+			total += s.distance(recommended[i], recommended[j])
+			pairs++
+		}
+	}
+	return total / float64(pairs)
+}
+
+// 新颖性：推荐物品自信息 -log2(物品流行度) 的平均值，流行度做加一平滑
+func (s *itemStatistics) novelty(recommended []string) float64 {
+	if len(recommended) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, itemID := range recommended {
+		popularity := float64(len(s.users[itemID])+1) / float64(s.userCount+1)
+		total -= math.Log2(popularity)
+	}
+	return total / float64(len(recommended))
+}
@@ -0,0 +1,46 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+)
+
+// WriteJSON 以 JSON 输出评估结果
+func WriteJSON(w io.Writer, results []models.AlgorithmMetrics) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("输出评估结果失败: %w", err)
+	}
+	return nil
+}
+
+// WriteTable 以表格输出评估结果
+func WriteTable(w io.Writer, results []models.AlgorithmMetrics) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "algorithm\tprecision\trecall\tf1\tndcg\tmap\tmrr\thit_rate\tcoverage\tdiversity\tnovelty\terrors\t")
+	for _, result := range results {
+		fmt.Fprintf(table, "%s\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%v\t\n",
+			result.Algorithm,
+			result.Precision,
+			result.Recall,
+			result.F1Score,
+			result.NDCG,
+			result.MAP,
+			result.MRR,
+			result.HitRate,
+			result.Coverage,
+			result.Diversity,
+			result.Novelty,
+			result.Metadata["errors"],
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("输出评估结果失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Package evaluation 推荐算法离线评估：切分行为日志、训练各算法引擎并计算排序与列表指标
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+)
+
+// SplitMethod 行为日志切分方式
+type SplitMethod string
+
+const (
+	SplitRandom      SplitMethod = "random"        // 按比例随机抽取测试行为
+	SplitTemporal    SplitMethod = "temporal"      // 按时间切分，最近的行为作为测试集
+	SplitLeaveOneOut SplitMethod = "leave_one_out" // 每个用户最近的一条行为作为测试集
+)
+
+var ErrUnsupportedSplit = errors.New("不支持的切分方式")
+
+// SplitConfig 切分配置
+type SplitConfig struct {
+	Method    SplitMethod
+	TestRatio float64 // 随机与按时间切分时测试集的比例
+	Seed      int64   // 随机切分的随机种子
+}
+
+// Split 将行为日志切分为训练集与测试集
+func Split(behaviors []datacollection.UserBehavior, config SplitConfig) ([]datacollection.UserBehavior, []datacollection.UserBehavior, error) {
+	if config.Method != SplitLeaveOneOut && (config.TestRatio <= 0 || config.TestRatio >= 1) {
+		return nil, nil, fmt.Errorf("测试集比例必须在(0,1)之间: %v", config.TestRatio)
+	}
+
+	switch config.Method {
+	case SplitRandom:
+		return splitRandom(behaviors, config.TestRatio, config.Seed)
+	case SplitTemporal:
+		return splitTemporal(behaviors, config.TestRatio)
+	case SplitLeaveOneOut:
+		return splitLeaveOneOut(behaviors)
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedSplit, config.Method)
+	}
+}
+
+// 随机切分
+func splitRandom(behaviors []datacollection.UserBehavior, testRatio float64, seed int64) ([]datacollection.UserBehavior, []datacollection.UserBehavior, error) {
+	rng := rand.New(rand.NewSource(seed))
+
+	train := make([]datacollection.UserBehavior, 0, len(behaviors))
+	test := make([]datacollection.UserBehavior, 0, int(float64(len(behaviors))*testRatio)+1)
+	for _, behavior := range behaviors {
+		if rng.Float64() < testRatio {
+			test = append(test, behavior)
+		} else {
+			train = append(train, behavior)
+		}
+	}
+	return train, test, nil
+}
+
+// 按时间切分：所有行为按时间排序，最后 testRatio 比例的行为作为测试集
+func splitTemporal(behaviors []datacollection.UserBehavior, testRatio float64) ([]datacollection.UserBehavior, []datacollection.UserBehavior, error) {
+	sorted := sortedByTime(behaviors)
+	cut := int(float64(len(sorted)) * (1 - testRatio))
+	return sorted[:cut], sorted[cut:], nil
+}
+
+// 留一切分：行为数不少于2的用户，最近的一条行为作为测试集
+func splitLeaveOneOut(behaviors []datacollection.UserBehavior) ([]datacollection.UserBehavior, []datacollection.UserBehavior, error) {
+	sorted := sortedByTime(behaviors)
+
+	counts := make(map[string]int)
+	for _, behavior := range sorted {
+		counts[behavior.UserID]++
+	}
+
+	train := make([]datacollection.UserBehavior, 0, len(sorted))
+	test := make([]datacollection.UserBehavior, 0, len(counts))
+	seen := make(map[string]int)
+	for _, behavior := range sorted {
+		seen[behavior.UserID]++
+		if counts[behavior.UserID] >= 2 && seen[behavior.UserID] == counts[behavior.UserID] {
+			test = append(test, behavior)
+		} else {
+			train = append(train, behavior)
+		}
+	}
+	return train, test, nil
+}
+
+// 按时间升序排列的行为副本
+func sortedByTime(behaviors []datacollection.UserBehavior) []datacollection.UserBehavior {
+	sorted := append([]datacollection.UserBehavior(nil), behaviors...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	return sorted
+}
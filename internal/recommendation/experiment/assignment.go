@@ -0,0 +1,138 @@
+// Package experiment A/B实验运行时：按用户哈希分桶分流、记录曝光、按组统计反馈指标并做显著性检验
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+)
+
+// 实验状态，与 models.ABTest.Status 的取值一致
+const (
+	StatusActive    = "active"
+	StatusPaused    = "paused"
+	StatusCompleted = "completed"
+)
+
+var (
+	// ErrInvalidTest 实验定义无效
+	ErrInvalidTest = errors.New("实验定义无效")
+	// ErrTestNotFound 实验不存在
+	ErrTestNotFound = errors.New("实验不存在")
+)
+
+// Assignment 用户在实验中的分组，Arm 为该组使用的算法
+type Assignment struct {
+	ExperimentID string
+	Arm          string
+}
+
+// Bucket 用户在实验中的桶位置：对"盐:用户ID"做FNV-1a哈希并映射到[0,1)，同一用户在同一实验中的位置固定
+func Bucket(userID string, salt string) float64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(salt))
+	hash.Write([]byte{':'})
+	hash.Write([]byte(userID))
+	return float64(hash.Sum64()>>11) / float64(1<<53)
+}
+
+// 实验的哈希盐：Metadata 中的 salt，未设置时使用实验ID
+func testSalt(test models.ABTest) string {
+	if salt, ok := test.Metadata["salt"].(string); ok && salt != "" {
+		return salt
+	}
+	return test.ID
+}
+
+// 实验限定的场景：Metadata 中的 scenario，为空时不限场景
+func testScenario(test models.ABTest) string {
+	scenario, _ := test.Metadata["scenario"].(string)
+	return scenario
+}
+
+// 各组的流量比例：未设置 TrafficSplit 时各组均分全部流量
+func trafficSplit(test models.ABTest) []float64 {
+	split := make([]float64, len(test.Algorithms))
+	total := 0.0
+	for _, value := range test.TrafficSplit {
+		total += value
+	}
+	for i, arm := range test.Algorithms {
+		if total == 0 {
+			split[i] = 1 / float64(len(test.Algorithms))
+		} else {
+			split[i] = test.TrafficSplit[arm]
+		}
+	}
+	return split
+}
+
+// 按桶位置分组：各组依 Algorithms 的顺序占据连续区间，比例之和小于1时剩余用户不进入实验
+func assignArm(test models.ABTest, userID string) (string, bool) {
+	bucket := Bucket(userID, testSalt(test))
+	upper := 0.0
+	for i, share := range trafficSplit(test) {
+		upper += share
+		if bucket < upper {
+			return test.Algorithms[i], true
+		}
+	}
+	return "", false
+}
+
+// 实验在该时刻是否运行中：状态为 active 且在开始与结束时间之间，时间为零值时不限制
+func running(test models.ABTest, now time.Time) bool {
+	if test.Status != StatusActive {
+		return false
+	}
+	if !test.StartTime.IsZero() && now.Before(test.StartTime) {
+		return false
+	}
+	if !test.EndTime.IsZero() && !now.Before(test.EndTime) {
+		return false
+	}
+	return true
+}
+
+// 校验实验定义
+func validateTest(test models.ABTest) error {
+	if test.ID == "" {
+		return fmt.Errorf("%w: 实验ID为空", ErrInvalidTest)
+	}
+	if len(test.Algorithms) < 2 {
+		return fmt.Errorf("%w: 实验 %s 至少需要两个算法", ErrInvalidTest, test.ID)
+	}
+
+	arms := make(map[string]bool, len(test.Algorithms))
+	for _, arm := range test.Algorithms {
+		if arm == "" || arms[arm] {
+			return fmt.Errorf("%w: 实验 %s 的算法为空或重复", ErrInvalidTest, test.ID)
+		}
+		arms[arm] = true
+	}
+
+	total := 0.0
+	for arm, share := range test.TrafficSplit {
+		if !arms[arm] {
+			return fmt.Errorf("%w: 实验 %s 的流量分配包含未知算法 %s", ErrInvalidTest, test.ID, arm)
+		}
+		if share < 0 || math.IsNaN(share) {
+			return fmt.Errorf("%w: 实验 %s 的流量比例无效: %s", ErrInvalidTest, test.ID, arm)
+		}
+		total += share
+	}
+	if total > 1+1e-9 {
+		return fmt.Errorf("%w: 实验 %s 的流量比例之和大于1", ErrInvalidTest, test.ID)
+	}
+
+	switch test.Status {
+	case StatusActive, StatusPaused, StatusCompleted:
+	default:
+		return fmt.Errorf("%w: 实验 %s 的状态不支持: %s", ErrInvalidTest, test.ID, test.Status)
+	}
+	return nil
+}
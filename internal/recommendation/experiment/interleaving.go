@@ -0,0 +1,345 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// InterleavingConfig 交错对比配置
+type InterleavingConfig struct {
+	ID                string
+	Algorithms        [2]string     // 参与对比的两个算法
+	Traffic           float64       // 参与交错的用户比例，按用户哈希分桶
+	Scenario          string        // 限定的推荐场景，为空时不限场景
+	AttributionWindow time.Duration // 展示后该时间内的点击计入该次展示
+	Alpha             float64       // 符号检验的显著性水平
+	MinComparisons    int           // 至少有胜负的展示数，未达到时不判定胜者
+}
+
+// DefaultInterleavingConfig 默认交错对比配置：全部流量，符号检验显著性水平0.05
+func DefaultInterleavingConfig() InterleavingConfig {
+	return InterleavingConfig{
+		Traffic:           1,
+		AttributionWindow: 30 * time.Minute,
+		Alpha:             0.05,
+		MinComparisons:    50,
+	}
+}
+
+// Slot 交错列表中的位置
+type Slot struct {
+	ItemID     string
+	Team       string  // 选出该物品的算法
+	SourceRank int     // 物品在来源算法列表中的排名，从1开始
+	Propensity float64 // 在已选出前面位置的条件下该位置选中此物品的概率
+}
+
+// InterleavingResult 交错对比结果
+type InterleavingResult struct {
+	ID          string   `json:"id"`
+	Algorithms  []string `json:"algorithms"`
+	Impressions int      `json:"impressions"` // 交错列表的展示次数
+	Clicked     int      `json:"clicked"`     // 有点击的展示次数
+	Wins        []int    `json:"wins"`        // 各算法获得更多点击的展示次数
+	Ties        int      `json:"ties"`        // 两个算法点击数相同的有点击展示次数
+	Preference  float64  `json:"preference"`  // 第一个算法的偏好度减0.5，平局各计一半，为正时偏好第一个算法
+	PValue      float64  `json:"p_value"`     // 胜负次数的双侧符号检验p值
+	Winner      string   `json:"winner"`
+}
+
+// Interleaving 团队选拔交错对比：两个算法轮流选出各自排名最高且未入选的物品组成一个列表，
+// 按点击落在哪个算法选出的物品上判断用户更偏好哪个算法
+type Interleaving struct {
+	mu          sync.Mutex
+	config      InterleavingConfig
+	rng         *rand.Rand
+	open        map[*interleavedImpression]bool   // 归因窗口内的展示
+	pending     map[string]*interleavedImpression // 用户-物品到最近一次展示
+	lastSweep   time.Time
+	impressions int
+	wins        [2]int // 已结算展示中各算法获胜的次数
+	ties        int    // 已结算展示中有点击的平局次数
+}
+
+// 交错展示
+type interleavedImpression struct {
+	userID   string
+	teams    map[string]int // 物品-选出该物品的算法下标
+	clicked  map[string]bool
+	clicks   [2]int
+	servedAt time.Time
+}
+
+// NewInterleaving 创建交错对比
+func NewInterleaving(config InterleavingConfig) (*Interleaving, error) {
+	if config.Algorithms[0] == "" || config.Algorithms[1] == "" || config.Algorithms[0] == config.Algorithms[1] {
+		return nil, fmt.Errorf("%w: 交错对比需要两个不同的算法", ErrInvalidTest)
+	}
+	if config.Traffic < 0 || config.Traffic > 1 {
+		return nil, fmt.Errorf("%w: 交错对比的流量比例无效: %v", ErrInvalidTest, config.Traffic)
+	}
+
+	defaults := DefaultInterleavingConfig()
+	if config.ID == "" {
+		config.ID = config.Algorithms[0] + "_vs_" + config.Algorithms[1]
+	}
+	if config.AttributionWindow <= 0 {
+		config.AttributionWindow = defaults.AttributionWindow
+	}
+	if config.Alpha <= 0 || config.Alpha >= 1 {
+		config.Alpha = defaults.Alpha
+	}
+
+	return &Interleaving{
+		config:  config,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		open:    make(map[*interleavedImpression]bool),
+		pending: make(map[string]*interleavedImpression),
+	}, nil
+}
+
+// ID 交错对比的ID
+func (i *Interleaving) ID() string {
+	return i.config.ID
+}
+
+// Algorithms 参与对比的两个算法
+func (i *Interleaving) Algorithms() [2]string {
+	return i.config.Algorithms
+}
+
+// Covers 用户在该场景下是否参与交错对比
+func (i *Interleaving) Covers(userID string, scenario string) bool {
+	if i.config.Scenario != "" && i.config.Scenario != scenario {
+		return false
+	}
+	return Bucket(userID, i.config.ID) < i.config.Traffic
+}
+
+// Interleave 团队选拔交错两个算法的列表，lists 与 Algorithms 的顺序一致，返回至多 limit 个位置
+func (i *Interleaving) Interleave(lists [2][]string, limit int) []Slot {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return teamDraft(lists, i.config.Algorithms, limit, i.rng)
+}
+
+// Expose 记录展示给用户的交错列表，slots 为截断与过滤后实际展示的位置
+func (i *Interleaving) Expose(userID string, slots []Slot) {
+	now := time.Now()
+	i.expire(now)
+
+	shown := &interleavedImpression{
+		userID:   userID,
+		teams:    make(map[string]int, len(slots)),
+		clicked:  make(map[string]bool),
+		servedAt: now,
+	}
+	for _, slot := range slots {
+		if team := i.team(slot.Team); team >= 0 {
+			shown.teams[slot.ItemID] = team
+		}
+	}
+	if len(shown.teams) == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.impressions++
+	i.open[shown] = true
+	for itemID := range shown.teams {
+		i.pending[impressionKey(userID, itemID)] = shown
+	}
+}
+
+// RecordFeedback 将点击计入展示该物品的算法，reward 不大于0时不计入，同一展示中同一物品只计一次，返回是否计入
+func (i *Interleaving) RecordFeedback(userID string, itemID string, reward float64) bool {
+	if reward <= 0 {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	shown, exists := i.pending[impressionKey(userID, itemID)]
+	if !exists || time.Since(shown.servedAt) > i.config.AttributionWindow || shown.clicked[itemID] {
+		return false
+	}
+	shown.clicked[itemID] = true
+	shown.clicks[shown.teams[itemID]]++
+	return true
+}
+
+// Result 统计已结算与归因窗口内的展示，计算偏好度与符号检验
+func (i *Interleaving) Result() InterleavingResult {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	wins, ties := i.wins, i.ties
+	for shown := range i.open {
+		shown.tally(&wins, &ties)
+	}
+
+	result := InterleavingResult{
+		ID:          i.config.ID,
+		Algorithms:  []string{i.config.Algorithms[0], i.config.Algorithms[1]},
+		Impressions: i.impressions,
+		Clicked:     wins[0] + wins[1] + ties,
+		Wins:        []int{wins[0], wins[1]},
+		Ties:        ties,
+		PValue:      signTest(wins[0], wins[1]),
+	}
+	if result.Clicked > 0 {
+		result.Preference = (float64(wins[0])+float64(ties)/2)/float64(result.Clicked) - 0.5
+	}
+	if wins[0]+wins[1] >= i.config.MinComparisons && result.PValue < i.config.Alpha {
+		if wins[0] > wins[1] {
+			result.Winner = i.config.Algorithms[0]
+		} else {
+			result.Winner = i.config.Algorithms[1]
+		}
+	}
+	return result
+}
+
+// 算法在对比中的下标，不参与对比时为-1
+func (i *Interleaving) team(algorithm string) int {
+	for index, name := range i.config.Algorithms {
+		if name == algorithm {
+			return index
+		}
+	}
+	return -1
+}
+
+// 结算超出归因窗口的展示，按窗口的十分之一为间隔
+func (i *Interleaving) expire(now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if now.Sub(i.lastSweep) < i.config.AttributionWindow/10 {
+		return
+	}
+	i.lastSweep = now
+
+	for shown := range i.open {
+		if now.Sub(shown.servedAt) <= i.config.AttributionWindow {
+			continue
+		}
+		shown.tally(&i.wins, &i.ties)
+		delete(i.open, shown)
+		for itemID := range shown.teams {
+			key := impressionKey(shown.userID, itemID)
+			if i.pending[key] == shown {
+				delete(i.pending, key)
+			}
+		}
+	}
+}
+
+// 累计展示的胜负：点击多的算法获胜，有点击且点击数相同为平局，没有点击的展示不计
+func (s *interleavedImpression) tally(wins *[2]int, ties *int) {
+	switch {
+	case s.clicks[0] > s.clicks[1]:
+		wins[0]++
+	case s.clicks[1] > s.clicks[0]:
+		wins[1]++
+	case s.clicks[0] > 0:
+		*ties++
+	}
+}
+
+// 团队选拔：每轮由已选物品较少的算法先选，数量相同时随机决定，
+// 算法选出自己列表中排名最高且未入选的物品，一方列表用尽后由另一方继续选
+func teamDraft(lists [2][]string, algorithms [2]string, limit int, rng *rand.Rand) []Slot {
+	capacity := len(lists[0]) + len(lists[1])
+	if limit > 0 && limit < capacity {
+		capacity = limit
+	}
+
+	slots := make([]Slot, 0, capacity)
+	selected := make(map[string]bool, capacity)
+	cursors := [2]int{}
+	picks := [2]int{}
+
+	// 算法列表中下一个未入选物品的下标，列表用尽时为-1
+	peek := func(team int) int {
+		for cursors[team] < len(lists[team]) && selected[lists[team][cursors[team]]] {
+			cursors[team]++
+		}
+		if cursors[team] == len(lists[team]) {
+			return -1
+		}
+		return cursors[team]
+	}
+
+	for len(slots) < capacity {
+		next := [2]int{peek(0), peek(1)}
+		if next[0] < 0 && next[1] < 0 {
+			break
+		}
+
+		team, propensity := 0, 1.0
+		switch {
+		case next[0] < 0:
+			team = 1
+		case next[1] < 0:
+			team = 0
+		case picks[1] < picks[0]:
+			team = 1
+		case picks[0] == picks[1]:
+			team = rng.Intn(2)
+			// 两个算法的下一个物品不同时，该位置的物品由抛硬币决定
+			if lists[0][next[0]] != lists[1][next[1]] {
+				propensity = 0.5
+			}
+		}
+
+		itemID := lists[team][next[team]]
+		selected[itemID] = true
+		picks[team]++
+		slots = append(slots, Slot{
+			ItemID:     itemID,
+			Team:       algorithms[team],
+			SourceRank: next[team] + 1,
+			Propensity: propensity,
+		})
+	}
+	return slots
+}
+
+// 双侧符号检验：无差异时胜负次数服从 Binomial(n, 0.5)，样本较多时使用正态近似
+func signTest(wins0 int, wins1 int) float64 {
+	n := wins0 + wins1
+	if n == 0 {
+		return 1
+	}
+	if n > 100 {
+		z := (math.Abs(float64(wins0-wins1)) - 1) / math.Sqrt(float64(n))
+		return math.Min(1, math.Erfc(math.Max(z, 0)/math.Sqrt2))
+	}
+
+	// 精确检验：两侧尾部概率之和
+	k := min(wins0, wins1)
+	tail := 0.0
+	for j := 0; j <= k; j++ {
+		tail += math.Exp(logBinomial(n, j) - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*tail)
+}
+
+// log C(n, k)
+func logBinomial(n int, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+// 展示的键
+func impressionKey(userID string, itemID string) string {
+	return userID + "\x00" + itemID
+}
@@ -0,0 +1,454 @@
+package experiment
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/exposurelog"
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+)
+
+// Config 实验运行配置
+type Config struct {
+	Method            TestMethod
+	Alpha             float64       // 显著性水平，多个实验组时按组数做 Bonferroni 校正
+	MinSamples        int           // 每组至少曝光的用户数，未达到时不判定胜者
+	AttributionWindow time.Duration // 曝光后该时间内的反馈归因到实验组
+	SequentialTau     float64       // 序贯检验中转化率之差的先验标准差
+	AutoComplete      bool          // 判定胜者后将实验状态置为 completed，停止分流
+}
+
+// DefaultConfig 默认实验配置：双比例z检验，显著性水平0.05，每组至少100个用户
+func DefaultConfig() Config {
+	return Config{
+		Method:            MethodZTest,
+		Alpha:             0.05,
+		MinSamples:        100,
+		AttributionWindow: 24 * time.Hour,
+		SequentialTau:     0.05,
+	}
+}
+
+// Exposure 曝光记录
+type Exposure struct {
+	ExperimentID string    `json:"experiment_id"`
+	Arm          string    `json:"arm"`
+	UserID       string    `json:"user_id"`
+	Scenario     string    `json:"scenario,omitempty"`
+	ItemIDs      []string  `json:"item_ids"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// ExposureLogger 曝光日志
+type ExposureLogger interface {
+	LogExposure(exposure Exposure) error
+}
+
+// JSONExposureLogger 以 JSON Lines 写出曝光记录
+type JSONExposureLogger struct {
+	writer *exposurelog.Writer
+}
+
+// NewJSONExposureLogger 创建 JSON Lines 曝光日志
+func NewJSONExposureLogger(w io.Writer) *JSONExposureLogger {
+	return &JSONExposureLogger{writer: exposurelog.NewWriter(w)}
+}
+
+// LogExposure 写出一条曝光记录
+func (l *JSONExposureLogger) LogExposure(exposure Exposure) error {
+	return l.writer.Write(exposure)
+}
+
+// Analysis 实验分析结果
+type Analysis struct {
+	ExperimentID string       `json:"experiment_id"`
+	Method       TestMethod   `json:"method"`
+	Alpha        float64      `json:"alpha"`
+	Arms         []ArmStats   `json:"arms"`
+	Comparisons  []Comparison `json:"comparisons"`
+	Winner       string       `json:"winner"`
+	AnalyzedAt   time.Time    `json:"analyzed_at"`
+}
+
+// Runner 实验运行时：为用户分组、记录曝光并把反馈归因到实验组
+type Runner struct {
+	mu        sync.Mutex
+	config    Config
+	tests     map[string]*testState
+	logger    ExposureLogger
+	lastSweep time.Time
+}
+
+// 运行中的实验
+type testState struct {
+	test       models.ABTest
+	arms       map[string]*armState
+	shown      map[string]armExposure // 用户-物品的最近曝光，用于反馈归因
+	sequential map[string]float64     // 各实验组序贯检验p值的历史最小值
+}
+
+// 曝光的实验组与时间
+type armExposure struct {
+	arm string
+	at  time.Time
+}
+
+// 实验组的累计数据
+type armState struct {
+	users       map[string]bool
+	converted   map[string]bool
+	impressions int
+	clicks      int
+	reward      float64
+}
+
+// NewRunner 创建实验运行时，logger 为空时不写曝光日志
+func NewRunner(config Config, logger ExposureLogger) *Runner {
+	defaults := DefaultConfig()
+	if config.Method == "" {
+		config.Method = defaults.Method
+	}
+	if config.Alpha <= 0 || config.Alpha >= 1 {
+		config.Alpha = defaults.Alpha
+	}
+	if config.AttributionWindow <= 0 {
+		config.AttributionWindow = defaults.AttributionWindow
+	}
+	if config.SequentialTau <= 0 {
+		config.SequentialTau = defaults.SequentialTau
+	}
+
+	return &Runner{
+		config: config,
+		tests:  make(map[string]*testState),
+		logger: logger,
+	}
+}
+
+// AddTest 添加或更新实验，更新已有实验时保留已累计的数据
+func (r *Runner) AddTest(test models.ABTest) error {
+	if test.Status == "" {
+		test.Status = StatusActive
+	}
+	if err := validateTest(test); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if test.CreatedAt.IsZero() {
+		test.CreatedAt = now
+	}
+	test.UpdatedAt = now
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, exists := r.tests[test.ID]
+	if !exists {
+		state = &testState{
+			arms:       make(map[string]*armState),
+			shown:      make(map[string]armExposure),
+			sequential: make(map[string]float64),
+		}
+		r.tests[test.ID] = state
+	}
+	for _, arm := range test.Algorithms {
+		if state.arms[arm] == nil {
+			state.arms[arm] = &armState{users: make(map[string]bool), converted: make(map[string]bool)}
+		}
+	}
+	state.test = test
+	return nil
+}
+
+// RemoveTest 移除实验
+func (r *Runner) RemoveTest(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.tests[id]
+	delete(r.tests, id)
+	return exists
+}
+
+// SetStatus 修改实验状态，暂停与结束的实验不再分流
+func (r *Runner) SetStatus(id string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, exists := r.tests[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTestNotFound, id)
+	}
+	switch status {
+	case StatusActive, StatusPaused, StatusCompleted:
+	default:
+		return fmt.Errorf("%w: 实验 %s 的状态不支持: %s", ErrInvalidTest, id, status)
+	}
+	state.test.Status = status
+	state.test.UpdatedAt = time.Now()
+	return nil
+}
+
+// Tests 获取全部实验，按开始时间与ID排序
+func (r *Runner) Tests() []models.ABTest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tests := make([]models.ABTest, 0, len(r.tests))
+	for _, state := range r.orderedTests() {
+		tests = append(tests, state.test)
+	}
+	return tests
+}
+
+// Assign 为用户选择实验分组：依次检查运行中且场景匹配的实验，返回第一个覆盖该用户的实验分组
+func (r *Runner) Assign(userID string, scenario string) (Assignment, bool) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.orderedTests() {
+		if !running(state.test, now) {
+			continue
+		}
+		if limited := testScenario(state.test); limited != "" && limited != scenario {
+			continue
+		}
+		if arm, ok := assignArm(state.test, userID); ok {
+			return Assignment{ExperimentID: state.test.ID, Arm: arm}, true
+		}
+	}
+	return Assignment{}, false
+}
+
+// Expose 记录用户在实验组中看到的推荐列表
+func (r *Runner) Expose(assignment Assignment, userID string, scenario string, itemIDs []string) error {
+	now := time.Now()
+	r.expire(now)
+
+	r.mu.Lock()
+	state, exists := r.tests[assignment.ExperimentID]
+	if !exists {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTestNotFound, assignment.ExperimentID)
+	}
+	arm, exists := state.arms[assignment.Arm]
+	if !exists {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: 实验 %s 没有分组 %s", ErrInvalidTest, assignment.ExperimentID, assignment.Arm)
+	}
+	arm.users[userID] = true
+	arm.impressions += len(itemIDs)
+	for _, itemID := range itemIDs {
+		state.shown[impressionKey(userID, itemID)] = armExposure{arm: assignment.Arm, at: now}
+	}
+	logger := r.logger
+	r.mu.Unlock()
+
+	if logger == nil {
+		return nil
+	}
+	return logger.LogExposure(Exposure{
+		ExperimentID: assignment.ExperimentID,
+		Arm:          assignment.Arm,
+		UserID:       userID,
+		Scenario:     scenario,
+		ItemIDs:      itemIDs,
+		Timestamp:    now,
+	})
+}
+
+// RecordFeedback 将反馈归因到归因窗口内曝光过该物品的实验组，reward 大于0视为正反馈，返回是否有实验组归因
+func (r *Runner) RecordFeedback(userID string, itemID string, reward float64) bool {
+	now := time.Now()
+	key := impressionKey(userID, itemID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attributed := false
+	for _, state := range r.tests {
+		exposure, exists := state.shown[key]
+		if !exists || now.Sub(exposure.at) > r.config.AttributionWindow {
+			continue
+		}
+		arm := state.arms[exposure.arm]
+		if arm == nil {
+			continue
+		}
+		attributed = true
+		if reward <= 0 {
+			continue
+		}
+		arm.clicks++
+		arm.reward += reward
+		arm.converted[userID] = true
+	}
+	return attributed
+}
+
+// Analyze 统计实验各组指标并与对照组（Algorithms 的第一个算法）做显著性检验，判定的胜者写入实验的 Winner
+func (r *Runner) Analyze(id string) (*Analysis, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, exists := r.tests[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTestNotFound, id)
+	}
+
+	analysis := &Analysis{
+		ExperimentID: id,
+		Method:       r.config.Method,
+		Alpha:        r.config.Alpha,
+		Arms:         make([]ArmStats, 0, len(state.test.Algorithms)),
+		AnalyzedAt:   time.Now(),
+	}
+	enough := true
+	for _, name := range state.test.Algorithms {
+		stats := state.arms[name].stats(name)
+		analysis.Arms = append(analysis.Arms, stats)
+		if stats.Users < r.config.MinSamples {
+			enough = false
+		}
+	}
+
+	// 多个实验组分别与对照组比较，显著性水平按比较次数校正
+	control := analysis.Arms[0]
+	alpha := r.config.Alpha / float64(len(analysis.Arms)-1)
+	for _, treatment := range analysis.Arms[1:] {
+		comparison := Comparison{
+			Arm:        treatment.Arm,
+			Control:    control.Arm,
+			Difference: treatment.ConversionRate - control.ConversionRate,
+		}
+		if control.ConversionRate > 0 {
+			comparison.Lift = comparison.Difference / control.ConversionRate
+		}
+		comparison.ZScore, comparison.PValue = twoProportionZTest(control, treatment)
+		if r.config.Method == MethodSequential {
+			comparison.PValue = sequentialPValue(control, treatment, r.config.SequentialTau)
+			if previous, exists := state.sequential[treatment.Arm]; exists {
+				comparison.PValue = math.Min(comparison.PValue, previous)
+			}
+			state.sequential[treatment.Arm] = comparison.PValue
+		}
+		comparison.Significant = enough && comparison.PValue < alpha
+		analysis.Comparisons = append(analysis.Comparisons, comparison)
+	}
+	analysis.Winner = winner(analysis)
+
+	state.test.Winner = analysis.Winner
+	state.test.Metrics = map[string]interface{}{
+		"method":      string(analysis.Method),
+		"alpha":       analysis.Alpha,
+		"arms":        analysis.Arms,
+		"comparisons": analysis.Comparisons,
+		"analyzed_at": analysis.AnalyzedAt,
+	}
+	state.test.UpdatedAt = analysis.AnalyzedAt
+	if analysis.Winner != "" && r.config.AutoComplete {
+		state.test.Status = StatusCompleted
+	}
+	return analysis, nil
+}
+
+// GetStats 获取统计信息
+func (r *Runner) GetStats() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	experiments := make(map[string]interface{}, len(r.tests))
+	for id, state := range r.tests {
+		arms := make(map[string]interface{}, len(state.arms))
+		for name, arm := range state.arms {
+			arms[name] = arm.stats(name)
+		}
+		experiments[id] = map[string]interface{}{
+			"status": state.test.Status,
+			"winner": state.test.Winner,
+			"arms":   arms,
+		}
+	}
+	return map[string]interface{}{
+		"method":      string(r.config.Method),
+		"experiments": experiments,
+	}
+}
+
+// 胜者：转化率显著高于对照组的实验组中转化率最高者；所有实验组都显著低于对照组时为对照组
+func winner(analysis *Analysis) string {
+	best := ""
+	bestRate := analysis.Arms[0].ConversionRate
+	controlWins := true
+	for i, comparison := range analysis.Comparisons {
+		if !comparison.Significant || comparison.Difference >= 0 {
+			controlWins = false
+		}
+		if comparison.Significant && comparison.Difference > 0 && analysis.Arms[i+1].ConversionRate > bestRate {
+			best, bestRate = comparison.Arm, analysis.Arms[i+1].ConversionRate
+		}
+	}
+	if best == "" && controlWins {
+		return analysis.Arms[0].Arm
+	}
+	return best
+}
+
+// 实验组的指标
+func (a *armState) stats(name string) ArmStats {
+	stats := ArmStats{
+		Arm:         name,
+		Users:       len(a.users),
+		Conversions: len(a.converted),
+		Impressions: a.impressions,
+		Clicks:      a.clicks,
+		Reward:      a.reward,
+	}
+	if stats.Users > 0 {
+		stats.ConversionRate = float64(stats.Conversions) / float64(stats.Users)
+	}
+	if stats.Impressions > 0 {
+		stats.CTR = float64(stats.Clicks) / float64(stats.Impressions)
+	}
+	return stats
+}
+
+// 按开始时间与ID排序的实验，调用方需持有锁
+func (r *Runner) orderedTests() []*testState {
+	states := make([]*testState, 0, len(r.tests))
+	for _, state := range r.tests {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if !states[i].test.StartTime.Equal(states[j].test.StartTime) {
+			return states[i].test.StartTime.Before(states[j].test.StartTime)
+		}
+		return states[i].test.ID < states[j].test.ID
+	})
+	return states
+}
+
+// 清理超出归因窗口的曝光，按窗口的十分之一为间隔
+func (r *Runner) expire(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Sub(r.lastSweep) < r.config.AttributionWindow/10 {
+		return
+	}
+	r.lastSweep = now
+	for _, state := range r.tests {
+		for key, exposure := range state.shown {
+			if now.Sub(exposure.at) > r.config.AttributionWindow {
+				delete(state.shown, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package experiment
+
+import (
+	"math"
+)
+
+// TestMethod 显著性检验方法
+type TestMethod string
+
+const (
+	// MethodZTest 双比例z检验，适用于样本量事先确定、到达样本量后才查看结果的实验
+	MethodZTest TestMethod = "z_test"
+	// MethodSequential 混合序贯概率比检验（mSPRT），p值始终有效，可以随时查看并提前结束实验
+	MethodSequential TestMethod = "sequential"
+)
+
+// ArmStats 实验组的指标
+type ArmStats struct {
+	Arm            string  `json:"arm"`
+	Users          int     `json:"users"`           // 曝光用户数
+	Conversions    int     `json:"conversions"`     // 有正反馈的曝光用户数
+	ConversionRate float64 `json:"conversion_rate"` // 用户转化率，显著性检验的指标
+	Impressions    int     `json:"impressions"`     // 曝光物品数
+	Clicks         int     `json:"clicks"`          // 归因到曝光的正反馈次数
+	CTR            float64 `json:"ctr"`
+	Reward         float64 `json:"reward"` // 归因到曝光的反馈奖励之和
+}
+
+// Comparison 实验组与对照组的比较
+type Comparison struct {
+	Arm         string  `json:"arm"`
+	Control     string  `json:"control"`
+	Difference  float64 `json:"difference"` // 转化率之差
+	Lift        float64 `json:"lift"`       // 相对对照组的转化率提升
+	ZScore      float64 `json:"z_score"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// 双比例z检验，返回z值与双侧p值
+func twoProportionZTest(control ArmStats, treatment ArmStats) (float64, float64) {
+	n1, n2 := float64(control.Users), float64(treatment.Users)
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+	pooled := float64(control.Conversions+treatment.Conversions) / (n1 + n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if se == 0 {
+		return 0, 1
+	}
+	z := (treatment.ConversionRate - control.ConversionRate) / se
+	return z, math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// mSPRT 的始终有效p值：以正态混合先验 N(0, tau²) 计算转化率之差的似然比 Λ，p值为 1/Λ
+func sequentialPValue(control ArmStats, treatment ArmStats, tau float64) float64 {
+	n1, n2 := float64(control.Users), float64(treatment.Users)
+	if n1 == 0 || n2 == 0 || tau <= 0 {
+		return 1
+	}
+	p1, p2 := control.ConversionRate, treatment.ConversionRate
+	variance := p1*(1-p1)/n1 + p2*(1-p2)/n2
+	if variance == 0 {
+		return 1
+	}
+
+	tau2 := tau * tau
+	theta := p2 - p1
+	logLambda := 0.5*math.Log(variance/(variance+tau2)) + tau2*theta*theta/(2*variance*(variance+tau2))
+	return math.Min(1, math.Exp(-logLambda))
+}
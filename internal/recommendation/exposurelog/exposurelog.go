@@ -0,0 +1,105 @@
+// Package exposurelog 推荐曝光日志：记录每次请求的候选列表、展示位置与倾向性以及之后的反馈，用于离线反事实评估
+package exposurelog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// EntryType 日志条目类型
+type EntryType string
+
+const (
+	EntryExposure EntryType = "exposure" // 一次推荐请求的展示
+	EntryFeedback EntryType = "feedback" // 用户对物品的反馈
+)
+
+// Candidate 记录策略排序前的候选物品
+type Candidate struct {
+	ItemID string  `json:"item_id"`
+	Score  float64 `json:"score"`
+}
+
+// Position 展示位置
+type Position struct {
+	ItemID     string  `json:"item_id"`
+	Position   int     `json:"position"`            // 展示位置，从1开始
+	Propensity float64 `json:"propensity"`          // 记录策略在该位置展示此物品的概率，0表示未知
+	Algorithm  string  `json:"algorithm,omitempty"` // 产生该物品的算法
+}
+
+// Entry 日志条目，展示与反馈共用同一结构，按 Type 区分
+type Entry struct {
+	Type       EntryType              `json:"type"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	UserID     string                 `json:"user_id"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Scenario   string                 `json:"scenario,omitempty"`
+	Context    map[string]interface{} `json:"context,omitempty"`
+	Algorithm  string                 `json:"algorithm,omitempty"` // 记录策略，即实际生成推荐的算法
+	Candidates []Candidate            `json:"candidates,omitempty"`
+	Shown      []Position             `json:"shown,omitempty"`
+	ItemID     string                 `json:"item_id,omitempty"` // 反馈的物品
+	Reward     float64                `json:"reward,omitempty"`  // 反馈奖励，取值[0,1]
+}
+
+// Logger 曝光日志
+type Logger interface {
+	Log(entry Entry) error
+}
+
+// Writer 并发安全的 JSON Lines 写入器，实验曝光等其他记录也通过 Write 复用
+type Writer struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewWriter 创建 JSON Lines 日志
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+// Log 写出一条日志
+func (w *Writer) Log(entry Entry) error {
+	return w.Write(entry)
+}
+
+// Write 以一行 JSON 写出任意记录
+func (w *Writer) Write(record interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.encoder.Encode(record); err != nil {
+		return fmt.Errorf("写入曝光日志失败: %w", err)
+	}
+	return nil
+}
+
+// Load 读取 JSON Lines 日志，跳过空行
+func Load(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	entries := make([]Entry, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("解析曝光日志第 %d 行失败: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取曝光日志失败: %w", err)
+	}
+	return entries, nil
+}
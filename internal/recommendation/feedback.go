@@ -0,0 +1,293 @@
+package recommendation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+)
+
+// 反馈类型，兼容用户行为类型（view、purchase、favorite、share等）
+type FeedbackType string
+
+const (
+	FeedbackLike    FeedbackType = "like"    // 喜欢
+	FeedbackDislike FeedbackType = "dislike" // 不喜欢，屏蔽该物品
+	FeedbackClick   FeedbackType = "click"   // 点击
+	FeedbackIgnore  FeedbackType = "ignore"  // 曝光后未交互
+	FeedbackDwell   FeedbackType = "dwell"   // 停留，Value 为停留秒数
+	FeedbackRating  FeedbackType = "rating"  // 显式评分，Value 为评分
+)
+
+// 停留时长换算评分：短于 bounceDwell 视为跳出，达到 saturatedDwell 时取满分
+const (
+	bounceDwell    = 5.0
+	saturatedDwell = 60.0
+)
+
+// 反馈类型对应的隐式评分，与用户行为评分同一量纲，负值表示负反馈
+var feedbackRatings = map[FeedbackType]float64{
+	FeedbackLike:    3.0,
+	FeedbackDislike: -2.0,
+	FeedbackIgnore:  -0.5,
+}
+
+// 类型化的用户反馈
+type Feedback struct {
+	UserID    string
+	ItemID    string
+	Type      FeedbackType
+	Value     float64 // 评分值或停留秒数
+	Timestamp time.Time
+	Context   map[string]interface{}
+}
+
+// 解析用户反馈，支持 Feedback、models.UserFeedback、用户行为、反馈类型字符串、
+// 包含 type/value/rating 的映射以及数值评分
+func ParseFeedback(userID string, itemID string, feedback interface{}) (Feedback, error) {
+	parsed := Feedback{UserID: userID, ItemID: itemID}
+
+	switch f := feedback.(type) {
+	case Feedback:
+		parsed = f
+	case *Feedback:
+		if f == nil {
+			return Feedback{}, &RecommendationError{Message: "用户反馈为空"}
+		}
+		parsed = *f
+	case models.UserFeedback:
+		parsed = fromUserFeedback(f)
+	case *models.UserFeedback:
+		if f == nil {
+			return Feedback{}, &RecommendationError{Message: "用户反馈为空"}
+		}
+		parsed = fromUserFeedback(*f)
+	case datacollection.UserBehavior:
+		parsed.Type = FeedbackType(f.Behavior)
+		parsed.Value = f.Value
+		parsed.Timestamp = f.Timestamp
+		parsed.Context = f.Context
+		// 用户行为类型不在已知评分表中时按点击计
+		if !parsed.Type.isKnown() {
+			parsed.Type = FeedbackClick
+		}
+	case string:
+		parsed.Type = FeedbackType(f)
+	case map[string]interface{}:
+		if value, ok := toFloat64(f["rating"]); ok {
+			parsed.Type = FeedbackRating
+			parsed.Value = value
+		} else {
+			kind, _ := f["type"].(string)
+			parsed.Type = FeedbackType(kind)
+			parsed.Value, _ = toFloat64(f["value"])
+		}
+		if text, ok := f["timestamp"].(string); ok {
+			parsed.Timestamp, _ = time.Parse(time.RFC3339, text)
+		}
+		parsed.Context, _ = f["context"].(map[string]interface{})
+	default:
+		value, ok := toFloat64(feedback)
+		if !ok {
+			return Feedback{}, &RecommendationError{Message: fmt.Sprintf("无法解析的用户反馈: %v", feedback)}
+		}
+		parsed.Type = FeedbackRating
+		parsed.Value = value
+	}
+
+	if !parsed.Type.isKnown() {
+		return Feedback{}, &RecommendationError{Message: fmt.Sprintf("无法解析的用户反馈: %v", feedback)}
+	}
+	if parsed.UserID == "" {
+		parsed.UserID = userID
+	}
+	if parsed.ItemID == "" {
+		parsed.ItemID = itemID
+	}
+	if parsed.Timestamp.IsZero() {
+		parsed.Timestamp = time.Now()
+	}
+	return parsed, nil
+}
+
+// 转换 models.UserFeedback
+func fromUserFeedback(f models.UserFeedback) Feedback {
+	return Feedback{
+		UserID:    f.UserID,
+		ItemID:    f.ItemID,
+		Type:      FeedbackType(f.Type),
+		Value:     f.Value,
+		Timestamp: f.Timestamp,
+		Context:   f.Context,
+	}
+}
+
+// 判断反馈类型是否可识别
+func (t FeedbackType) isKnown() bool {
+	if t == FeedbackRating || t == FeedbackDwell {
+		return true
+	}
+	if _, exists := feedbackRatings[t]; exists {
+		return true
+	}
+	_, exists := behaviorRatings[datacollection.UserBehaviorType(t)]
+	return exists
+}
+
+// 隐式评分：显式评分取评分值，停留按时长换算，其余按反馈类型取固定评分
+func (f Feedback) Rating() float64 {
+	switch f.Type {
+	case FeedbackRating:
+		return f.Value
+	case FeedbackDwell:
+		if f.Value < bounceDwell {
+			return feedbackRatings[FeedbackIgnore]
+		}
+		return 2.0 * math.Min(f.Value/saturatedDwell, 1)
+	}
+	if rating, exists := feedbackRatings[f.Type]; exists {
+		return rating
+	}
+	return behaviorRatings[datacollection.UserBehaviorType(f.Type)]
+}
+
+// 归一化到[-1,1]的反馈信号，按购买评分缩放
+func (f Feedback) Signal() float64 {
+	return math.Max(-1, math.Min(1, f.Rating()/behaviorRatings[datacollection.BehaviorPurchase]))
+}
+
+// 判断是否为负反馈
+func (f Feedback) IsNegative() bool {
+	return f.Rating() < 0
+}
+
+// 全量清理过期屏蔽和忽略记录的间隔，避免不再访问的用户的记录一直保留
+const suppressionSweepInterval = time.Hour
+
+// 忽略记录：窗口内的忽略次数和窗口截止时间
+type ignoreRecord struct {
+	count int
+	until time.Time
+}
+
+// 负反馈屏蔽列表：不喜欢的物品立即屏蔽，多次忽略的物品在统计窗口内达到次数后屏蔽，正反馈解除屏蔽
+type suppressionList struct {
+	mu        sync.Mutex
+	until     map[string]map[string]time.Time     // 用户-物品-屏蔽截止时间
+	ignores   map[string]map[string]*ignoreRecord // 用户-物品-忽略记录
+	lastSweep time.Time
+}
+
+// 创建负反馈屏蔽列表
+func newSuppressionList() *suppressionList {
+	return &suppressionList{
+		until:     make(map[string]map[string]time.Time),
+		ignores:   make(map[string]map[string]*ignoreRecord),
+		lastSweep: time.Now(),
+	}
+}
+
+// 记录反馈，返回物品是否因此被屏蔽
+// 屏蔽时长与清理以服务端当前时间为准，客户端上报的反馈时间只用于忽略次数的统计窗口
+func (s *suppressionList) record(feedback Feedback, ttl time.Duration, ignoreLimit int, ignoreWindow time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	userID, itemID := feedback.UserID, feedback.ItemID
+	if !feedback.IsNegative() {
+		delete(s.until[userID], itemID)
+		delete(s.ignores[userID], itemID)
+		return false
+	}
+
+	if feedback.Type != FeedbackDislike {
+		if ignoreLimit <= 0 {
+			return false
+		}
+		if s.ignores[userID] == nil {
+			s.ignores[userID] = make(map[string]*ignoreRecord)
+		}
+		ignore := s.ignores[userID][itemID]
+		if ignore == nil || feedback.Timestamp.After(ignore.until) {
+			ignore = &ignoreRecord{until: feedback.Timestamp.Add(ignoreWindow)}
+			s.ignores[userID][itemID] = ignore
+		}
+		ignore.count++
+		if ignore.count < ignoreLimit {
+			return false
+		}
+		delete(s.ignores[userID], itemID)
+	}
+
+	if s.until[userID] == nil {
+		s.until[userID] = make(map[string]time.Time)
+	}
+	s.until[userID][itemID] = now.Add(ttl)
+	return true
+}
+
+// 获取用户当前屏蔽的物品，同时清理已过期的屏蔽
+func (s *suppressionList) suppressed(userID string, now time.Time) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make(map[string]bool, len(s.until[userID]))
+	for itemID, until := range s.until[userID] {
+		if now.After(until) {
+			delete(s.until[userID], itemID)
+			continue
+		}
+		items[itemID] = true
+	}
+	if len(s.until[userID]) == 0 {
+		delete(s.until, userID)
+	}
+	return items
+}
+
+// 距上次清理超过间隔时清理所有用户已过期的屏蔽和忽略记录，调用方需持有锁
+func (s *suppressionList) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < suppressionSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for userID, items := range s.until {
+		for itemID, until := range items {
+			if now.After(until) {
+				delete(items, itemID)
+			}
+		}
+		if len(items) == 0 {
+			delete(s.until, userID)
+		}
+	}
+	for userID, items := range s.ignores {
+		for itemID, ignore := range items {
+			if now.After(ignore.until) {
+				delete(items, itemID)
+			}
+		}
+		if len(items) == 0 {
+			delete(s.ignores, userID)
+		}
+	}
+}
+
+// 统计屏蔽的用户-物品数
+func (s *suppressionList) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	total := 0
+	for _, items := range s.until {
+		total += len(items)
+	}
+	return total
+}
@@ -0,0 +1,326 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/guanguoyintao/luban/internal/recommendation/diversity"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
+	"github.com/sirupsen/logrus"
+)
+
+// 混合过滤推荐引擎适配器
+// 组件引擎与协同过滤、内容过滤适配器共享，数据更新由组件适配器负责
+type HybridFilteringAdapter struct {
+	engine        *algorithms.HybridFilteringEngine
+	collaborative *CollaborativeFilteringAdapter
+	contentBased  *ContentBasedFilteringAdapter
+	log           *logrus.Logger
+}
+
+// 创建新的混合过滤推荐引擎适配器
+func NewHybridFilteringAdapter(engine *algorithms.HybridFilteringEngine, collaborative *CollaborativeFilteringAdapter, contentBased *ContentBasedFilteringAdapter, log *logrus.Logger) *HybridFilteringAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+
+	return &HybridFilteringAdapter{
+		engine:        engine,
+		collaborative: collaborative,
+		contentBased:  contentBased,
+		log:           log,
+	}
+}
+
+// 生成推荐
+func (a *HybridFilteringAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.GenerateScenarioRecommendations(request.UserID, string(request.Scenario), resolveLimit(request.Limit))
+
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  AlgorithmHybridFiltering,
+			Confidence: rec.Confidence,
+			Metadata: map[string]interface{}{
+				"collaborative_score": rec.CollaborativeScore,
+				"content_based_score": rec.ContentBasedScore,
+				"diversity_score":     rec.DiversityScore,
+				"popularity_score":    rec.PopularityScore,
+				"recency_score":       rec.RecencyScore,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmHybridFiltering,
+		Metadata: map[string]interface{}{
+			"weights": a.engine.GetUserWeights(request.UserID, string(request.Scenario)),
+		},
+	}, nil
+}
+
+// 候选物品的排序特征：混合过滤的组件得分、物品热度与时效性、价格以及用户对物品类别的偏好
+func (a *HybridFilteringAdapter) CandidateFeatures(ctx context.Context, userID string, itemIDs []string) (map[string]strategy.CandidateFeatures, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	features := make(map[string]strategy.CandidateFeatures, len(itemIDs))
+	for _, rec := range a.engine.ScoreItems(userID, "", itemIDs) {
+		candidate := strategy.CandidateFeatures{
+			HybridScore:        rec.Score,
+			CollaborativeScore: rec.CollaborativeScore,
+			ContentBasedScore:  rec.ContentBasedScore,
+			DiversityScore:     rec.DiversityScore,
+			Popularity:         a.engine.PopularityScore(rec.ItemID),
+			Recency:            a.engine.RecencyScore(rec.ItemID),
+		}
+		// 数值特征在写入内容过滤引擎时已做对数压缩
+		if item, exists := a.contentBased.engine.GetItemFeatures(rec.ItemID); exists {
+			candidate.Price = item.Features["price"]
+			candidate.CategoryAffinity = a.contentBased.engine.CategoryAffinity(userID, item.Category)
+		}
+		features[rec.ItemID] = candidate
+	}
+	return features, nil
+}
+
+// 为候选物品打分，供推荐流水线融合多个算法的得分
+func (a *HybridFilteringAdapter) ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.ScoreItems(userID, "", itemIDs)
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  AlgorithmHybridFiltering,
+			Confidence: rec.Confidence,
+		})
+	}
+	return results, nil
+}
+
+// 批量生成推荐
+func (a *HybridFilteringAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *HybridFilteringAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	explanations := make([]string, 0, 2)
+	var lastErr error
+
+	if explanation, err := a.collaborative.ExplainRecommendation(ctx, userID, itemID); err == nil {
+		explanations = append(explanations, explanation)
+	} else {
+		lastErr = err
+	}
+	if explanation, err := a.contentBased.ExplainRecommendation(ctx, userID, itemID); err == nil {
+		explanations = append(explanations, explanation)
+	} else {
+		lastErr = err
+	}
+
+	if len(explanations) == 0 {
+		return "", lastErr
+	}
+	return strings.Join(explanations, "；"), nil
+}
+
+// 更新推荐模型
+func (a *HybridFilteringAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	// 组件引擎由各自的适配器更新，这里避免重复写入
+	a.log.Debug("混合过滤模型随组件引擎更新")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *HybridFilteringAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmHybridFiltering}, nil
+}
+
+// 获取算法参数
+func (a *HybridFilteringAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"collaborative_weight":  config.CollaborativeWeight,
+		"content_based_weight":  config.ContentBasedWeight,
+		"diversity_weight":      config.DiversityWeight,
+		"popularity_weight":     config.PopularityWeight,
+		"recency_weight":        config.RecencyWeight,
+		"enable_diversity":      config.EnableDiversity,
+		"diversity_method":      string(config.DiversityMethod),
+		"diversity_lambda":      config.DiversityLambda,
+		"enable_popularity":     config.EnablePopularity,
+		"enable_recency":        config.EnableRecency,
+		"enable_blend_learning": config.EnableBlendLearning,
+		"blend_learning_rate":   config.BlendLearningRate,
+		"blend_l2":              config.BlendL2,
+		"blend_min_samples":     config.BlendMinSamples,
+	}, nil
+}
+
+// 设置算法参数
+func (a *HybridFilteringAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	weights := map[string]*float64{
+		"collaborative_weight": &config.CollaborativeWeight,
+		"content_based_weight": &config.ContentBasedWeight,
+		"diversity_weight":     &config.DiversityWeight,
+		"popularity_weight":    &config.PopularityWeight,
+		"recency_weight":       &config.RecencyWeight,
+		"blend_learning_rate":  &config.BlendLearningRate,
+		"blend_l2":             &config.BlendL2,
+	}
+	flags := map[string]*bool{
+		"enable_diversity":      &config.EnableDiversity,
+		"enable_popularity":     &config.EnablePopularity,
+		"enable_recency":        &config.EnableRecency,
+		"enable_blend_learning": &config.EnableBlendLearning,
+	}
+	scenarioWeights := make(map[string]map[string]float64)
+
+	for name, value := range parameters {
+		if weight, exists := weights[name]; exists {
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			*weight = v
+			continue
+		}
+		if flag, exists := flags[name]; exists {
+			v, ok := value.(bool)
+			if !ok {
+				return invalidParameterError(name, value)
+			}
+			*flag = v
+			continue
+		}
+		switch name {
+		case "blend_min_samples":
+			v, ok := toInt(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			config.BlendMinSamples = v
+		case "diversity_method":
+			v, ok := value.(string)
+			if !ok || (diversity.Method(v) != diversity.MethodMMR && diversity.Method(v) != diversity.MethodDPP) {
+				return invalidParameterError(name, value)
+			}
+			config.DiversityMethod = diversity.Method(v)
+		case "diversity_lambda":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v > 1 {
+				return invalidParameterError(name, value)
+			}
+			config.DiversityLambda = v
+		case "scenario_weights":
+			parsed, ok := parseScenarioWeights(value)
+			if !ok {
+				return invalidParameterError(name, value)
+			}
+			scenarioWeights = parsed
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	for scenario, weights := range scenarioWeights {
+		a.engine.UpdateScenarioWeights(scenario, weights)
+	}
+	return nil
+}
+
+// 解析手动设置的场景权重：场景-权重名称-权重，权重为空的场景恢复使用学到的权重
+func parseScenarioWeights(value interface{}) (map[string]map[string]float64, bool) {
+	scenarios, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	parsed := make(map[string]map[string]float64, len(scenarios))
+	for scenario, raw := range scenarios {
+		weights := make(map[string]float64)
+		if raw != nil {
+			entries, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			for name, weight := range entries {
+				v, ok := toFloat64(weight)
+				if !ok || v < 0 || !isBlendComponent(name) {
+					return nil, false
+				}
+				weights[name] = v
+			}
+		}
+		parsed[scenario] = weights
+	}
+	return parsed, true
+}
+
+// 判断是否为混合权重名称
+func isBlendComponent(name string) bool {
+	for _, component := range algorithms.BlendComponents {
+		if component == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 持久化按场景学到的混合权重
+func (a *HybridFilteringAdapter) SaveBlendWeights(path string) error {
+	return a.engine.GetBlendLearner().Save(path)
+}
+
+// 加载按场景学到的混合权重
+func (a *HybridFilteringAdapter) LoadBlendWeights(path string) error {
+	return a.engine.GetBlendLearner().Load(path)
+}
+
+// 获取推荐统计信息
+func (a *HybridFilteringAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetPerformanceStats()
+	stats["user_weights"] = a.engine.GetUserWeights(userID, "")
+	return stats, nil
+}
+
+// 记录用户反馈
+func (a *HybridFilteringAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	// 评分由组件适配器记录，这里只根据反馈学习场景的混合权重并调整用户的组件权重
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+
+	a.engine.RecordFeedback(userID, itemID, parsed.Signal())
+	return nil
+}
+
+// 关闭推荐引擎
+func (a *HybridFilteringAdapter) Close() error {
+	a.log.Info("关闭混合过滤推荐引擎")
+	return nil
+}
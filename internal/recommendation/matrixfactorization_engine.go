@@ -0,0 +1,243 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 矩阵分解推荐引擎适配器
+type MatrixFactorizationAdapter struct {
+	engine    *algorithms.MatrixFactorizationEngine
+	retrainer *retrainer
+	log       *logrus.Logger
+}
+
+// 创建新的矩阵分解推荐引擎适配器
+func NewMatrixFactorizationAdapter(engine *algorithms.MatrixFactorizationEngine, log *logrus.Logger) *MatrixFactorizationAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewMatrixFactorizationEngine(log)
+	}
+
+	return &MatrixFactorizationAdapter{
+		engine:    engine,
+		retrainer: newRetrainer(string(AlgorithmMatrixFactorization), engine.Train, log),
+		log:       log,
+	}
+}
+
+// 生成推荐
+func (a *MatrixFactorizationAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.Recommend(request.UserID, resolveLimit(request.Limit))
+
+	// 以最高得分为基准计算置信度
+	maxScore := 0.0
+	for _, rec := range recs {
+		if rec.Score > maxScore {
+			maxScore = rec.Score
+		}
+	}
+
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		if rec.Score <= 0 {
+			continue
+		}
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     "与您的兴趣模式相符",
+			Algorithm:  AlgorithmMatrixFactorization,
+			Confidence: 0.5 + 0.5*rec.Score/maxScore,
+			Metadata:   make(map[string]interface{}),
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmMatrixFactorization,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 为候选物品打分，供推荐流水线融合多个算法的得分
+func (a *MatrixFactorizationAdapter) ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scores := a.engine.PredictItems(userID, itemIDs)
+	results := make([]RecommendationResult, 0, len(scores))
+	for _, itemID := range itemIDs {
+		score, exists := scores[itemID]
+		if !exists {
+			continue
+		}
+		results = append(results, RecommendationResult{
+			ItemID:     itemID,
+			Score:      score,
+			Reason:     "与您的兴趣模式相符",
+			Algorithm:  AlgorithmMatrixFactorization,
+			Confidence: 1.0 / (1.0 + math.Exp(-score)),
+		})
+	}
+	return results, nil
+}
+
+// 批量生成推荐
+func (a *MatrixFactorizationAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *MatrixFactorizationAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	score, exists := a.engine.Predict(userID, itemID)
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+
+	// 找出用户交互过且隐向量相近的物品
+	interactions := a.engine.GetUserInteractions(userID)
+	related := make([]string, 0, 3)
+	for _, similar := range a.engine.SimilarItems(itemID, 20) {
+		if _, seen := interactions[similar.ItemID]; seen && similar.Similarity > 0 {
+			related = append(related, similar.ItemID)
+			if len(related) == 3 {
+				break
+			}
+		}
+	}
+
+	if len(related) == 0 {
+		return fmt.Sprintf("推荐物品 %s 是因为它与您的兴趣模式相符（偏好得分%.2f）", itemID, score), nil
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为它与您交互过的 %s 在兴趣空间中相近（偏好得分%.2f）", itemID, strings.Join(related, "、"), score), nil
+}
+
+// 更新推荐模型：首次写入交互数据时同步训练，之后按新增交互数或间隔在后台重新训练，
+// 两次训练之间通过折叠更新涉及用户的隐向量
+func (a *MatrixFactorizationAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	users := make(map[string]bool)
+	for _, behavior := range update.Behaviors {
+		if rating := behaviorToRating(behavior); rating > 0 {
+			a.engine.AddInteraction(behavior.UserID, behavior.ItemID, rating)
+			users[behavior.UserID] = true
+			added++
+		}
+	}
+
+	if !a.retrainer.add(added) {
+		for userID := range users {
+			a.engine.FoldInUser(userID)
+		}
+	}
+
+	a.log.WithField("behaviors", added).Debug("更新矩阵分解模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *MatrixFactorizationAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmMatrixFactorization}, nil
+}
+
+// 获取算法参数
+func (a *MatrixFactorizationAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"factors":        config.Factors,
+		"iterations":     config.Iterations,
+		"regularization": config.Regularization,
+		"alpha":          config.Alpha,
+	}, nil
+}
+
+// 设置算法参数
+func (a *MatrixFactorizationAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "factors", "iterations":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			if name == "factors" {
+				config.Factors = v
+			} else {
+				config.Iterations = v
+			}
+		case "regularization", "alpha":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			if name == "regularization" {
+				config.Regularization = v
+			} else {
+				config.Alpha = v
+			}
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	// 隐向量维度变化会清空已训练的隐向量，需要立即重新训练
+	retrain := config.Factors != a.engine.GetConfig().Factors
+	a.engine.SetConfig(&config)
+	if retrain {
+		a.retrainer.retrain()
+	}
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *MatrixFactorizationAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_interaction_count"] = len(a.engine.GetUserInteractions(userID))
+	return stats, nil
+}
+
+// 记录用户反馈，通过折叠增量更新用户隐向量
+func (a *MatrixFactorizationAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	rating, err := feedbackToRating(feedback)
+	if err != nil {
+		return err
+	}
+	if rating <= 0 {
+		return nil
+	}
+
+	a.engine.AddInteraction(userID, itemID, rating)
+	if !a.retrainer.add(1) {
+		a.engine.FoldInUser(userID)
+	}
+	return nil
+}
+
+// 关闭推荐引擎，等待后台训练完成
+func (a *MatrixFactorizationAdapter) Close() error {
+	a.retrainer.wait()
+	a.log.Info("关闭矩阵分解推荐引擎")
+	return nil
+}
@@ -91,6 +91,10 @@ type AlgorithmMetrics struct {
 	Precision       float64                `json:"precision"`
 	Recall          float64                `json:"recall"`
 	F1Score         float64                `json:"f1_score"`
+	NDCG            float64                `json:"ndcg"`
+	MAP             float64                `json:"map"`
+	MRR             float64                `json:"mrr"`
+	HitRate         float64                `json:"hit_rate"`
 	CTR             float64                `json:"ctr"`              // Click-through rate
 	ConversionRate  float64                `json:"conversion_rate"`
 	AverageScore    float64                `json:"average_score"`
@@ -0,0 +1,333 @@
+// Package offpolicy 基于曝光日志的反事实评估：将记录策略展示的列表与反馈关联，
+// 用回放、IPS 与 SNIPS 估计其他算法上线后的点击率
+package offpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/guanguoyintao/luban/internal/recommendation/exposurelog"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoExposures 曝光日志中没有展示记录
+var ErrNoExposures = errors.New("曝光日志中没有展示记录")
+
+// Config 反事实评估配置
+type Config struct {
+	Algorithms           []recommendation.AlgorithmType // 评估的目标算法，为空时评估所有注册的算法
+	AttributionWindow    time.Duration                  // 展示后该时间内的正反馈计为点击
+	RestrictToCandidates bool                           // 目标算法的列表只保留记录时的候选物品
+	MinPropensity        float64                        // 前缀倾向性下限，防止个别位置的权重过大
+	PoolSize             int                            // 目标算法重新推荐的物品数下限，限定候选时需足够覆盖记录的候选
+}
+
+// DefaultConfig 默认反事实评估配置：30分钟归因窗口，前缀倾向性下限0.01，重新推荐至少100个物品
+func DefaultConfig() Config {
+	return Config{
+		AttributionWindow:    30 * time.Minute,
+		RestrictToCandidates: true,
+		MinPropensity:        0.01,
+		PoolSize:             100,
+	}
+}
+
+// Estimate 一个策略的点击率估计
+type Estimate struct {
+	Algorithm           string  `json:"algorithm"`
+	Exposures           int     `json:"exposures"`             // 参与评估的展示次数
+	Positions           int     `json:"positions"`             // 倾向性已知的展示位置数
+	Clicks              int     `json:"clicks"`                // 记录策略在这些位置上获得的点击数
+	Matched             int     `json:"matched"`               // 目标算法与记录策略从第一个位置起给出同一物品的位置数
+	UnknownPropensity   int     `json:"unknown_propensity"`    // 倾向性未知而跳过的位置数
+	Replay              float64 `json:"replay_ctr"`            // 回放：匹配位置上的点击率
+	IPS                 float64 `json:"ips_ctr"`               // 逆倾向加权
+	SNIPS               float64 `json:"snips_ctr"`             // 自归一化逆倾向加权
+	EffectiveSampleSize float64 `json:"effective_sample_size"` // 重要性权重的有效样本量
+	Errors              int     `json:"errors"`                // 目标算法推荐失败的展示次数
+}
+
+// Report 反事实评估报告
+type Report struct {
+	Logging []Estimate `json:"logging"` // 各记录策略在日志中观测到的点击率
+	Targets []Estimate `json:"targets"` // 各目标算法的点击率估计
+}
+
+// Estimator 反事实评估器
+type Estimator struct {
+	config  Config
+	factory evaluation.EngineFactory
+	log     *logrus.Logger
+}
+
+// 与反馈关联后的展示
+type exposure struct {
+	entry   exposurelog.Entry
+	clicked []bool // 各展示位置是否被点击
+}
+
+// NewEstimator 创建反事实评估器，factory 为空时使用默认注册的算法引擎
+func NewEstimator(config Config, factory evaluation.EngineFactory, log *logrus.Logger) *Estimator {
+	if log == nil {
+		log = logrus.New()
+	}
+	if factory == nil {
+		factory = func() *recommendation.RecommendationEngineManager {
+			return recommendation.NewRecommendationEngineManager(log)
+		}
+	}
+
+	defaults := DefaultConfig()
+	if config.AttributionWindow <= 0 {
+		config.AttributionWindow = defaults.AttributionWindow
+	}
+	if config.MinPropensity <= 0 || config.MinPropensity > 1 {
+		config.MinPropensity = defaults.MinPropensity
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = defaults.PoolSize
+	}
+
+	return &Estimator{
+		config:  config,
+		factory: factory,
+		log:     log,
+	}
+}
+
+// Estimate 关联曝光日志中的展示与反馈，统计记录策略的点击率并估计各目标算法的点击率；
+// dataset 中有行为日志时先用其训练目标算法
+func (e *Estimator) Estimate(ctx context.Context, entries []exposurelog.Entry, dataset evaluation.Dataset) (*Report, error) {
+	exposures := e.attribute(entries)
+	if len(exposures) == 0 {
+		return nil, ErrNoExposures
+	}
+
+	manager := e.factory()
+	defer manager.Close()
+	if len(dataset.Behaviors) > 0 {
+		update := recommendation.ModelUpdate{Behaviors: dataset.Behaviors, Items: dataset.Items, Users: dataset.Users}
+		if err := manager.UpdateModel(ctx, update); err != nil {
+			// 个别引擎训练失败时其余引擎仍可评估
+			e.log.WithError(err).Warn("部分算法引擎训练失败")
+		}
+	}
+
+	algorithms := e.config.Algorithms
+	if len(algorithms) == 0 {
+		var err error
+		if algorithms, err = manager.GetAvailableAlgorithms(ctx); err != nil {
+			return nil, err
+		}
+		sort.Slice(algorithms, func(i, j int) bool { return algorithms[i] < algorithms[j] })
+	}
+
+	report := &Report{Logging: loggingEstimates(exposures)}
+	for _, algorithm := range algorithms {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		engine, exists := manager.GetEngine(algorithm)
+		if !exists {
+			return nil, fmt.Errorf("算法未注册: %s", algorithm)
+		}
+
+		estimate := e.estimateAlgorithm(ctx, algorithm, engine, exposures)
+		e.log.WithFields(logrus.Fields{
+			"algorithm": algorithm,
+			"replay":    estimate.Replay,
+			"ips":       estimate.IPS,
+			"snips":     estimate.SNIPS,
+			"matched":   estimate.Matched,
+		}).Info("完成算法反事实评估")
+		report.Targets = append(report.Targets, estimate)
+	}
+	return report, nil
+}
+
+// 估计单个目标算法的点击率：目标算法对记录的请求重新推荐，记录的倾向性是在前面位置已选定的条件下的概率，
+// 因此目标算法与记录策略从第一个位置起逐位给出同一物品时才计为匹配，匹配位置的点击以前缀倾向性乘积的倒数加权
+func (e *Estimator) estimateAlgorithm(ctx context.Context, algorithm recommendation.AlgorithmType, engine recommendation.RecommendationEngine, exposures []exposure) Estimate {
+	estimate := Estimate{Algorithm: string(algorithm)}
+	var replayClicks, weighted, weightSum, weightSquares float64
+
+	for _, shown := range exposures {
+		ranked, err := e.rerank(ctx, algorithm, engine, shown.entry)
+		if err != nil {
+			estimate.Errors++
+			continue
+		}
+		estimate.Exposures++
+
+		propensity, matching := 1.0, true
+		for i, position := range shown.entry.Shown {
+			if position.Propensity <= 0 {
+				// 之后位置的前缀倾向性都无法计算
+				estimate.UnknownPropensity += len(shown.entry.Shown) - i
+				break
+			}
+			estimate.Positions++
+			if shown.clicked[i] {
+				estimate.Clicks++
+			}
+			propensity *= position.Propensity
+			matching = matching && i < len(ranked) && ranked[i] == position.ItemID
+			if !matching {
+				continue
+			}
+
+			estimate.Matched++
+			reward := 0.0
+			if shown.clicked[i] {
+				reward = 1
+				replayClicks++
+			}
+			weight := 1 / max(propensity, e.config.MinPropensity)
+			weighted += weight * reward
+			weightSum += weight
+			weightSquares += weight * weight
+		}
+	}
+
+	if estimate.Matched > 0 {
+		estimate.Replay = replayClicks / float64(estimate.Matched)
+	}
+	if estimate.Positions > 0 {
+		estimate.IPS = weighted / float64(estimate.Positions)
+	}
+	if weightSum > 0 {
+		estimate.SNIPS = weighted / weightSum
+		estimate.EffectiveSampleSize = weightSum * weightSum / weightSquares
+	}
+	return estimate
+}
+
+// 目标算法对记录的请求给出的前 len(Shown) 个物品
+func (e *Estimator) rerank(ctx context.Context, algorithm recommendation.AlgorithmType, engine recommendation.RecommendationEngine, entry exposurelog.Entry) ([]string, error) {
+	limit := max(len(entry.Candidates), len(entry.Shown), e.config.PoolSize)
+	response, err := engine.Recommend(ctx, recommendation.RecommendationRequest{
+		UserID:    entry.UserID,
+		Scenario:  recommendation.RecommendationScenario(entry.Scenario),
+		Context:   entry.Context,
+		Limit:     limit,
+		Algorithm: algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates map[string]bool
+	if e.config.RestrictToCandidates && len(entry.Candidates) > 0 {
+		candidates = make(map[string]bool, len(entry.Candidates))
+		for _, candidate := range entry.Candidates {
+			candidates[candidate.ItemID] = true
+		}
+	}
+
+	ranked := make([]string, 0, len(entry.Shown))
+	for _, result := range response.Recommendations {
+		if len(ranked) == len(entry.Shown) {
+			break
+		}
+		if candidates != nil && !candidates[result.ItemID] {
+			continue
+		}
+		ranked = append(ranked, result.ItemID)
+	}
+	return ranked, nil
+}
+
+// 将正反馈归因到同一用户在归因窗口内最近一次展示该物品的位置，同一位置只计一次点击
+func (e *Estimator) attribute(entries []exposurelog.Entry) []exposure {
+	exposures := make([]exposure, 0)
+	feedbacks := make([]exposurelog.Entry, 0)
+	for _, entry := range entries {
+		switch entry.Type {
+		case exposurelog.EntryExposure:
+			if len(entry.Shown) > 0 {
+				exposures = append(exposures, exposure{entry: entry, clicked: make([]bool, len(entry.Shown))})
+			}
+		case exposurelog.EntryFeedback:
+			if entry.Reward > 0 {
+				feedbacks = append(feedbacks, entry)
+			}
+		}
+	}
+	sort.SliceStable(exposures, func(i, j int) bool {
+		return exposures[i].entry.Timestamp.Before(exposures[j].entry.Timestamp)
+	})
+
+	byUser := make(map[string][]int)
+	for i, shown := range exposures {
+		byUser[shown.entry.UserID] = append(byUser[shown.entry.UserID], i)
+	}
+
+	for _, feedback := range feedbacks {
+		indexes := byUser[feedback.UserID]
+		// 反馈时刻之前的最后一次展示
+		last := sort.Search(len(indexes), func(i int) bool {
+			return exposures[indexes[i]].entry.Timestamp.After(feedback.Timestamp)
+		}) - 1
+		for j := last; j >= 0; j-- {
+			shown := &exposures[indexes[j]]
+			if feedback.Timestamp.Sub(shown.entry.Timestamp) > e.config.AttributionWindow {
+				break
+			}
+			if position := shownPosition(shown.entry, feedback.ItemID); position >= 0 {
+				shown.clicked[position] = true
+				break
+			}
+		}
+	}
+	return exposures
+}
+
+// 物品在展示中的下标，未展示时为-1
+func shownPosition(entry exposurelog.Entry, itemID string) int {
+	for i, position := range entry.Shown {
+		if position.ItemID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// 各记录策略在日志中观测到的点击率
+func loggingEstimates(exposures []exposure) []Estimate {
+	byAlgorithm := make(map[string]*Estimate)
+	names := make([]string, 0)
+	for _, shown := range exposures {
+		estimate, exists := byAlgorithm[shown.entry.Algorithm]
+		if !exists {
+			estimate = &Estimate{Algorithm: shown.entry.Algorithm}
+			byAlgorithm[shown.entry.Algorithm] = estimate
+			names = append(names, shown.entry.Algorithm)
+		}
+		estimate.Exposures++
+		for i := range shown.entry.Shown {
+			estimate.Positions++
+			estimate.Matched++
+			if shown.clicked[i] {
+				estimate.Clicks++
+			}
+		}
+	}
+	sort.Strings(names)
+
+	estimates := make([]Estimate, 0, len(names))
+	for _, name := range names {
+		estimate := byAlgorithm[name]
+		if estimate.Positions > 0 {
+			ctr := float64(estimate.Clicks) / float64(estimate.Positions)
+			estimate.Replay, estimate.IPS, estimate.SNIPS = ctr, ctr, ctr
+			estimate.EffectiveSampleSize = float64(estimate.Positions)
+		}
+		estimates = append(estimates, *estimate)
+	}
+	return estimates
+}
@@ -0,0 +1,51 @@
+package offpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteJSON 以 JSON 输出反事实评估报告
+func WriteJSON(w io.Writer, report *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("输出反事实评估报告失败: %w", err)
+	}
+	return nil
+}
+
+// WriteTable 以表格输出反事实评估报告，先列出记录策略的观测点击率，再列出目标算法的估计
+func WriteTable(w io.Writer, report *Report) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "logging\texposures\tpositions\tclicks\tctr\t")
+	for _, estimate := range report.Logging {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%.4f\t\n",
+			estimate.Algorithm,
+			estimate.Exposures,
+			estimate.Positions,
+			estimate.Clicks,
+			estimate.Replay,
+		)
+	}
+	fmt.Fprintln(table)
+	fmt.Fprintln(table, "algorithm\tmatched\treplay_ctr\tips_ctr\tsnips_ctr\tess\tunknown\terrors\t")
+	for _, estimate := range report.Targets {
+		fmt.Fprintf(table, "%s\t%d\t%.4f\t%.4f\t%.4f\t%.1f\t%d\t%d\t\n",
+			estimate.Algorithm,
+			estimate.Matched,
+			estimate.Replay,
+			estimate.IPS,
+			estimate.SNIPS,
+			estimate.EffectiveSampleSize,
+			estimate.UnknownPropensity,
+			estimate.Errors,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("输出反事实评估报告失败: %w", err)
+	}
+	return nil
+}
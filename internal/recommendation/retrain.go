@@ -0,0 +1,106 @@
+package recommendation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	retrainMinInteractions = 1000      // 上次训练后新增交互数达到该值时在后台重新训练
+	retrainInterval        = time.Hour // 距上次训练超过该时间且有新增交互时在后台重新训练
+)
+
+// retrainer 矩阵分解、BPR等批量训练模型的延迟重训练：模型首次训练在调用方同步完成，保证批量加载后立即可用；
+// 之后只累计新增交互，达到阈值或间隔后在后台重新训练，同一时间最多一个训练任务，
+// 两次训练之间的单条交互由折叠（fold-in）即时更新用户向量
+type retrainer struct {
+	mu          sync.Mutex
+	name        string
+	train       func()
+	pending     int
+	trained     bool
+	running     bool
+	lastTrained time.Time
+	done        sync.WaitGroup
+	log         *logrus.Logger
+}
+
+// newRetrainer 创建延迟重训练器
+func newRetrainer(name string, train func(), log *logrus.Logger) *retrainer {
+	return &retrainer{
+		name:  name,
+		train: train,
+		log:   log,
+	}
+}
+
+// add 记录新增交互数，模型从未训练过时同步训练并返回 true，否则按需启动后台训练并返回 false
+func (r *retrainer) add(count int) bool {
+	if count <= 0 {
+		return false
+	}
+
+	r.mu.Lock()
+	r.pending += count
+	if !r.trained {
+		r.trained = true
+		r.running = true
+		r.pending = 0
+		r.mu.Unlock()
+		r.run()
+		return true
+	}
+
+	due := r.pending >= retrainMinInteractions || time.Since(r.lastTrained) >= retrainInterval
+	if r.running || !due {
+		r.mu.Unlock()
+		return false
+	}
+	r.running = true
+	pending := r.pending
+	r.pending = 0
+	r.done.Add(1)
+	r.mu.Unlock()
+
+	r.log.WithFields(logrus.Fields{
+		"model":   r.name,
+		"pending": pending,
+	}).Info("后台重新训练模型")
+	go func() {
+		defer r.done.Done()
+		r.run()
+	}()
+	return false
+}
+
+// retrain 配置变更使已训练的模型失效时同步重新训练；模型从未训练过时由下一次 add 完成首次训练
+func (r *retrainer) retrain() {
+	r.mu.Lock()
+	if !r.trained {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.pending = 0
+	r.mu.Unlock()
+
+	r.log.WithField("model", r.name).Info("配置变更，重新训练模型")
+	r.run()
+}
+
+// 执行训练并记录训练时间
+func (r *retrainer) run() {
+	r.train()
+
+	r.mu.Lock()
+	r.running = false
+	r.lastTrained = time.Now()
+	r.mu.Unlock()
+}
+
+// wait 等待进行中的后台训练完成
+func (r *retrainer) wait() {
+	r.done.Wait()
+}
@@ -0,0 +1,343 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 基于规则的推荐引擎适配器
+type RuleBasedAdapter struct {
+	engine *algorithms.RuleBasedEngine
+	log    *logrus.Logger
+}
+
+// 创建新的规则推荐引擎适配器
+func NewRuleBasedAdapter(engine *algorithms.RuleBasedEngine, log *logrus.Logger) *RuleBasedAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewRuleBasedEngine(log)
+	}
+
+	return &RuleBasedAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐
+func (a *RuleBasedAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	matches := a.engine.Recommend(requestFacts(request), resolveLimit(request.Limit))
+
+	results := make([]RecommendationResult, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, RecommendationResult{
+			ItemID:     match.ItemID,
+			Score:      match.Score,
+			Reason:     ruleReason(match.Rule),
+			Algorithm:  AlgorithmRuleBased,
+			Confidence: math.Min(1.0, match.Score),
+			Metadata: map[string]interface{}{
+				"rule":     match.Rule.Name,
+				"priority": match.Rule.Priority,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmRuleBased,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 批量生成推荐
+func (a *RuleBasedAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释，没有请求上下文时只能匹配不依赖上下文的规则
+func (a *RuleBasedAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	rule, matched := a.engine.MatchItem(requestFacts(RecommendationRequest{UserID: userID}), itemID)
+	if !matched {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为命中规则 %s：%s", itemID, rule.Name, ruleReason(rule)), nil
+}
+
+// 更新推荐模型，写入物品属性供规则匹配
+func (a *RuleBasedAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range update.Items {
+		attributes := make(map[string]interface{}, len(item.Features)+len(item.Metadata))
+		for key, value := range item.Metadata {
+			attributes[key] = value
+		}
+		for key, value := range item.Features {
+			attributes[key] = value
+		}
+
+		tags := make([]string, 0)
+		if values, ok := item.Features["tags"].([]string); ok {
+			tags = values
+		}
+
+		a.engine.AddItem(algorithms.RuleItem{
+			ItemID:     item.ItemID,
+			Category:   item.Category,
+			Title:      item.Title,
+			Tags:       tags,
+			Attributes: attributes,
+		})
+	}
+
+	a.log.WithField("items", len(update.Items)).Debug("更新规则引擎物品")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *RuleBasedAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmRuleBased}, nil
+}
+
+// 获取算法参数
+func (a *RuleBasedAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	rules := a.engine.GetRules()
+	values := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		values = append(values, ruleToMap(rule))
+	}
+	return map[string]interface{}{
+		"rules": values,
+	}, nil
+}
+
+// 设置算法参数，rules 为规则列表，格式与配置文件一致
+func (a *RuleBasedAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	for name, value := range parameters {
+		if name != "rules" {
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+
+		rules, err := ParseRules(value)
+		if err != nil {
+			return err
+		}
+		if err := a.engine.SetRules(rules); err != nil {
+			return &RecommendationError{Message: "规则校验失败: " + err.Error()}
+		}
+	}
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *RuleBasedAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	return a.engine.GetStats(), nil
+}
+
+// 记录用户反馈，规则推荐不依赖用户反馈
+func (a *RuleBasedAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	_, err := feedbackToRating(feedback)
+	return err
+}
+
+// 关闭推荐引擎
+func (a *RuleBasedAdapter) Close() error {
+	a.log.Info("关闭规则推荐引擎")
+	return nil
+}
+
+// ParseRules 解析规则配置
+//
+//	rules:
+//	  - name: cart_accessories
+//	    priority: 10
+//	    score: 0.9
+//	    reason: 与购物车商品同品牌的配件
+//	    conditions:
+//	      - {field: scenario, operator: eq, value: shopping_cart}
+//	    item_conditions:
+//	      - {field: category, operator: eq, value: accessories}
+//	      - {field: brand, operator: in, value: $context.cart_brands}
+func ParseRules(value interface{}) ([]algorithms.Rule, error) {
+	switch v := value.(type) {
+	case []algorithms.Rule:
+		return v, nil
+	case []interface{}:
+		rules := make([]algorithms.Rule, 0, len(v))
+		for i, element := range v {
+			fields, ok := toStringMap(element)
+			if !ok {
+				return nil, &RecommendationError{Message: fmt.Sprintf("第%d条规则格式错误", i+1)}
+			}
+			rule, err := parseRule(fields)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		return rules, nil
+	case []map[string]interface{}:
+		values := make([]interface{}, len(v))
+		for i, element := range v {
+			values[i] = element
+		}
+		return ParseRules(values)
+	default:
+		return nil, invalidParameterError("rules", value)
+	}
+}
+
+// 解析单条规则
+func parseRule(fields map[string]interface{}) (algorithms.Rule, error) {
+	rule := algorithms.Rule{}
+	rule.Name, _ = fields["name"].(string)
+	rule.Description, _ = fields["description"].(string)
+	rule.Reason, _ = fields["reason"].(string)
+	rule.SortBy, _ = fields["sort_by"].(string)
+
+	if value, exists := fields["priority"]; exists {
+		priority, ok := toInt(value)
+		if !ok {
+			return rule, invalidParameterError("priority", value)
+		}
+		rule.Priority = priority
+	}
+	if value, exists := fields["limit"]; exists {
+		limit, ok := toInt(value)
+		if !ok || limit < 0 {
+			return rule, invalidParameterError("limit", value)
+		}
+		rule.Limit = limit
+	}
+	if value, exists := fields["score"]; exists {
+		score, ok := toFloat64(value)
+		if !ok || score < 0 {
+			return rule, invalidParameterError("score", value)
+		}
+		rule.Score = score
+	}
+
+	var err error
+	if rule.Conditions, err = parseConditions(fields["conditions"]); err != nil {
+		return rule, err
+	}
+	if rule.ItemConditions, err = parseConditions(fields["item_conditions"]); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// 解析条件列表
+func parseConditions(value interface{}) ([]algorithms.RuleCondition, error) {
+	if value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, invalidParameterError("conditions", value)
+	}
+
+	conditions := make([]algorithms.RuleCondition, 0, len(values))
+	for _, element := range values {
+		fields, ok := toStringMap(element)
+		if !ok {
+			return nil, invalidParameterError("conditions", element)
+		}
+		field, _ := fields["field"].(string)
+		operator, _ := fields["operator"].(string)
+		if operator == "" {
+			operator = algorithms.RuleOperatorEq
+		}
+		conditions = append(conditions, algorithms.RuleCondition{
+			Field:    field,
+			Operator: operator,
+			Value:    fields["value"],
+		})
+	}
+	return conditions, nil
+}
+
+// 规则转换为与配置一致的结构
+func ruleToMap(rule algorithms.Rule) map[string]interface{} {
+	conditions := func(values []algorithms.RuleCondition) []interface{} {
+		result := make([]interface{}, 0, len(values))
+		for _, condition := range values {
+			result = append(result, map[string]interface{}{
+				"field":    condition.Field,
+				"operator": condition.Operator,
+				"value":    condition.Value,
+			})
+		}
+		return result
+	}
+
+	return map[string]interface{}{
+		"name":            rule.Name,
+		"description":     rule.Description,
+		"priority":        rule.Priority,
+		"score":           rule.Score,
+		"limit":           rule.Limit,
+		"sort_by":         rule.SortBy,
+		"reason":          rule.Reason,
+		"conditions":      conditions(rule.Conditions),
+		"item_conditions": conditions(rule.ItemConditions),
+	}
+}
+
+// 规则推荐理由
+func ruleReason(rule algorithms.Rule) string {
+	if rule.Reason != "" {
+		return rule.Reason
+	}
+	if rule.Description != "" {
+		return rule.Description
+	}
+	return "命中推荐规则: " + rule.Name
+}
+
+// 请求字段，供规则条件求值
+func requestFacts(request RecommendationRequest) map[string]interface{} {
+	facts := map[string]interface{}{
+		"user_id":  request.UserID,
+		"scenario": string(request.Scenario),
+	}
+	if request.Context != nil {
+		facts["context"] = request.Context
+	}
+	if request.Filters != nil {
+		facts["filters"] = request.Filters
+	}
+	return facts
+}
+
+// 转换为 map[string]interface{}，兼容YAML解析出的 map[interface{}]interface{}
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, element := range v {
+			result[fmt.Sprint(key)] = element
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,230 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 基于会话的推荐引擎适配器
+type SessionBasedAdapter struct {
+	engine *algorithms.SessionBasedEngine
+	log    *logrus.Logger
+}
+
+// 创建新的会话推荐引擎适配器
+func NewSessionBasedAdapter(engine *algorithms.SessionBasedEngine, log *logrus.Logger) *SessionBasedAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewSessionBasedEngine(log)
+	}
+
+	return &SessionBasedAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐，当前会话取自 Context 中的 session_items，
+// 否则取 session_id（缺省为用户ID）对应的进行中会话
+func (a *SessionBasedAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sessionItems := a.currentSessionItems(request.UserID, request.Context)
+	recs := a.engine.Recommend(sessionItems, resolveLimit(request.Limit))
+
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     "浏览过相同物品的用户接下来常看",
+			Algorithm:  AlgorithmSessionBased,
+			Confidence: rec.Score,
+			Metadata: map[string]interface{}{
+				"session_length": len(sessionItems),
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmSessionBased,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 批量生成推荐
+func (a *SessionBasedAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *SessionBasedAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	sessionItems := a.engine.SessionItems(userID)
+	if len(sessionItems) == 0 {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+
+	lastItem := sessionItems[len(sessionItems)-1]
+	if probability := a.engine.TransitionProbability(lastItem, itemID); probability > 0 {
+		return fmt.Sprintf("推荐物品 %s 是因为浏览物品 %s 的用户有%.0f%%接下来查看了它", itemID, lastItem, probability*100), nil
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为它常与您本次浏览的物品出现在同一会话中", itemID), nil
+}
+
+// 更新推荐模型，按时间顺序回放用户行为构建会话
+func (a *SessionBasedAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	behaviors := append([]datacollection.UserBehavior(nil), update.Behaviors...)
+	sort.SliceStable(behaviors, func(i, j int) bool {
+		return behaviors[i].Timestamp.Before(behaviors[j].Timestamp)
+	})
+	for _, behavior := range behaviors {
+		a.engine.AddEvent(sessionKey(behavior.UserID, behavior.Context), behavior.UserID, behavior.ItemID, behavior.Timestamp)
+	}
+
+	expired := a.engine.ExpireSessions(time.Now())
+
+	a.log.WithFields(logrus.Fields{
+		"behaviors": len(behaviors),
+		"expired":   expired,
+	}).Debug("更新会话推荐模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *SessionBasedAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmSessionBased}, nil
+}
+
+// 获取算法参数
+func (a *SessionBasedAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"idle_timeout":    config.IdleTimeout.String(),
+		"neighbors":       config.Neighbors,
+		"sample_size":     config.SampleSize,
+		"markov_weight":   config.MarkovWeight,
+		"min_session_len": config.MinSessionLen,
+		"max_sessions":    config.MaxSessions,
+	}, nil
+}
+
+// 设置算法参数
+func (a *SessionBasedAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "idle_timeout":
+			text, _ := value.(string)
+			timeout, err := time.ParseDuration(text)
+			if err != nil || timeout <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.IdleTimeout = timeout
+		case "neighbors", "sample_size", "min_session_len", "max_sessions":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "neighbors":
+				config.Neighbors = v
+			case "sample_size":
+				config.SampleSize = v
+			case "min_session_len":
+				config.MinSessionLen = v
+			default:
+				config.MaxSessions = v
+			}
+		case "markov_weight":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 || v > 1 {
+				return invalidParameterError(name, value)
+			}
+			config.MarkovWeight = v
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *SessionBasedAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	if session, exists := a.engine.GetSession(userID); exists {
+		stats["session_id"] = session.ID
+		stats["session_start"] = session.StartTime
+		stats["session_length"] = len(a.engine.SessionItems(userID))
+	}
+	return stats, nil
+}
+
+// 记录用户反馈，正反馈作为会话事件实时写入
+func (a *SessionBasedAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	parsed, err := ParseFeedback(userID, itemID, feedback)
+	if err != nil {
+		return err
+	}
+	if parsed.IsNegative() {
+		return nil
+	}
+
+	a.engine.AddEvent(sessionKey(userID, parsed.Context), userID, itemID, parsed.Timestamp)
+	return nil
+}
+
+// 关闭推荐引擎
+func (a *SessionBasedAdapter) Close() error {
+	a.log.Info("关闭会话推荐引擎")
+	return nil
+}
+
+// 当前会话的物品序列
+func (a *SessionBasedAdapter) currentSessionItems(userID string, requestContext map[string]interface{}) []string {
+	if values, ok := requestContext["session_items"]; ok {
+		items := make([]string, 0)
+		switch v := values.(type) {
+		case []string:
+			items = append(items, v...)
+		case []interface{}:
+			for _, value := range v {
+				if itemID, ok := value.(string); ok {
+					items = append(items, itemID)
+				}
+			}
+		}
+		if len(items) > 0 {
+			return items
+		}
+	}
+	return a.engine.SessionItems(sessionKey(userID, requestContext))
+}
+
+// 会话键，优先使用上下文中的 session_id，匿名用户可只传 session_id
+func sessionKey(userID string, context map[string]interface{}) string {
+	if sessionID, ok := context["session_id"].(string); ok && sessionID != "" {
+		return sessionID
+	}
+	return userID
+}
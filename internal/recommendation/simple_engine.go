@@ -2,143 +2,521 @@ package recommendation
 
 import (
 	"context"
+	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/guanguoyintao/luban/internal/datacollection"
+	"github.com/guanguoyintao/luban/internal/datacollection/datasource"
 	"github.com/guanguoyintao/luban/internal/dataprocessing"
 	"github.com/guanguoyintao/luban/internal/domain"
+	"github.com/guanguoyintao/luban/internal/recommendation/strategy"
 )
 
-// SimpleRecommendationEngine 简单推荐引擎实现
+// 召回通道对应的推荐理由
+var recallReasons = map[string]string{
+	"popular_items":       "热门推荐",
+	"similar_users":       "与您相似的用户也喜欢",
+	"recent_behavior":     "与您最近浏览的内容相关",
+	"category_preference": "符合您的类别偏好",
+	"embedding":           "与您的兴趣向量相近",
+}
+
+// SimpleRecommendationConfig 推荐流水线配置
+type SimpleRecommendationConfig struct {
+	RecallTypes      []string                  // 召回类型
+	Algorithms       []AlgorithmType           // 参与打分的算法，为空时使用全部已注册算法
+	AlgorithmWeights map[AlgorithmType]float64 // 各算法得分的融合权重，未配置的算法权重为1
+	RecallWeight     float64                   // 召回得分在最终得分中的权重
+	CandidateFactor  int                       // 不支持候选打分的算法返回结果数量相对推荐数量的倍数
+}
+
+// algorithmWeight 获取算法得分的融合权重
+func (c *SimpleRecommendationConfig) algorithmWeight(algorithm AlgorithmType) float64 {
+	if weight, exists := c.AlgorithmWeights[algorithm]; exists {
+		return weight
+	}
+	return 1
+}
+
+// SimpleRecommendationEngine 推荐流水线：多路召回 → 算法打分 → 策略排序
 type SimpleRecommendationEngine struct {
+	mu            sync.RWMutex
 	logger        *logrus.Logger
-	dataCollector datacollection.DataCollector
+	dataSource    *datasource.MultiDataSource
 	dataProcessor dataprocessing.DataProcessor
+	engine        RecommendationEngine
+	strategies    []strategy.RankingStrategy
+	config        *SimpleRecommendationConfig
+}
+
+// 候选物品打分结果
+type candidateScore struct {
+	score      float64
+	confidence float64
+	reason     string
+	algorithm  AlgorithmType
 }
 
-// NewRecommendationEngine 创建推荐引擎
-func NewRecommendationEngine(
+// NewSimpleRecommendationEngine 创建推荐引擎
+func NewSimpleRecommendationEngine(
 	logger *logrus.Logger,
-	dataCollector datacollection.DataCollector,
+	dataSource *datasource.MultiDataSource,
 	dataProcessor dataprocessing.DataProcessor,
-	contentBased interface{},
-	collaborative interface{},
-	hybrid interface{},
+	engine RecommendationEngine,
+	strategies []strategy.RankingStrategy,
 ) *SimpleRecommendationEngine {
+	if logger == nil {
+		logger = logrus.New()
+	}
+
 	return &SimpleRecommendationEngine{
 		logger:        logger,
-		dataCollector: dataCollector,
+		dataSource:    dataSource,
 		dataProcessor: dataProcessor,
+		engine:        engine,
+		strategies:    strategies,
+		config: &SimpleRecommendationConfig{
+			RecallTypes: []string{"popular", "similar_users", "category_preference", "embedding"},
+			Algorithms: []AlgorithmType{
+				AlgorithmHybridFiltering,
+				AlgorithmMatrixFactorization,
+				AlgorithmBPR,
+				AlgorithmDeepLearning,
+			},
+			RecallWeight:    0.3,
+			CandidateFactor: 5,
+		},
 	}
 }
 
 // GetRecommendations 获取推荐
 func (e *SimpleRecommendationEngine) GetRecommendations(ctx context.Context, userID string, count int) ([]domain.Recommendation, error) {
+	return e.recommend(ctx, userID, "", count)
+}
+
+// GetRecommendationsByCategory 按类别获取推荐
+func (e *SimpleRecommendationEngine) GetRecommendationsByCategory(ctx context.Context, userID string, category string, count int) ([]domain.Recommendation, error) {
+	return e.recommend(ctx, userID, category, count)
+}
+
+// recommend 执行推荐流水线，category为空时不按类别过滤
+func (e *SimpleRecommendationEngine) recommend(ctx context.Context, userID string, category string, count int) ([]domain.Recommendation, error) {
+	startTime := time.Now()
+	config := e.GetConfig()
+	if count <= 0 {
+		count = defaultRecommendationLimit
+	}
+
 	e.logger.WithFields(logrus.Fields{
-		"user_id": userID,
-		"count":   count,
+		"user_id":  userID,
+		"category": category,
+		"count":    count,
 	}).Info("开始生成推荐")
 
-	// 创建用户数据
-	userData := datacollection.UserData{
-		UserID: userID,
-		Demographics: map[string]interface{}{
-			"age":    25,
-			"gender": "male",
-		},
-		Preferences: map[string]interface{}{
-			"categories": []string{"technology", "sports"},
-		},
+	// 多路召回候选物品
+	candidates, err := e.recall(ctx, userID, config.RecallTypes)
+	if err != nil {
+		return nil, err
 	}
 
-	// 处理用户数据
-	processedData, err := e.dataProcessor.CleanUserData(ctx, userData)
-	if err != nil {
-		e.logger.WithError(err).Error("处理用户数据失败")
-		// 即使处理失败，我们也可以返回模拟推荐
-		e.logger.Info("使用默认推荐数据")
-	}
-
-	_ = processedData // 使用处理后的数据
-
-	// 模拟推荐结果
-	recommendations := []domain.Recommendation{
-		{
-			ItemID:     "item_001",
-			Score:      0.95,
-			Reason:     "基于您的历史偏好推荐",
-			Algorithm:  "hybrid_filtering",
-			Confidence: 0.9,
-			CreatedAt:  time.Now(),
-			Category:   "technology",
-		},
-		{
-			ItemID:     "item_002",
-			Score:      0.87,
-			Reason:     "与您相似的用户也喜欢",
-			Algorithm:  "collaborative_filtering",
-			Confidence: 0.8,
-			CreatedAt:  time.Now(),
-			Category:   "sports",
-		},
-		{
-			ItemID:     "item_003",
-			Score:      0.82,
-			Reason:     "内容特征匹配",
-			Algorithm:  "content_based_filtering",
-			Confidence: 0.75,
-			CreatedAt:  time.Now(),
-			Category:   "technology",
-		},
+	if category != "" {
+		filtered := make([]datasource.ItemRecord, 0, len(candidates))
+		for _, item := range candidates {
+			if item.Category == category {
+				filtered = append(filtered, item)
+			}
+		}
+		candidates = filtered
 	}
 
-	// 限制推荐数量
-	if count > 0 && count < len(recommendations) {
-		recommendations = recommendations[:count]
+	candidates = e.cleanCandidates(ctx, candidates)
+	if len(candidates) == 0 {
+		e.logger.WithField("user_id", userID).Info("没有可用的候选物品")
+		return []domain.Recommendation{}, nil
 	}
 
+	// 算法引擎为召回候选打分
+	scores := e.score(ctx, userID, candidates, config, count*config.CandidateFactor)
+
+	recommendations := make([]domain.Recommendation, 0, len(candidates))
+	now := time.Now()
+	for _, item := range candidates {
+		recall := recallScore(item)
+		rec := domain.Recommendation{
+			ItemID:     item.ItemID,
+			Score:      config.RecallWeight * recall,
+			Reason:     recallReason(item),
+			Algorithm:  "recall",
+			Confidence: 0.5 * recall,
+			CreatedAt:  now,
+			Category:   item.Category,
+		}
+		if scored, exists := scores[item.ItemID]; exists {
+			rec.Score = config.RecallWeight*recall + (1-config.RecallWeight)*scored.score
+			rec.Reason = scored.reason
+			rec.Algorithm = string(scored.algorithm)
+			rec.Confidence = scored.confidence
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	// 排序策略链
+	recommendations, err = e.rank(ctx, userID, recommendations)
+	if err != nil {
+		return nil, err
+	}
+
+	// 在最终列表上探索并截取展示的位置
+	recommendations = e.serve(userID, category, count, recommendations)
+
 	e.logger.WithFields(logrus.Fields{
 		"user_id":         userID,
+		"category":        category,
+		"candidates":      len(candidates),
+		"scored":          len(scores),
 		"recommendations": len(recommendations),
+		"processing_time": time.Since(startTime).Milliseconds(),
 	}).Info("推荐生成成功")
 
 	return recommendations, nil
 }
 
-// GetRecommendationsByCategory 按类别获取推荐
-func (e *SimpleRecommendationEngine) GetRecommendationsByCategory(ctx context.Context, userID string, category string, count int) ([]domain.Recommendation, error) {
-	e.logger.WithFields(logrus.Fields{
-		"user_id":  userID,
-		"category": category,
-		"count":    count,
-	}).Info("开始按类别生成推荐")
+// listServer 在流水线最终展示的列表上探索，由推荐引擎管理器实现
+type listServer interface {
+	ServeList(request RecommendationRequest, recommendations []RecommendationResult) []RecommendationResult
+}
+
+// serve 由推荐引擎在排序后的列表上选择展示的前 count 个位置，引擎不支持时直接截取
+func (e *SimpleRecommendationEngine) serve(userID string, category string, count int, recommendations []domain.Recommendation) []domain.Recommendation {
+	server, ok := e.engine.(listServer)
+	if !ok {
+		if len(recommendations) > count {
+			recommendations = recommendations[:count]
+		}
+		return recommendations
+	}
+
+	results := make([]RecommendationResult, len(recommendations))
+	byItem := make(map[string]domain.Recommendation, len(recommendations))
+	for i, rec := range recommendations {
+		results[i] = RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      rec.Score,
+			Reason:     rec.Reason,
+			Algorithm:  AlgorithmType(rec.Algorithm),
+			Confidence: rec.Confidence,
+		}
+		byItem[rec.ItemID] = rec
+	}
+	request := RecommendationRequest{UserID: userID, Limit: count}
+	if category != "" {
+		request.Context = map[string]interface{}{"category": category}
+	}
+
+	served := server.ServeList(request, results)
+	shown := make([]domain.Recommendation, len(served))
+	for i, result := range served {
+		shown[i] = byItem[result.ItemID]
+	}
+	return shown
+}
+
+// recall 多路召回并合并候选物品
+func (e *SimpleRecommendationEngine) recall(ctx context.Context, userID string, recallTypes []string) ([]datasource.ItemRecord, error) {
+	if e.dataSource == nil {
+		return nil, &RecommendationError{Message: "未配置召回数据源"}
+	}
+
+	multiRecall, err := e.dataSource.ParallelRecall(ctx, userID, recallTypes)
+	if err != nil {
+		return nil, &RecommendationError{Message: "多路召回失败: " + err.Error()}
+	}
+
+	return multiRecall.MergeResults(), nil
+}
+
+// cleanCandidates 清洗候选物品，丢弃无效数据
+func (e *SimpleRecommendationEngine) cleanCandidates(ctx context.Context, candidates []datasource.ItemRecord) []datasource.ItemRecord {
+	if e.dataProcessor == nil {
+		return candidates
+	}
 
-	// 获取所有推荐
-	recommendations, err := e.GetRecommendations(ctx, userID, count*2) // 获取更多以便筛选
+	cleaned := make([]datasource.ItemRecord, 0, len(candidates))
+	for _, item := range candidates {
+		_, err := e.dataProcessor.CleanItemData(ctx, dataprocessing.ItemData{
+			ItemID:      item.ItemID,
+			Category:    item.Category,
+			Title:       item.Title,
+			Description: item.Description,
+			Features:    item.Features,
+			Metadata:    item.Metadata,
+		})
+		if err != nil {
+			e.logger.WithError(err).WithField("item_id", item.ItemID).Warn("丢弃无效候选物品")
+			continue
+		}
+		cleaned = append(cleaned, item)
+	}
+	return cleaned
+}
+
+// candidateScoringEngine 为召回候选打分，由推荐引擎管理器实现
+type candidateScoringEngine interface {
+	ScoreCandidates(ctx context.Context, request RecommendationRequest, itemIDs []string) ([]RecommendationResult, error)
+}
+
+// score 并行调用配置的算法引擎为召回候选打分，并按算法权重融合
+// 各算法的得分在候选内做最小-最大归一化，融合得分为已打分算法归一化得分的加权平均，
+// 推荐理由、置信度与算法取加权贡献最大的算法
+func (e *SimpleRecommendationEngine) score(ctx context.Context, userID string, candidates []datasource.ItemRecord, config *SimpleRecommendationConfig, limit int) map[string]candidateScore {
+	scores := make(map[string]candidateScore)
+	if e.engine == nil {
+		return scores
+	}
+
+	algorithms := config.Algorithms
+	if len(algorithms) == 0 {
+		available, err := e.engine.GetAvailableAlgorithms(ctx)
+		if err != nil {
+			e.logger.WithError(err).Warn("获取可用算法失败")
+			return scores
+		}
+		algorithms = available
+	}
+
+	itemIDs := make([]string, len(candidates))
+	candidateSet := make(map[string]bool, len(candidates))
+	for i, item := range candidates {
+		itemIDs[i] = item.ItemID
+		candidateSet[item.ItemID] = true
+	}
+
+	// 各算法独立打分
+	results := make([][]RecommendationResult, len(algorithms))
+	var wg sync.WaitGroup
+	for i, algorithm := range algorithms {
+		wg.Add(1)
+		go func(i int, algorithm AlgorithmType) {
+			defer wg.Done()
+			scored, err := e.scoreWith(ctx, algorithm, userID, itemIDs, limit)
+			if err != nil {
+				e.logger.WithError(err).WithField("algorithm", algorithm).Warn("算法打分失败")
+				return
+			}
+			results[i] = scored
+		}(i, algorithm)
+	}
+	wg.Wait()
+
+	totalWeight := 0.0
+	combined := make(map[string]float64)
+	best := make(map[string]float64)
+	for i, algorithm := range algorithms {
+		weight := config.algorithmWeight(algorithm)
+		normalized := normalizeScores(results[i], candidateSet)
+		if weight <= 0 || len(normalized) == 0 {
+			continue
+		}
+		totalWeight += weight
+
+		for _, result := range results[i] {
+			value, exists := normalized[result.ItemID]
+			if !exists {
+				continue
+			}
+			contribution := weight * value
+			combined[result.ItemID] += contribution
+			if current, seen := best[result.ItemID]; seen && current >= contribution {
+				continue
+			}
+			best[result.ItemID] = contribution
+			algorithmType := result.Algorithm
+			if algorithmType == "" {
+				algorithmType = algorithm
+			}
+			scores[result.ItemID] = candidateScore{
+				confidence: result.Confidence,
+				reason:     result.Reason,
+				algorithm:  algorithmType,
+			}
+		}
+	}
+
+	for itemID, scored := range scores {
+		scored.score = combined[itemID] / totalWeight
+		scores[itemID] = scored
+	}
+	return scores
+}
+
+// scoreWith 用单个算法为候选打分，推荐引擎不支持候选打分时取该算法的推荐结果
+func (e *SimpleRecommendationEngine) scoreWith(ctx context.Context, algorithm AlgorithmType, userID string, itemIDs []string, limit int) ([]RecommendationResult, error) {
+	request := RecommendationRequest{
+		UserID:    userID,
+		Algorithm: algorithm,
+		Limit:     limit,
+	}
+	if scorer, ok := e.engine.(candidateScoringEngine); ok {
+		return scorer.ScoreCandidates(ctx, request, itemIDs)
+	}
+
+	request.Parameters = map[string]interface{}{ParameterScoringOnly: true}
+	response, err := e.engine.Recommend(ctx, request)
 	if err != nil {
 		return nil, err
 	}
+	return response.Recommendations, nil
+}
 
-	// 按类别筛选
-	var filteredRecommendations []domain.Recommendation
-	for _, rec := range recommendations {
-		if rec.Category == category {
-			filteredRecommendations = append(filteredRecommendations, rec)
+// normalizeScores 将算法对候选的得分做最小-最大归一化到[0,1]，非候选物品忽略，得分全部相同时归一化为1
+func normalizeScores(results []RecommendationResult, candidates map[string]bool) map[string]float64 {
+	minScore, maxScore := math.Inf(1), math.Inf(-1)
+	for _, result := range results {
+		if !candidates[result.ItemID] {
+			continue
 		}
+		minScore = math.Min(minScore, result.Score)
+		maxScore = math.Max(maxScore, result.Score)
 	}
 
-	// 限制推荐数量
-	if count > 0 && count < len(filteredRecommendations) {
-		filteredRecommendations = filteredRecommendations[:count]
+	normalized := make(map[string]float64)
+	for _, result := range results {
+		if !candidates[result.ItemID] {
+			continue
+		}
+		if maxScore > minScore {
+			normalized[result.ItemID] = (result.Score - minScore) / (maxScore - minScore)
+		} else {
+			normalized[result.ItemID] = 1
+		}
 	}
+	return normalized
+}
 
-	e.logger.WithFields(logrus.Fields{
-		"user_id":         userID,
-		"category":        category,
-		"recommendations": len(filteredRecommendations),
-	}).Info("按类别推荐生成成功")
+// rank 依次执行排序策略链，没有配置策略时按得分排序
+func (e *SimpleRecommendationEngine) rank(ctx context.Context, userID string, recommendations []domain.Recommendation) ([]domain.Recommendation, error) {
+	strategies := e.GetStrategies()
+	if len(strategies) == 0 {
+		sort.SliceStable(recommendations, func(i, j int) bool {
+			return recommendations[i].Score > recommendations[j].Score
+		})
+		return recommendations, nil
+	}
+
+	var err error
+	for _, rankingStrategy := range strategies {
+		recommendations, err = rankingStrategy.Rank(ctx, recommendations, userID)
+		if err != nil {
+			e.logger.WithError(err).WithField("strategy", rankingStrategy.GetName()).Error("排序策略执行失败")
+			return nil, &RecommendationError{Message: "排序策略执行失败: " + err.Error()}
+		}
+	}
+	return recommendations, nil
+}
+
+// recallScore 召回得分：召回通道分数与物品热度的乘积
+func recallScore(item datasource.ItemRecord) float64 {
+	if popularity, ok := item.Metadata["popularity"].(float64); ok && popularity > 0 {
+		return item.Popularity * popularity
+	}
+	return item.Popularity
+}
+
+// recallReason 根据命中的召回通道生成推荐理由
+func recallReason(item datasource.ItemRecord) string {
+	if sources, ok := item.Metadata["sources"].([]string); ok {
+		for _, source := range sources {
+			if reason, exists := recallReasons[source]; exists {
+				return reason
+			}
+		}
+	}
+	return "为您推荐"
+}
+
+// TrainFromDataSource 用召回数据源导出的全量行为、物品与用户数据训练算法引擎，
+// 服务启动时调用，矩阵分解、BPR、双塔等批量训练的算法在此之后才能参与打分
+func (e *SimpleRecommendationEngine) TrainFromDataSource(ctx context.Context) (int, error) {
+	if e.dataSource == nil || e.engine == nil {
+		return 0, nil
+	}
+
+	snapshot, err := e.dataSource.Export(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(snapshot.Behaviors) == 0 {
+		return 0, nil
+	}
+
+	update := ModelUpdate{
+		Behaviors: make([]datacollection.UserBehavior, len(snapshot.Behaviors)),
+		Items:     make([]datacollection.ItemData, len(snapshot.Items)),
+		Users:     make([]datacollection.UserData, len(snapshot.Users)),
+	}
+	for i, behavior := range snapshot.Behaviors {
+		update.Behaviors[i] = datacollection.UserBehavior{
+			UserID:    behavior.UserID,
+			ItemID:    behavior.ItemID,
+			Behavior:  datacollection.UserBehaviorType(behavior.Behavior),
+			Value:     behavior.Value,
+			Timestamp: behavior.Timestamp,
+			Context:   behavior.Context,
+		}
+	}
+	for i, item := range snapshot.Items {
+		update.Items[i] = datacollection.ItemData{
+			ItemID:      item.ItemID,
+			Category:    item.Category,
+			Title:       item.Title,
+			Description: item.Description,
+			Features:    item.Features,
+			Metadata:    item.Metadata,
+		}
+	}
+	for i, user := range snapshot.Users {
+		update.Users[i] = datacollection.UserData{
+			UserID:       user.UserID,
+			Demographics: user.Demographics,
+			Preferences:  user.Preferences,
+			Metadata:     user.BehaviorStats,
+		}
+	}
+
+	if err := e.engine.UpdateModel(ctx, update); err != nil {
+		return len(update.Behaviors), err
+	}
+	return len(update.Behaviors), nil
+}
+
+// SetConfig 设置推荐流水线配置
+func (e *SimpleRecommendationEngine) SetConfig(config *SimpleRecommendationConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.config = config
+}
+
+// GetConfig 获取推荐流水线配置
+func (e *SimpleRecommendationEngine) GetConfig() *SimpleRecommendationConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.config
+}
+
+// SetStrategies 设置排序策略链，按顺序执行
+func (e *SimpleRecommendationEngine) SetStrategies(strategies []strategy.RankingStrategy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.strategies = strategies
+}
 
-	return filteredRecommendations, nil
+// GetStrategies 获取排序策略链
+func (e *SimpleRecommendationEngine) GetStrategies() []strategy.RankingStrategy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.strategies
 }
@@ -25,6 +25,18 @@ func (b *StrategyBuilder) WithDiversity() *StrategyBuilder {
 	return b
 }
 
+// WithMMR 添加最大边际相关多样性策略
+func (b *StrategyBuilder) WithMMR(lambda float64, provider ItemVectorProvider) *StrategyBuilder {
+	b.strategies = append(b.strategies, NewMMRStrategy(lambda, provider))
+	return b
+}
+
+// WithDPP 添加行列式点过程多样性策略
+func (b *StrategyBuilder) WithDPP(lambda float64, provider ItemVectorProvider) *StrategyBuilder {
+	b.strategies = append(b.strategies, NewDPPStrategy(lambda, provider))
+	return b
+}
+
 // WithNovelty 添加新颖性策略
 func (b *StrategyBuilder) WithNovelty() *StrategyBuilder {
 	b.strategies = append(b.strategies, NewNoveltyStrategy())
@@ -37,6 +49,12 @@ func (b *StrategyBuilder) WithPersonalization() *StrategyBuilder {
 	return b
 }
 
+// WithLearningToRank 添加学习排序策略
+func (b *StrategyBuilder) WithLearningToRank(model RankingModel, provider FeatureProvider) *StrategyBuilder {
+	b.strategies = append(b.strategies, NewLTRStrategy(model, provider))
+	return b
+}
+
 // Build 构建策略组合
 func (b *StrategyBuilder) Build() []RankingStrategy {
 	return b.strategies
@@ -0,0 +1,55 @@
+package strategy
+
+import (
+	"context"
+	"sort"
+
+	"github.com/guanguoyintao/luban/internal/domain"
+)
+
+// LTRStrategy 学习排序策略：为每个候选构造特征，按离线训练的排序模型得分重排，
+// 推荐的 Score 替换为模型得分，后续按分数排序的策略保持模型顺序
+type LTRStrategy struct {
+	model    RankingModel
+	provider FeatureProvider
+}
+
+// NewLTRStrategy 创建学习排序策略
+func NewLTRStrategy(model RankingModel, provider FeatureProvider) *LTRStrategy {
+	return &LTRStrategy{
+		model:    model,
+		provider: provider,
+	}
+}
+
+// Rank 按模型得分降序重排，得分相同时保持原顺序
+func (s *LTRStrategy) Rank(ctx context.Context, recommendations []domain.Recommendation, userID string) ([]domain.Recommendation, error) {
+	if len(recommendations) <= 1 {
+		return recommendations, nil
+	}
+
+	features, err := ExtractFeatures(ctx, s.provider, userID, recommendations)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]domain.Recommendation, len(recommendations))
+	for i, rec := range recommendations {
+		rec.Score = s.model.Score(features[i])
+		ranked[i] = rec
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked, nil
+}
+
+// GetName 策略名称
+func (s *LTRStrategy) GetName() string {
+	return "learning_to_rank"
+}
+
+// GetDescription 策略描述
+func (s *LTRStrategy) GetDescription() string {
+	return "基于排序模型的学习排序策略，特征包括混合过滤组件得分、热度、价格、类别偏好与时效性"
+}
@@ -0,0 +1,69 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/guanguoyintao/luban/internal/domain"
+)
+
+// FeatureNames 排序模型的特征，顺序即特征向量的下标；特征不含上游得分，离线训练的模型可用于任意候选列表
+var FeatureNames = []string{
+	"hybrid_score",
+	"collaborative_score",
+	"content_based_score",
+	"diversity_score",
+	"popularity",
+	"price",
+	"category_affinity",
+	"recency",
+}
+
+// CandidateFeatures 候选物品的排序特征
+type CandidateFeatures struct {
+	HybridScore        float64 // 混合过滤得分
+	CollaborativeScore float64 // 混合过滤的协同过滤组件得分
+	ContentBasedScore  float64 // 混合过滤的内容过滤组件得分
+	DiversityScore     float64 // 混合过滤的多样性组件得分
+	Popularity         float64 // 物品热度，取值[0,1]
+	Price              float64 // 对数压缩后的价格，物品没有价格时为0
+	CategoryAffinity   float64 // 用户对物品类别的偏好，取值[0,1]
+	Recency            float64 // 物品时效性，取值[0,1]
+}
+
+// FeatureProvider 提供候选物品的排序特征
+type FeatureProvider interface {
+	CandidateFeatures(ctx context.Context, userID string, itemIDs []string) (map[string]CandidateFeatures, error)
+}
+
+// Vector 按 FeatureNames 的顺序转换为特征向量
+func (f CandidateFeatures) Vector() []float64 {
+	return []float64{
+		f.HybridScore,
+		f.CollaborativeScore,
+		f.ContentBasedScore,
+		f.DiversityScore,
+		f.Popularity,
+		f.Price,
+		f.CategoryAffinity,
+		f.Recency,
+	}
+}
+
+// ExtractFeatures 构造每个候选的特征向量，与 recommendations 的顺序一致，缺少特征的候选为零向量
+func ExtractFeatures(ctx context.Context, provider FeatureProvider, userID string, recommendations []domain.Recommendation) ([][]float64, error) {
+	itemIDs := make([]string, len(recommendations))
+	for i, rec := range recommendations {
+		itemIDs[i] = rec.ItemID
+	}
+	features, err := provider.CandidateFeatures(ctx, userID, itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取排序特征失败: %w", err)
+	}
+
+	vectors := make([][]float64, len(recommendations))
+	for i, rec := range recommendations {
+		vectors[i] = features[rec.ItemID].Vector()
+	}
+	return vectors, nil
+}
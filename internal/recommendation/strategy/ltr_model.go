@@ -0,0 +1,189 @@
+package strategy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+)
+
+// ModelType 排序模型类型
+type ModelType string
+
+const (
+	ModelLogistic   ModelType = "logistic"   // 逐点逻辑回归
+	ModelLambdaMART ModelType = "lambdamart" // 以 LambdaRank 梯度训练的梯度提升树
+)
+
+var (
+	// ErrUnsupportedModel 不支持的排序模型
+	ErrUnsupportedModel = errors.New("不支持的排序模型")
+	// ErrFeatureMismatch 模型的特征与 FeatureNames 不一致
+	ErrFeatureMismatch = errors.New("排序模型的特征与当前特征不一致")
+)
+
+// RankingModel 排序模型，Score 返回[0,1]的排序得分
+type RankingModel interface {
+	Score(features []float64) float64
+	Type() ModelType
+}
+
+// LogisticModel 逐点逻辑回归，特征先按训练集的均值与标准差标准化
+type LogisticModel struct {
+	Weights []float64 `json:"weights"`
+	Bias    float64   `json:"bias"`
+	Mean    []float64 `json:"mean"`
+	Scale   []float64 `json:"scale"`
+}
+
+// Score 点击概率
+func (m *LogisticModel) Score(features []float64) float64 {
+	z := m.Bias
+	for i, weight := range m.Weights {
+		if i < len(features) {
+			z += weight * (features[i] - m.Mean[i]) / m.Scale[i]
+		}
+	}
+	return sigmoid(z)
+}
+
+// Type 模型类型
+func (m *LogisticModel) Type() ModelType {
+	return ModelLogistic
+}
+
+// TreeNode 回归树节点，Leaf 为真时 Value 为叶子输出
+type TreeNode struct {
+	Feature   int     `json:"feature"`
+	Threshold float64 `json:"threshold"` // 特征值不大于阈值时进入左子树
+	Left      int     `json:"left"`
+	Right     int     `json:"right"`
+	Leaf      bool    `json:"leaf"`
+	Value     float64 `json:"value"`
+}
+
+// RegressionTree 回归树，根节点下标为0
+type RegressionTree struct {
+	Nodes []TreeNode `json:"nodes"`
+}
+
+// 树的输出
+func (t RegressionTree) predict(features []float64) float64 {
+	node := t.Nodes[0]
+	for !node.Leaf {
+		value := 0.0
+		if node.Feature < len(features) {
+			value = features[node.Feature]
+		}
+		if value <= node.Threshold {
+			node = t.Nodes[node.Left]
+		} else {
+			node = t.Nodes[node.Right]
+		}
+	}
+	return node.Value
+}
+
+// GBDTModel 梯度提升树，得分为各树输出按学习率加权求和后经 sigmoid 压缩
+type GBDTModel struct {
+	Trees        []RegressionTree `json:"trees"`
+	LearningRate float64          `json:"learning_rate"`
+}
+
+// Score 排序得分
+func (m *GBDTModel) Score(features []float64) float64 {
+	return sigmoid(m.raw(features))
+}
+
+// Type 模型类型
+func (m *GBDTModel) Type() ModelType {
+	return ModelLambdaMART
+}
+
+// 未压缩的模型输出
+func (m *GBDTModel) raw(features []float64) float64 {
+	sum := 0.0
+	for _, tree := range m.Trees {
+		sum += m.LearningRate * tree.predict(features)
+	}
+	return sum
+}
+
+// 模型文件
+type modelFile struct {
+	Type     ModelType      `json:"type"`
+	Features []string       `json:"features"`
+	Logistic *LogisticModel `json:"logistic,omitempty"`
+	GBDT     *GBDTModel     `json:"gbdt,omitempty"`
+}
+
+// WriteRankingModel 以 JSON 写出排序模型与特征名
+func WriteRankingModel(w io.Writer, model RankingModel) error {
+	file := modelFile{Type: model.Type(), Features: FeatureNames}
+	switch m := model.(type) {
+	case *LogisticModel:
+		file.Logistic = m
+	case *GBDTModel:
+		file.GBDT = m
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedModel, model.Type())
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("写出排序模型失败: %w", err)
+	}
+	return nil
+}
+
+// ReadRankingModel 读取 WriteRankingModel 写出的排序模型，特征与 FeatureNames 不一致时返回 ErrFeatureMismatch
+func ReadRankingModel(r io.Reader) (RankingModel, error) {
+	var file modelFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("解析排序模型失败: %w", err)
+	}
+	if !slices.Equal(file.Features, FeatureNames) {
+		return nil, fmt.Errorf("%w: %v", ErrFeatureMismatch, file.Features)
+	}
+
+	switch {
+	case file.Type == ModelLogistic && file.Logistic != nil:
+		model := file.Logistic
+		if len(model.Weights) != len(FeatureNames) || len(model.Mean) != len(FeatureNames) || len(model.Scale) != len(FeatureNames) {
+			return nil, fmt.Errorf("%w: 逻辑回归参数维度错误", ErrFeatureMismatch)
+		}
+		return model, nil
+	case file.Type == ModelLambdaMART && file.GBDT != nil:
+		for _, tree := range file.GBDT.Trees {
+			if err := validateTree(tree); err != nil {
+				return nil, err
+			}
+		}
+		return file.GBDT, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedModel, file.Type)
+	}
+}
+
+// 校验回归树的节点引用，避免预测时越界或死循环
+func validateTree(tree RegressionTree) error {
+	if len(tree.Nodes) == 0 {
+		return fmt.Errorf("%w: 回归树为空", ErrUnsupportedModel)
+	}
+	for i, node := range tree.Nodes {
+		if node.Leaf {
+			continue
+		}
+		if node.Left <= i || node.Right <= i || node.Left >= len(tree.Nodes) || node.Right >= len(tree.Nodes) {
+			return fmt.Errorf("%w: 回归树节点 %d 的子节点无效", ErrUnsupportedModel, i)
+		}
+	}
+	return nil
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
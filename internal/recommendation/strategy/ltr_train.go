@@ -0,0 +1,439 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrNoTrainingSamples 没有可用的训练样本
+var ErrNoTrainingSamples = errors.New("没有可用的排序训练样本")
+
+// LTRConfig 排序模型训练配置
+type LTRConfig struct {
+	Model          ModelType
+	Iterations     int     // 逻辑回归的梯度下降轮数
+	Trees          int     // 梯度提升树的棵数
+	MaxDepth       int     // 回归树的最大深度
+	MinLeafSamples int     // 叶子节点的最少样本数
+	LearningRate   float64 // 逻辑回归的步长与梯度提升的收缩系数
+	L2             float64 // 逻辑回归的L2正则系数
+}
+
+// DefaultLTRConfig 默认训练配置：100棵深度3的梯度提升树，收缩系数0.1
+func DefaultLTRConfig() LTRConfig {
+	return LTRConfig{
+		Model:          ModelLambdaMART,
+		Iterations:     300,
+		Trees:          100,
+		MaxDepth:       3,
+		MinLeafSamples: 10,
+		LearningRate:   0.1,
+		L2:             0.001,
+	}
+}
+
+// TrainingSample 一个查询下的候选物品，Label 为相关度，大于0表示正样本
+type TrainingSample struct {
+	QueryID  string    `json:"query_id"`
+	Features []float64 `json:"features"`
+	Label    float64   `json:"label"`
+}
+
+// TrainingQuery 一次排序请求：用户、待排序的候选与各候选的相关度，未列出的候选相关度为0
+type TrainingQuery struct {
+	UserID     string
+	Candidates []string
+	Relevance  map[string]float64
+}
+
+// BuildTrainingSamples 为每个查询的候选构造特征与标签，查询ID为用户ID
+func BuildTrainingSamples(ctx context.Context, provider FeatureProvider, queries []TrainingQuery) ([]TrainingSample, error) {
+	samples := make([]TrainingSample, 0)
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		features, err := provider.CandidateFeatures(ctx, query.UserID, query.Candidates)
+		if err != nil {
+			return nil, fmt.Errorf("获取排序特征失败: %w", err)
+		}
+		for _, itemID := range query.Candidates {
+			samples = append(samples, TrainingSample{
+				QueryID:  query.UserID,
+				Features: features[itemID].Vector(),
+				Label:    query.Relevance[itemID],
+			})
+		}
+	}
+	return samples, nil
+}
+
+// TrainRankingModel 按配置训练逐点逻辑回归或 LambdaMART 梯度提升树
+func TrainRankingModel(samples []TrainingSample, config LTRConfig) (RankingModel, error) {
+	if len(samples) == 0 {
+		return nil, ErrNoTrainingSamples
+	}
+	for _, sample := range samples {
+		if len(sample.Features) != len(FeatureNames) {
+			return nil, fmt.Errorf("%w: 样本特征维度 %d", ErrFeatureMismatch, len(sample.Features))
+		}
+	}
+
+	defaults := DefaultLTRConfig()
+	if config.Iterations <= 0 {
+		config.Iterations = defaults.Iterations
+	}
+	if config.Trees <= 0 {
+		config.Trees = defaults.Trees
+	}
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = defaults.MaxDepth
+	}
+	if config.MinLeafSamples <= 0 {
+		config.MinLeafSamples = defaults.MinLeafSamples
+	}
+	if config.LearningRate <= 0 {
+		config.LearningRate = defaults.LearningRate
+	}
+	if config.L2 < 0 {
+		config.L2 = defaults.L2
+	}
+
+	switch config.Model {
+	case ModelLogistic:
+		return trainLogistic(samples, config), nil
+	case ModelLambdaMART:
+		return trainLambdaMART(samples, config)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedModel, config.Model)
+	}
+}
+
+// EvaluateNDCG 按 score 对每个查询的候选排序，返回有正样本的查询上 NDCG@k 的均值
+func EvaluateNDCG(samples []TrainingSample, score func(features []float64) float64, k int) float64 {
+	total, queries := 0.0, 0
+	for _, group := range groupByQuery(samples) {
+		scores := make([]float64, len(group))
+		for i, index := range group {
+			scores[i] = score(samples[index].Features)
+		}
+		if ndcg, ok := queryNDCG(samples, group, scores, k); ok {
+			total += ndcg
+			queries++
+		}
+	}
+	if queries == 0 {
+		return 0
+	}
+	return total / float64(queries)
+}
+
+// 逐点逻辑回归：标签大于0为正样本，全量梯度下降
+func trainLogistic(samples []TrainingSample, config LTRConfig) *LogisticModel {
+	dimension := len(FeatureNames)
+	model := &LogisticModel{
+		Weights: make([]float64, dimension),
+		Mean:    make([]float64, dimension),
+		Scale:   make([]float64, dimension),
+	}
+
+	// 标准化参数
+	n := float64(len(samples))
+	for _, sample := range samples {
+		for j, value := range sample.Features {
+			model.Mean[j] += value / n
+		}
+	}
+	for _, sample := range samples {
+		for j, value := range sample.Features {
+			diff := value - model.Mean[j]
+			model.Scale[j] += diff * diff / n
+		}
+	}
+	for j := range model.Scale {
+		model.Scale[j] = math.Sqrt(model.Scale[j])
+		if model.Scale[j] < 1e-9 {
+			model.Scale[j] = 1
+		}
+	}
+
+	standardized := make([][]float64, len(samples))
+	for i, sample := range samples {
+		standardized[i] = make([]float64, dimension)
+		for j, value := range sample.Features {
+			standardized[i][j] = (value - model.Mean[j]) / model.Scale[j]
+		}
+	}
+
+	gradient := make([]float64, dimension)
+	for iteration := 0; iteration < config.Iterations; iteration++ {
+		for j := range gradient {
+			gradient[j] = config.L2 * model.Weights[j]
+		}
+		biasGradient := 0.0
+		for i, sample := range samples {
+			z := model.Bias
+			for j, value := range standardized[i] {
+				z += model.Weights[j] * value
+			}
+			label := 0.0
+			if sample.Label > 0 {
+				label = 1
+			}
+			residual := (sigmoid(z) - label) / n
+			for j, value := range standardized[i] {
+				gradient[j] += residual * value
+			}
+			biasGradient += residual
+		}
+		for j := range model.Weights {
+			model.Weights[j] -= config.LearningRate * gradient[j]
+		}
+		model.Bias -= config.LearningRate * biasGradient
+	}
+	return model
+}
+
+// LambdaMART：每轮按当前得分计算各查询内候选对的 lambda 梯度（以交换两者带来的 NDCG 变化加权），
+// 用回归树拟合梯度，叶子输出取牛顿步
+func trainLambdaMART(samples []TrainingSample, config LTRConfig) (*GBDTModel, error) {
+	groups := groupByQuery(samples)
+	model := &GBDTModel{LearningRate: config.LearningRate}
+	scores := make([]float64, len(samples))
+	lambdas := make([]float64, len(samples))
+	hessians := make([]float64, len(samples))
+
+	pairs := false
+	for _, group := range groups {
+		if hasPairs(samples, group) {
+			pairs = true
+			break
+		}
+	}
+	if !pairs {
+		return nil, fmt.Errorf("%w: 没有同时包含正负样本的查询", ErrNoTrainingSamples)
+	}
+
+	indexes := make([]int, len(samples))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	for round := 0; round < config.Trees; round++ {
+		for i := range lambdas {
+			lambdas[i], hessians[i] = 0, 0
+		}
+		for _, group := range groups {
+			accumulateLambdas(samples, group, scores, lambdas, hessians)
+		}
+
+		builder := treeBuilder{samples: samples, targets: lambdas, hessians: hessians, config: config}
+		builder.build(indexes, 0)
+		tree := RegressionTree{Nodes: builder.nodes}
+		for i, sample := range samples {
+			scores[i] += config.LearningRate * tree.predict(sample.Features)
+		}
+		model.Trees = append(model.Trees, tree)
+	}
+	return model, nil
+}
+
+// 累加一个查询内的 lambda 梯度与二阶项，梯度方向为使得分增大
+func accumulateLambdas(samples []TrainingSample, group []int, scores []float64, lambdas []float64, hessians []float64) {
+	ideal := idealDCG(samples, group, len(group))
+	if ideal == 0 {
+		return
+	}
+
+	// 当前得分下的排名
+	order := make([]int, len(group))
+	copy(order, group)
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	rank := make(map[int]int, len(order))
+	for position, index := range order {
+		rank[index] = position
+	}
+
+	for _, i := range group {
+		for _, j := range group {
+			if samples[i].Label <= samples[j].Label {
+				continue
+			}
+			// 交换 i 与 j 的位置带来的 NDCG 变化
+			gainDiff := gain(samples[i].Label) - gain(samples[j].Label)
+			discountDiff := discount(rank[i]) - discount(rank[j])
+			delta := math.Abs(gainDiff*discountDiff) / ideal
+
+			rho := sigmoid(scores[j] - scores[i])
+			lambdas[i] += rho * delta
+			lambdas[j] -= rho * delta
+			hessian := rho * (1 - rho) * delta
+			hessians[i] += hessian
+			hessians[j] += hessian
+		}
+	}
+}
+
+// 回归树构建：按平方误差选择分裂，叶子输出为梯度和与二阶项和之比
+type treeBuilder struct {
+	samples  []TrainingSample
+	targets  []float64
+	hessians []float64
+	config   LTRConfig
+	nodes    []TreeNode
+}
+
+// 构建以 indexes 为样本的子树，返回子树根节点下标
+func (b *treeBuilder) build(indexes []int, depth int) int {
+	position := len(b.nodes)
+	b.nodes = append(b.nodes, TreeNode{Leaf: true, Value: b.leafValue(indexes)})
+	if depth >= b.config.MaxDepth || len(indexes) < 2*b.config.MinLeafSamples {
+		return position
+	}
+
+	feature, threshold, ok := b.bestSplit(indexes)
+	if !ok {
+		return position
+	}
+	left := make([]int, 0, len(indexes))
+	right := make([]int, 0, len(indexes))
+	for _, index := range indexes {
+		if b.samples[index].Features[feature] <= threshold {
+			left = append(left, index)
+		} else {
+			right = append(right, index)
+		}
+	}
+
+	leftNode := b.build(left, depth+1)
+	rightNode := b.build(right, depth+1)
+	b.nodes[position] = TreeNode{Feature: feature, Threshold: threshold, Left: leftNode, Right: rightNode}
+	return position
+}
+
+// 平方误差下降最大的分裂
+func (b *treeBuilder) bestSplit(indexes []int) (int, float64, bool) {
+	total := 0.0
+	for _, index := range indexes {
+		total += b.targets[index]
+	}
+	n := float64(len(indexes))
+	baseline := total * total / n
+
+	bestFeature, bestThreshold, bestGain := -1, 0.0, 1e-12
+	sorted := make([]int, len(indexes))
+	for feature := range FeatureNames {
+		copy(sorted, indexes)
+		sort.Slice(sorted, func(a, c int) bool {
+			return b.samples[sorted[a]].Features[feature] < b.samples[sorted[c]].Features[feature]
+		})
+
+		leftSum := 0.0
+		for i := 0; i < len(sorted)-1; i++ {
+			leftSum += b.targets[sorted[i]]
+			leftCount := i + 1
+			current := b.samples[sorted[i]].Features[feature]
+			next := b.samples[sorted[i+1]].Features[feature]
+			if current == next || leftCount < b.config.MinLeafSamples || len(sorted)-leftCount < b.config.MinLeafSamples {
+				continue
+			}
+			rightSum := total - leftSum
+			gain := leftSum*leftSum/float64(leftCount) + rightSum*rightSum/float64(len(sorted)-leftCount) - baseline
+			if gain > bestGain {
+				bestFeature, bestThreshold, bestGain = feature, (current+next)/2, gain
+			}
+		}
+	}
+	return bestFeature, bestThreshold, bestFeature >= 0
+}
+
+// 叶子输出：牛顿步 Σλ/Σh
+func (b *treeBuilder) leafValue(indexes []int) float64 {
+	sum, hessian := 0.0, 0.0
+	for _, index := range indexes {
+		sum += b.targets[index]
+		hessian += b.hessians[index]
+	}
+	if hessian < 1e-9 {
+		return 0
+	}
+	return sum / hessian
+}
+
+// 按查询ID分组的样本下标，组的顺序与查询首次出现的顺序一致
+func groupByQuery(samples []TrainingSample) [][]int {
+	positions := make(map[string]int)
+	groups := make([][]int, 0)
+	for i, sample := range samples {
+		position, exists := positions[sample.QueryID]
+		if !exists {
+			position = len(groups)
+			positions[sample.QueryID] = position
+			groups = append(groups, nil)
+		}
+		groups[position] = append(groups[position], i)
+	}
+	return groups
+}
+
+// 查询内是否存在相关度不同的候选
+func hasPairs(samples []TrainingSample, group []int) bool {
+	for _, index := range group[1:] {
+		if samples[index].Label != samples[group[0]].Label {
+			return true
+		}
+	}
+	return false
+}
+
+// 查询的 NDCG@k，没有正样本时不计入
+func queryNDCG(samples []TrainingSample, group []int, scores []float64, k int) (float64, bool) {
+	if k <= 0 || k > len(group) {
+		k = len(group)
+	}
+	ideal := idealDCG(samples, group, k)
+	if ideal == 0 {
+		return 0, false
+	}
+
+	order := make([]int, len(group))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	dcg := 0.0
+	for position := 0; position < k; position++ {
+		dcg += gain(samples[group[order[position]]].Label) * discount(position)
+	}
+	return dcg / ideal, true
+}
+
+// 理想排序下前 k 个位置的 DCG
+func idealDCG(samples []TrainingSample, group []int, k int) float64 {
+	labels := make([]float64, len(group))
+	for i, index := range group {
+		labels[i] = samples[index].Label
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(labels)))
+
+	dcg := 0.0
+	for position := 0; position < k && position < len(labels); position++ {
+		dcg += gain(labels[position]) * discount(position)
+	}
+	return dcg
+}
+
+func gain(label float64) float64 {
+	if label <= 0 {
+		return 0
+	}
+	return math.Pow(2, label) - 1
+}
+
+// 位置折损，position 从0开始
+func discount(position int) float64 {
+	return 1 / math.Log2(float64(position)+2)
+}
@@ -1,12 +1,15 @@
 // Package strategy 推荐排序策略模式
 // 用于推荐结果的不同排序策略
-type package strategy
+package strategy
 
 import (
 	"context"
 	"sort"
 	
+	"github.com/sirupsen/logrus"
+	
 	"github.com/guanguoyintao/luban/internal/domain"
+	"github.com/guanguoyintao/luban/internal/recommendation/diversity"
 )
 
 // RankingStrategy 排序策略接口
@@ -44,40 +47,88 @@ func (s *ScoreBasedStrategy) GetDescription() string {
 	return "基于推荐分数的排序策略"
 }
 
-// DiversityStrategy 多样性排序策略
+// ItemVectorProvider 提供物品特征向量，多样性重排据此计算候选之间的相似度
+type ItemVectorProvider interface {
+	ItemVectors(ctx context.Context, itemIDs []string) (map[string]map[string]float64, error)
+}
+
+// DiversityStrategy 多样性排序策略：按得分排序后以 MMR 或 DPP 重排，只调整顺序不丢弃推荐，
+// 没有特征向量的物品以类别独热向量计算相似度
 type DiversityStrategy struct {
-	maxSameCategory int
+	config   diversity.Config
+	provider ItemVectorProvider
+	log      *logrus.Logger
 }
 
-// NewDiversityStrategy 创建多样性排序策略
+// NewDiversityStrategy 创建多样性排序策略，默认按类别做 MMR 重排
 func NewDiversityStrategy() *DiversityStrategy {
+	return NewMMRStrategy(diversity.DefaultConfig().Lambda, nil)
+}
+
+// NewMMRStrategy 创建最大边际相关多样性排序策略，provider 为空时只按类别计算相似度
+func NewMMRStrategy(lambda float64, provider ItemVectorProvider) *DiversityStrategy {
 	return &DiversityStrategy{
-		maxSameCategory: 2, // 同一类别最多2个
+		config:   diversity.Config{Method: diversity.MethodMMR, Lambda: lambda},
+		provider: provider,
+		log:      logrus.New(),
 	}
 }
 
+// NewDPPStrategy 创建行列式点过程多样性排序策略，provider 为空时只按类别计算相似度
+func NewDPPStrategy(lambda float64, provider ItemVectorProvider) *DiversityStrategy {
+	return &DiversityStrategy{
+		config:   diversity.Config{Method: diversity.MethodDPP, Lambda: lambda},
+		provider: provider,
+		log:      logrus.New(),
+	}
+}
+
+// SetLogger 设置日志记录器
+func (s *DiversityStrategy) SetLogger(log *logrus.Logger) {
+	if log != nil {
+		s.log = log
+	}
+}
+
+// Rank 重排全部推荐，第k位的得分取第k高的原得分，后续按分数排序的策略保持重排顺序
 func (s *DiversityStrategy) Rank(ctx context.Context, recommendations []domain.Recommendation, userID string) ([]domain.Recommendation, error) {
 	if len(recommendations) <= 1 {
 		return recommendations, nil
 	}
 	
-	// 先按分数排序
-	sorted := make([]domain.Recommendation, len(recommendations))
-	copy(sorted, recommendations)
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].Score > sorted[j].Score
-	})
-	
-	// 应用多样性策略
-	result := make([]domain.Recommendation, 0)
-	categoryCount := make(map[string]int)
+	vectors := make(map[string]map[string]float64)
+	if s.provider != nil {
+		itemIDs := make([]string, len(recommendations))
+		for i, rec := range recommendations {
+			itemIDs[i] = rec.ItemID
+		}
+		// 获取特征向量失败时不影响推荐，全部按类别独热向量计算相似度
+		if provided, err := s.provider.ItemVectors(ctx, itemIDs); err != nil {
+			s.log.WithError(err).WithField("user_id", userID).Warn("获取物品特征向量失败，按类别计算多样性")
+		} else {
+			vectors = provided
+		}
+	}
 	
-	for _, rec := range sorted {
-		count := categoryCount[rec.Category]
-		if count < s.maxSameCategory {
-			result = append(result, rec)
-			categoryCount[rec.Category] = count + 1
+	candidates := make([]diversity.Candidate, len(recommendations))
+	for i, rec := range recommendations {
+		vector, exists := vectors[rec.ItemID]
+		if !exists && rec.Category != "" {
+			vector = map[string]float64{"category:" + rec.Category: 1}
 		}
+		candidates[i] = diversity.Candidate{ID: rec.ItemID, Relevance: rec.Score, Vector: vector}
+	}
+	
+	order, err := diversity.Rerank(candidates, s.config)
+	if err != nil {
+		return nil, err
+	}
+	
+	scores := diversity.PositionScores(candidates)
+	result := make([]domain.Recommendation, len(order))
+	for position, index := range order {
+		result[position] = recommendations[index]
+		result[position].Score = scores[position]
 	}
 	
 	return result, nil
@@ -88,7 +139,10 @@ func (s *DiversityStrategy) GetName() string {
 }
 
 func (s *DiversityStrategy) GetDescription() string {
-	return "基于多样性的排序策略，避免同一类别过多推荐"
+	if s.config.Method == diversity.MethodDPP {
+		return "基于行列式点过程贪心MAP的多样性重排策略，兼顾得分与物品特征向量的差异"
+	}
+	return "基于最大边际相关的多样性重排策略，兼顾得分与物品特征向量的差异"
 }
 
 // NoveltyStrategy 新颖性排序策略
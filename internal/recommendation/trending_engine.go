@@ -0,0 +1,216 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 趋势热度推荐引擎适配器
+type TrendingAdapter struct {
+	engine *algorithms.TrendingEngine
+	log    *logrus.Logger
+}
+
+// 热度窗口对应的推荐理由
+var trendingReasons = map[algorithms.TrendingWindow]string{
+	algorithms.TrendingWindowDecayed: "近期热门",
+	algorithms.TrendingWindowHour:    "最近1小时热门",
+	algorithms.TrendingWindowDay:     "24小时热门",
+	algorithms.TrendingWindowWeek:    "本周热门",
+	algorithms.TrendingWindowRising:  "热度快速上升中",
+}
+
+// 创建新的趋势热度推荐引擎适配器
+func NewTrendingAdapter(engine *algorithms.TrendingEngine, log *logrus.Logger) *TrendingAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewTrendingEngine(log)
+	}
+
+	return &TrendingAdapter{
+		engine: engine,
+		log:    log,
+	}
+}
+
+// 生成推荐，窗口取自 Context 中的 trending_window（默认按时间衰减热度），
+// 类别取自 Filters 中的 category
+func (a *TrendingAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	window := algorithms.TrendingWindowDecayed
+	if value, ok := request.Context["trending_window"].(string); ok && value != "" {
+		window = algorithms.TrendingWindow(value)
+		if _, valid := trendingReasons[window]; !valid {
+			return nil, invalidParameterError("trending_window", value)
+		}
+	}
+	category, _ := request.Filters["category"].(string)
+
+	trends := a.engine.Recommend(request.UserID, window, category, resolveLimit(request.Limit), time.Now())
+
+	// 得分按本次结果的最高热度归一化
+	results := make([]RecommendationResult, 0, len(trends))
+	for _, trend := range trends {
+		score := trend.Score / trends[0].Score
+		results = append(results, RecommendationResult{
+			ItemID:     trend.ItemID,
+			Score:      score,
+			Reason:     trendingReasons[window],
+			Algorithm:  AlgorithmPopularity,
+			Confidence: score,
+			Metadata: map[string]interface{}{
+				"trending_window": string(window),
+				"trending_score":  trend.Score,
+				"velocity":        trend.Velocity,
+				"category":        trend.Category,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmPopularity,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 批量生成推荐
+func (a *TrendingAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *TrendingAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	trend, exists := a.engine.ItemTrend(itemID, time.Now())
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+
+	if trend.Velocity >= a.engine.GetConfig().RisingRatio {
+		return fmt.Sprintf("推荐物品 %s 是因为它最近1小时的热度是平时的%.1f倍", itemID, trend.Velocity), nil
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为它是近期的热门物品", itemID), nil
+}
+
+// 更新推荐模型，写入物品类别与行为计数
+func (a *TrendingAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range update.Items {
+		a.engine.SetItemCategory(item.ItemID, item.Category)
+	}
+	for _, item := range update.ProcessedItems {
+		a.engine.SetItemCategory(item.ItemID, item.Category)
+	}
+
+	added := 0
+	for _, behavior := range update.Behaviors {
+		if weight := behaviorToRating(behavior); weight > 0 {
+			a.engine.AddEvent(behavior.UserID, behavior.ItemID, "", weight, behavior.Timestamp)
+			added++
+		}
+	}
+
+	a.log.WithField("behaviors", added).Debug("更新趋势热度")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *TrendingAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmPopularity}, nil
+}
+
+// 获取算法参数
+func (a *TrendingAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"half_life":        config.HalfLife.String(),
+		"rising_ratio":     config.RisingRatio,
+		"min_rising_count": config.MinRisingCount,
+		"smoothing":        config.Smoothing,
+	}, nil
+}
+
+// 设置算法参数
+func (a *TrendingAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "half_life":
+			text, _ := value.(string)
+			halfLife, err := time.ParseDuration(text)
+			if err != nil || halfLife <= 0 {
+				return invalidParameterError(name, value)
+			}
+			config.HalfLife = halfLife
+		case "rising_ratio", "min_rising_count", "smoothing":
+			v, ok := toFloat64(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "rising_ratio":
+				config.RisingRatio = v
+			case "min_rising_count":
+				config.MinRisingCount = v
+			default:
+				config.Smoothing = v
+			}
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	a.engine.SetConfig(&config)
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *TrendingAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+
+	categories := make([]interface{}, 0)
+	for _, trend := range a.engine.CategoryTrending(algorithms.TrendingWindowDay, 5, time.Now()) {
+		categories = append(categories, map[string]interface{}{
+			"category": trend.Category,
+			"score":    trend.Score,
+		})
+	}
+	stats["trending_categories"] = categories
+	return stats, nil
+}
+
+// 记录用户反馈，计入物品热度
+func (a *TrendingAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	weight, err := feedbackToRating(feedback)
+	if err != nil {
+		return err
+	}
+	if weight <= 0 {
+		return nil
+	}
+
+	a.engine.AddEvent(userID, itemID, "", weight, time.Now())
+	return nil
+}
+
+// 关闭推荐引擎
+func (a *TrendingAdapter) Close() error {
+	a.log.Info("关闭趋势热度推荐引擎")
+	return nil
+}
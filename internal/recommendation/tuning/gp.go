@@ -0,0 +1,198 @@
+package tuning
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	gpLengthScale   = 0.25 // RBF核在[0,1]坐标上的长度尺度
+	gpNoise         = 1e-4 // 观测噪声，同时保证核矩阵正定
+	eiExploration   = 0.01 // 期望提升的探索系数
+	eiRandomSamples = 256  // 每轮用于最大化期望提升的随机候选数
+	eiLocalSamples  = 64   // 每轮在当前最优点附近扰动的候选数
+	eiLocalScale    = 0.1  // 局部扰动的标准差
+)
+
+// 高斯过程代理模型，目标值归一化为零均值单位方差
+type gaussianProcess struct {
+	points [][]float64
+	alpha  []float64   // K^-1 y
+	chol   [][]float64 // K 的 Cholesky 分解下三角
+	mean   float64
+	std    float64
+	best   float64 // 归一化后的最优目标值
+}
+
+// 用已完成的试验拟合高斯过程，核矩阵无法分解时返回 nil
+func fitGaussianProcess(points [][]float64, values []float64) *gaussianProcess {
+	n := len(points)
+	if n == 0 {
+		return nil
+	}
+
+	mean, std := 0.0, 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(n)
+	for _, value := range values {
+		std += (value - mean) * (value - mean)
+	}
+	std = math.Sqrt(std / float64(n))
+	if std == 0 {
+		std = 1
+	}
+
+	y := make([]float64, n)
+	best := math.Inf(-1)
+	for i, value := range values {
+		y[i] = (value - mean) / std
+		best = math.Max(best, y[i])
+	}
+
+	kernel := make([][]float64, n)
+	for i := range kernel {
+		kernel[i] = make([]float64, n)
+		for j := range kernel[i] {
+			kernel[i][j] = rbf(points[i], points[j])
+		}
+		kernel[i][i] += gpNoise
+	}
+	chol, ok := cholesky(kernel)
+	if !ok {
+		return nil
+	}
+
+	return &gaussianProcess{
+		points: points,
+		alpha:  solveUpper(chol, solveLower(chol, y)),
+		chol:   chol,
+		mean:   mean,
+		std:    std,
+		best:   best,
+	}
+}
+
+// 预测归一化目标值的均值与标准差
+func (gp *gaussianProcess) predict(x []float64) (float64, float64) {
+	k := make([]float64, len(gp.points))
+	mu := 0.0
+	for i, point := range gp.points {
+		k[i] = rbf(x, point)
+		mu += k[i] * gp.alpha[i]
+	}
+
+	v := solveLower(gp.chol, k)
+	variance := 1.0
+	for _, value := range v {
+		variance -= value * value
+	}
+	return mu, math.Sqrt(math.Max(variance, 1e-12))
+}
+
+// 期望提升
+func (gp *gaussianProcess) expectedImprovement(x []float64) float64 {
+	mu, sigma := gp.predict(x)
+	improvement := mu - gp.best - eiExploration
+	z := improvement / sigma
+	return improvement*normalCDF(z) + sigma*normalPDF(z)
+}
+
+// 在随机候选与最优点附近的扰动中选出期望提升最大的坐标
+func (gp *gaussianProcess) suggest(dimensions int, incumbent []float64, rng *rand.Rand) []float64 {
+	var best []float64
+	bestScore := math.Inf(-1)
+
+	consider := func(candidate []float64) {
+		if score := gp.expectedImprovement(candidate); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	for i := 0; i < eiRandomSamples; i++ {
+		candidate := make([]float64, dimensions)
+		for d := range candidate {
+			candidate[d] = rng.Float64()
+		}
+		consider(candidate)
+	}
+	for i := 0; i < eiLocalSamples && incumbent != nil; i++ {
+		candidate := make([]float64, dimensions)
+		for d := range candidate {
+			candidate[d] = math.Max(0, math.Min(1, incumbent[d]+rng.NormFloat64()*eiLocalScale))
+		}
+		consider(candidate)
+	}
+	return best
+}
+
+// RBF核，方差为1
+func rbf(a []float64, b []float64) float64 {
+	distance := 0.0
+	for i := range a {
+		d := a[i] - b[i]
+		distance += d * d
+	}
+	return math.Exp(-distance / (2 * gpLengthScale * gpLengthScale))
+}
+
+// Cholesky 分解，矩阵非正定时返回 false
+func cholesky(matrix [][]float64) ([][]float64, bool) {
+	n := len(matrix)
+	lower := make([][]float64, n)
+	for i := range lower {
+		lower[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := matrix[i][j]
+			for k := 0; k < j; k++ {
+				sum -= lower[i][k] * lower[j][k]
+			}
+			if i == j {
+				if sum <= 0 {
+					return nil, false
+				}
+				lower[i][i] = math.Sqrt(sum)
+			} else {
+				lower[i][j] = sum / lower[j][j]
+			}
+		}
+	}
+	return lower, true
+}
+
+// 解下三角方程 L x = b
+func solveLower(lower [][]float64, b []float64) []float64 {
+	x := make([]float64, len(b))
+	for i := range b {
+		sum := b[i]
+		for k := 0; k < i; k++ {
+			sum -= lower[i][k] * x[k]
+		}
+		x[i] = sum / lower[i][i]
+	}
+	return x
+}
+
+// 解上三角方程 L^T x = b
+func solveUpper(lower [][]float64, b []float64) []float64 {
+	n := len(b)
+	x := make([]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sum := b[i]
+		for k := i + 1; k < n; k++ {
+			sum -= lower[k][i] * x[k]
+		}
+		x[i] = sum / lower[i][i]
+	}
+	return x
+}
+
+// 标准正态分布的概率密度
+func normalPDF(z float64) float64 {
+	return math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+}
+
+// 标准正态分布的累积分布
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
@@ -0,0 +1,196 @@
+// Package tuning 推荐算法超参数搜索：网格、随机与贝叶斯优化，以离线评估指标为目标
+package tuning
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+)
+
+// ParameterKind 参数类型
+type ParameterKind string
+
+const (
+	ParameterFloat  ParameterKind = "float"  // 连续参数
+	ParameterInt    ParameterKind = "int"    // 整数参数
+	ParameterChoice ParameterKind = "choice" // 离散取值参数
+)
+
+// Parameter 搜索空间中的一个参数，名称与 SetAlgorithmParameters 的参数名一致
+type Parameter struct {
+	Name    string
+	Kind    ParameterKind
+	Min     float64       // 连续与整数参数的下界
+	Max     float64       // 连续与整数参数的上界
+	Log     bool          // 是否按对数尺度搜索，要求下界大于0
+	Choices []interface{} // 离散取值
+}
+
+// 校验参数定义
+func (p Parameter) validate() error {
+	switch p.Kind {
+	case ParameterFloat, ParameterInt:
+		if p.Max < p.Min {
+			return fmt.Errorf("参数 %s 的上界小于下界", p.Name)
+		}
+		if p.Log && p.Min <= 0 {
+			return fmt.Errorf("参数 %s 按对数尺度搜索时下界必须大于0", p.Name)
+		}
+	case ParameterChoice:
+		if len(p.Choices) == 0 {
+			return fmt.Errorf("参数 %s 没有可选值", p.Name)
+		}
+	default:
+		return fmt.Errorf("参数 %s 的类型不支持: %s", p.Name, p.Kind)
+	}
+	return nil
+}
+
+// 将[0,1]内的坐标换算为参数取值
+func (p Parameter) decode(u float64) interface{} {
+	u = math.Max(0, math.Min(1, u))
+	switch p.Kind {
+	case ParameterChoice:
+		index := int(u * float64(len(p.Choices)))
+		if index == len(p.Choices) {
+			index--
+		}
+		return p.Choices[index]
+	default:
+		value := p.Min + u*(p.Max-p.Min)
+		if p.Log {
+			value = math.Exp(math.Log(p.Min) + u*(math.Log(p.Max)-math.Log(p.Min)))
+		}
+		if p.Kind == ParameterInt {
+			return int(math.Round(value))
+		}
+		return value
+	}
+}
+
+// 网格坐标：离散参数取每个取值的中点，连续参数在[0,1]上均匀取 points 个点，整数参数去掉重复取值
+func (p Parameter) grid(points int) []float64 {
+	if p.Kind == ParameterChoice {
+		coordinates := make([]float64, len(p.Choices))
+		for i := range p.Choices {
+			coordinates[i] = (float64(i) + 0.5) / float64(len(p.Choices))
+		}
+		return coordinates
+	}
+	if points < 2 || p.Max == p.Min {
+		return []float64{0.5}
+	}
+
+	coordinates := make([]float64, 0, points)
+	seen := make(map[interface{}]bool, points)
+	for i := 0; i < points; i++ {
+		u := float64(i) / float64(points-1)
+		value := p.decode(u)
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		coordinates = append(coordinates, u)
+	}
+	return coordinates
+}
+
+// Space 搜索空间
+type Space []Parameter
+
+// 校验搜索空间
+func (s Space) validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("搜索空间为空")
+	}
+	names := make(map[string]bool, len(s))
+	for _, parameter := range s {
+		if names[parameter.Name] {
+			return fmt.Errorf("参数 %s 重复", parameter.Name)
+		}
+		names[parameter.Name] = true
+		if err := parameter.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 将坐标换算为参数
+func (s Space) decode(point []float64) map[string]interface{} {
+	parameters := make(map[string]interface{}, len(s))
+	for i, parameter := range s {
+		parameters[parameter.Name] = parameter.decode(point[i])
+	}
+	return parameters
+}
+
+// 随机坐标
+func (s Space) sample(rng *rand.Rand) []float64 {
+	point := make([]float64, len(s))
+	for i := range point {
+		point[i] = rng.Float64()
+	}
+	return point
+}
+
+// 网格上的全部坐标
+func (s Space) grid(points int) [][]float64 {
+	grid := [][]float64{{}}
+	for _, parameter := range s {
+		coordinates := parameter.grid(points)
+		expanded := make([][]float64, 0, len(grid)*len(coordinates))
+		for _, prefix := range grid {
+			for _, u := range coordinates {
+				point := append(append(make([]float64, 0, len(s)), prefix...), u)
+				expanded = append(expanded, point)
+			}
+		}
+		grid = expanded
+	}
+	return grid
+}
+
+// DefaultSpace 协同过滤、内容过滤与混合过滤的默认搜索空间
+func DefaultSpace(algorithm recommendation.AlgorithmType) (Space, error) {
+	switch algorithm {
+	case recommendation.AlgorithmCollaborativeFiltering:
+		metrics := make([]interface{}, 0)
+		for _, name := range algorithms.SimilarityMetricNames() {
+			metrics = append(metrics, name)
+		}
+		return Space{
+			{Name: "mode", Kind: ParameterChoice, Choices: []interface{}{recommendation.CollaborativeModeUserBased, recommendation.CollaborativeModeItemBased}},
+			{Name: "similarity_threshold", Kind: ParameterFloat, Min: 0.01, Max: 0.5, Log: true},
+			{Name: "max_neighbors", Kind: ParameterInt, Min: 10, Max: 200, Log: true},
+			{Name: "min_common_items", Kind: ParameterInt, Min: 1, Max: 5},
+			{Name: "user_similarity", Kind: ParameterChoice, Choices: metrics},
+			{Name: "item_similarity", Kind: ParameterChoice, Choices: metrics},
+			{Name: "normalization_method", Kind: ParameterChoice, Choices: []interface{}{
+				algorithms.NormalizationNone, algorithms.NormalizationMeanCentering, algorithms.NormalizationZScore,
+			}},
+		}, nil
+	case recommendation.AlgorithmContentBasedFiltering:
+		return Space{
+			{Name: "feature_weight_threshold", Kind: ParameterFloat, Min: 0, Max: 0.3},
+			{Name: "max_features", Kind: ParameterInt, Min: 20, Max: 200, Log: true},
+			{Name: "similarity_threshold", Kind: ParameterFloat, Min: 0.01, Max: 0.5, Log: true},
+			{Name: "learning_rate", Kind: ParameterFloat, Min: 0.01, Max: 0.5, Log: true},
+			{Name: "decay_factor", Kind: ParameterFloat, Min: 0.8, Max: 1},
+		}, nil
+	case recommendation.AlgorithmHybridFiltering:
+		return Space{
+			{Name: "collaborative_weight", Kind: ParameterFloat, Min: 0, Max: 1},
+			{Name: "content_based_weight", Kind: ParameterFloat, Min: 0, Max: 1},
+			{Name: "diversity_weight", Kind: ParameterFloat, Min: 0, Max: 0.3},
+			{Name: "popularity_weight", Kind: ParameterFloat, Min: 0, Max: 0.3},
+			{Name: "recency_weight", Kind: ParameterFloat, Min: 0, Max: 0.3},
+			{Name: "diversity_lambda", Kind: ParameterFloat, Min: 0.3, Max: 1},
+		}, nil
+	default:
+		return nil, fmt.Errorf("算法没有默认搜索空间: %s", algorithm)
+	}
+}
@@ -0,0 +1,344 @@
+package tuning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/guanguoyintao/luban/internal/recommendation"
+	"github.com/guanguoyintao/luban/internal/recommendation/evaluation"
+	"github.com/guanguoyintao/luban/internal/recommendation/models"
+	"github.com/sirupsen/logrus"
+)
+
+// SearchMethod 搜索方式
+type SearchMethod string
+
+const (
+	SearchGrid     SearchMethod = "grid"     // 网格搜索
+	SearchRandom   SearchMethod = "random"   // 随机搜索
+	SearchBayesian SearchMethod = "bayesian" // 高斯过程加期望提升的贝叶斯优化
+)
+
+// 试验状态，与 ModelTrainingResult.Status 的取值一致
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+)
+
+var (
+	// ErrUnsupportedMethod 不支持的搜索方式
+	ErrUnsupportedMethod = errors.New("不支持的搜索方式")
+	// ErrUnknownObjective 未知的优化目标
+	ErrUnknownObjective = errors.New("未知的优化目标")
+	// ErrNoSuccessfulTrial 没有成功完成的试验
+	ErrNoSuccessfulTrial = errors.New("没有成功完成的试验")
+)
+
+// Config 超参数搜索配置
+type Config struct {
+	Algorithm     recommendation.AlgorithmType
+	Space         Space // 搜索空间，为空时使用算法的默认搜索空间
+	Method        SearchMethod
+	Trials        int    // 试验次数，网格搜索时为网格点数上限，0表示不限制
+	Objective     string // 最大化的离线指标
+	GridPoints    int    // 网格搜索时每个连续参数的取值个数
+	InitialTrials int    // 贝叶斯优化开始前的随机试验次数
+	Seed          int64
+	Evaluation    evaluation.Config // 每次试验的离线评估配置，评估的算法固定为 Algorithm
+}
+
+// DefaultConfig 默认搜索配置：贝叶斯优化20次试验，最大化前10个推荐的NDCG
+func DefaultConfig() Config {
+	return Config{
+		Method:        SearchBayesian,
+		Trials:        20,
+		Objective:     "ndcg",
+		GridPoints:    3,
+		InitialTrials: 5,
+		Seed:          42,
+		Evaluation:    evaluation.DefaultConfig(),
+	}
+}
+
+// Result 搜索结果：最优试验与全部试验记录
+type Result struct {
+	Best   *models.ModelTrainingResult  `json:"best"`
+	Trials []models.ModelTrainingResult `json:"trials"`
+}
+
+// Tuner 超参数搜索器
+type Tuner struct {
+	config  Config
+	factory evaluation.EngineFactory
+	log     *logrus.Logger
+}
+
+// NewTuner 创建超参数搜索器，factory 为空时每次试验使用新的默认引擎管理器
+func NewTuner(config Config, factory evaluation.EngineFactory, log *logrus.Logger) *Tuner {
+	if log == nil {
+		log = logrus.New()
+	}
+	if factory == nil {
+		factory = func() *recommendation.RecommendationEngineManager {
+			return recommendation.NewRecommendationEngineManager(log)
+		}
+	}
+
+	defaults := DefaultConfig()
+	if config.Method == "" {
+		config.Method = defaults.Method
+	}
+	if config.Objective == "" {
+		config.Objective = defaults.Objective
+	}
+	if config.GridPoints <= 0 {
+		config.GridPoints = defaults.GridPoints
+	}
+	if config.InitialTrials <= 0 {
+		config.InitialTrials = defaults.InitialTrials
+	}
+	if config.Trials <= 0 && config.Method != SearchGrid {
+		config.Trials = defaults.Trials
+	}
+
+	return &Tuner{
+		config:  config,
+		factory: factory,
+		log:     log,
+	}
+}
+
+// Run 按配置的搜索方式依次试验参数组合，每次试验用新的引擎管理器训练并离线评估
+func (t *Tuner) Run(ctx context.Context, dataset evaluation.Dataset) (*Result, error) {
+	space := t.config.Space
+	if len(space) == 0 {
+		var err error
+		if space, err = DefaultSpace(t.config.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+	if err := space.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := objectiveValue(models.AlgorithmMetrics{}, t.config.Objective); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(t.config.Seed))
+	var grid [][]float64
+	switch t.config.Method {
+	case SearchGrid:
+		grid = space.grid(t.config.GridPoints)
+		if t.config.Trials > 0 && len(grid) > t.config.Trials {
+			// 网格点多于试验次数时按随机种子抽取
+			rng.Shuffle(len(grid), func(i, j int) { grid[i], grid[j] = grid[j], grid[i] })
+			grid = grid[:t.config.Trials]
+		}
+	case SearchRandom, SearchBayesian:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMethod, t.config.Method)
+	}
+
+	trials := t.config.Trials
+	if t.config.Method == SearchGrid {
+		trials = len(grid)
+	}
+
+	runID := fmt.Sprintf("%s-%d", t.config.Algorithm, time.Now().Unix())
+	result := &Result{Trials: make([]models.ModelTrainingResult, 0, trials)}
+	var observed [][]float64
+	var values []float64
+	var incumbent []float64
+	bestValue, bestIndex := math.Inf(-1), -1
+
+	for trial := 0; trial < trials; trial++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var point []float64
+		switch {
+		case t.config.Method == SearchGrid:
+			point = grid[trial]
+		case t.config.Method == SearchBayesian && len(observed) >= t.config.InitialTrials:
+			if gp := fitGaussianProcess(observed, values); gp != nil {
+				point = gp.suggest(len(space), incumbent, rng)
+			}
+		}
+		if point == nil {
+			point = space.sample(rng)
+		}
+
+		record, value, err := t.runTrial(ctx, dataset, runID, trial, space.decode(point))
+		result.Trials = append(result.Trials, record)
+		if err != nil {
+			t.log.WithError(err).WithField("trial", trial).Warn("超参数试验失败")
+			continue
+		}
+
+		observed = append(observed, point)
+		values = append(values, value)
+		if value > bestValue {
+			bestValue = value
+			incumbent = point
+			bestIndex = len(result.Trials) - 1
+		}
+		t.log.WithFields(logrus.Fields{
+			"trial":            trial,
+			t.config.Objective: value,
+			"best":             bestValue,
+		}).Info("完成超参数试验")
+	}
+
+	if bestIndex < 0 {
+		return result, ErrNoSuccessfulTrial
+	}
+	result.Best = &result.Trials[bestIndex]
+	return result, nil
+}
+
+// 用一组参数训练并评估，返回试验记录与目标值
+func (t *Tuner) runTrial(ctx context.Context, dataset evaluation.Dataset, runID string, trial int, parameters map[string]interface{}) (models.ModelTrainingResult, float64, error) {
+	started := time.Now()
+	record := models.ModelTrainingResult{
+		ID:              fmt.Sprintf("%s-%d", runID, trial),
+		Algorithm:       string(t.config.Algorithm),
+		ModelVersion:    runID,
+		TrainingData:    fmt.Sprintf("%d behaviors, %s split", len(dataset.Behaviors), t.config.Evaluation.Split.Method),
+		ValidationData:  fmt.Sprintf("test_ratio=%g, seed=%d", t.config.Evaluation.Split.TestRatio, t.config.Evaluation.Split.Seed),
+		Metrics:         make(map[string]interface{}),
+		Hyperparameters: parameters,
+		StartTime:       started,
+		Metadata: map[string]interface{}{
+			"trial":     trial,
+			"method":    string(t.config.Method),
+			"objective": t.config.Objective,
+		},
+	}
+
+	value, err := t.evaluate(ctx, dataset, parameters, record.Metrics)
+	ended := time.Now()
+	record.EndTime = &ended
+	record.TrainingTime = ended.Sub(started).Milliseconds()
+	if err != nil {
+		record.Status = statusFailed
+		record.Metadata["error"] = err.Error()
+		return record, 0, err
+	}
+
+	record.Status = statusSuccess
+	record.Metadata["objective_value"] = value
+	return record, value, nil
+}
+
+// 在新的引擎管理器上设置参数并离线评估，指标写入 metrics
+func (t *Tuner) evaluate(ctx context.Context, dataset evaluation.Dataset, parameters map[string]interface{}, metrics map[string]interface{}) (float64, error) {
+	manager := t.factory()
+	defer manager.Close()
+	if err := manager.SetAlgorithmParameters(ctx, t.config.Algorithm, parameters); err != nil {
+		return 0, err
+	}
+
+	config := t.config.Evaluation
+	config.Algorithms = []recommendation.AlgorithmType{t.config.Algorithm}
+	evaluator := evaluation.NewEvaluator(config, func() *recommendation.RecommendationEngineManager {
+		return manager
+	}, t.log)
+	results, err := evaluator.Evaluate(ctx, dataset)
+	if err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, fmt.Errorf("算法没有评估结果: %s", t.config.Algorithm)
+	}
+
+	result := results[0]
+	for name, value := range map[string]float64{
+		"precision":     result.Precision,
+		"recall":        result.Recall,
+		"f1":            result.F1Score,
+		"ndcg":          result.NDCG,
+		"map":           result.MAP,
+		"mrr":           result.MRR,
+		"hit_rate":      result.HitRate,
+		"coverage":      result.Coverage,
+		"diversity":     result.Diversity,
+		"novelty":       result.Novelty,
+		"average_score": result.AverageScore,
+	} {
+		metrics[name] = value
+	}
+	for _, name := range []string{"k", "users", "errors", "empty_lists", "train_size", "test_size"} {
+		metrics[name] = result.Metadata[name]
+	}
+	return objectiveValue(result, t.config.Objective)
+}
+
+// 读取优化目标对应的指标
+func objectiveValue(metrics models.AlgorithmMetrics, objective string) (float64, error) {
+	switch objective {
+	case "precision":
+		return metrics.Precision, nil
+	case "recall":
+		return metrics.Recall, nil
+	case "f1":
+		return metrics.F1Score, nil
+	case "ndcg":
+		return metrics.NDCG, nil
+	case "map":
+		return metrics.MAP, nil
+	case "mrr":
+		return metrics.MRR, nil
+	case "hit_rate":
+		return metrics.HitRate, nil
+	case "coverage":
+		return metrics.Coverage, nil
+	case "diversity":
+		return metrics.Diversity, nil
+	case "novelty":
+		return metrics.Novelty, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownObjective, objective)
+	}
+}
+
+// ParameterSetter 可设置算法参数的对象，推荐引擎与推荐引擎管理器均满足
+type ParameterSetter interface {
+	SetAlgorithmParameters(ctx context.Context, algorithm recommendation.AlgorithmType, parameters map[string]interface{}) error
+}
+
+// Apply 将最优试验的参数设置到推荐引擎
+func Apply(ctx context.Context, engine ParameterSetter, best models.ModelTrainingResult) error {
+	if best.Status != statusSuccess {
+		return fmt.Errorf("试验 %s 未成功完成，不能应用其参数", best.ID)
+	}
+	return engine.SetAlgorithmParameters(ctx, recommendation.AlgorithmType(best.Algorithm), best.Hyperparameters)
+}
+
+// WriteResult 以 JSON 输出搜索结果
+func WriteResult(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("输出搜索结果失败: %w", err)
+	}
+	return nil
+}
+
+// LoadResult 读取 WriteResult 输出的搜索结果
+func LoadResult(r io.Reader) (*Result, error) {
+	var result Result
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("读取搜索结果失败: %w", err)
+	}
+	if result.Best == nil {
+		return nil, ErrNoSuccessfulTrial
+	}
+	return &result, nil
+}
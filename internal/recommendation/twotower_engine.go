@@ -0,0 +1,319 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/guanguoyintao/luban/internal/dataprocessing"
+	"github.com/guanguoyintao/luban/internal/infra/ann"
+	"github.com/guanguoyintao/luban/internal/recommendation/algorithms"
+	"github.com/sirupsen/logrus"
+)
+
+// 双塔模型推荐引擎适配器
+type TwoTowerAdapter struct {
+	engine    *algorithms.TwoTowerEngine
+	processor dataprocessing.DataProcessor
+	retrainer *retrainer
+	log       *logrus.Logger
+
+	mu             sync.RWMutex
+	embeddingIndex ann.Index // 训练后写入物品向量的召回索引
+}
+
+// 创建新的双塔模型推荐引擎适配器
+func NewTwoTowerAdapter(engine *algorithms.TwoTowerEngine, processor dataprocessing.DataProcessor, log *logrus.Logger) *TwoTowerAdapter {
+	if log == nil {
+		log = logrus.New()
+	}
+	if engine == nil {
+		engine = algorithms.NewTwoTowerEngine(log)
+	}
+	if processor == nil {
+		processor = dataprocessing.NewMemoryDataProcessor(log)
+	}
+
+	adapter := &TwoTowerAdapter{
+		engine:    engine,
+		processor: processor,
+		log:       log,
+	}
+	adapter.retrainer = newRetrainer(string(AlgorithmDeepLearning), adapter.train, log)
+	return adapter
+}
+
+// 设置向量召回索引，每次训练完成后导出物品向量
+func (a *TwoTowerAdapter) SetEmbeddingIndex(index ann.Index) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.embeddingIndex = index
+}
+
+// 生成推荐
+func (a *TwoTowerAdapter) Recommend(ctx context.Context, request RecommendationRequest) (*RecommendationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recs := a.engine.Recommend(request.UserID, resolveLimit(request.Limit))
+
+	// 双塔得分为归一化向量的余弦相似度，映射到[0,1]作为得分和置信度
+	results := make([]RecommendationResult, 0, len(recs))
+	for _, rec := range recs {
+		score := (rec.Score + 1) / 2
+		results = append(results, RecommendationResult{
+			ItemID:     rec.ItemID,
+			Score:      score,
+			Reason:     "与您的兴趣向量相近",
+			Algorithm:  AlgorithmDeepLearning,
+			Confidence: score,
+			Metadata: map[string]interface{}{
+				"embedding_similarity": rec.Score,
+			},
+		})
+	}
+
+	return &RecommendationResponse{
+		UserID:          request.UserID,
+		Recommendations: results,
+		TotalCount:      len(results),
+		Algorithm:       AlgorithmDeepLearning,
+		Metadata:        make(map[string]interface{}),
+	}, nil
+}
+
+// 为候选物品打分，供推荐流水线融合多个算法的得分
+func (a *TwoTowerAdapter) ScoreCandidates(ctx context.Context, userID string, itemIDs []string) ([]RecommendationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]RecommendationResult, 0, len(itemIDs))
+	for _, itemID := range itemIDs {
+		similarity, exists := a.engine.Predict(userID, itemID)
+		if !exists {
+			continue
+		}
+		score := (similarity + 1) / 2
+		results = append(results, RecommendationResult{
+			ItemID:     itemID,
+			Score:      score,
+			Reason:     "与您的兴趣向量相近",
+			Algorithm:  AlgorithmDeepLearning,
+			Confidence: score,
+		})
+	}
+	return results, nil
+}
+
+// 批量生成推荐
+func (a *TwoTowerAdapter) RecommendBatch(ctx context.Context, requests []RecommendationRequest) ([]*RecommendationResponse, error) {
+	return recommendBatch(ctx, a, requests)
+}
+
+// 获取推荐解释
+func (a *TwoTowerAdapter) ExplainRecommendation(ctx context.Context, userID string, itemID string) (string, error) {
+	similarity, exists := a.engine.Predict(userID, itemID)
+	if !exists {
+		return "", &RecommendationError{Message: fmt.Sprintf("无法解释物品 %s 的推荐", itemID)}
+	}
+
+	history := a.engine.GetUserInteractions(userID)
+	for _, similar := range a.engine.SimilarItems(itemID, 20) {
+		if _, seen := history[similar.ItemID]; seen {
+			return fmt.Sprintf("推荐物品 %s 是因为它与您接触过的物品 %s 相似（兴趣匹配度%.2f）", itemID, similar.ItemID, similarity), nil
+		}
+	}
+	return fmt.Sprintf("推荐物品 %s 是因为它与您的兴趣向量相近（兴趣匹配度%.2f）", itemID, similarity), nil
+}
+
+// 更新推荐模型，提取物品特征并写入行为：首次写入行为时同步训练，之后按新增行为数或间隔在后台重新训练，
+// 用户向量由行为历史实时计算，两次训练之间的新行为即时生效
+func (a *TwoTowerAdapter) UpdateModel(ctx context.Context, data interface{}) error {
+	update, err := parseModelUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range update.Items {
+		processed, err := a.processor.CleanItemData(ctx, dataprocessing.ItemData{
+			ItemID:      item.ItemID,
+			Category:    item.Category,
+			Title:       item.Title,
+			Description: item.Description,
+			Features:    item.Features,
+			Metadata:    item.Metadata,
+		})
+		if err != nil {
+			a.log.WithError(err).WithField("item_id", item.ItemID).Warn("物品特征提取失败，仅使用ID嵌入")
+			a.engine.AddItem(item.ItemID, item.Category, nil)
+			continue
+		}
+		a.engine.AddItem(processed.ItemID, processed.Category, processed.Features)
+	}
+	for _, item := range update.ProcessedItems {
+		a.engine.AddItem(item.ItemID, item.Category, item.Features)
+	}
+
+	added := 0
+	for _, behavior := range update.Behaviors {
+		if rating := behaviorToRating(behavior); rating > 0 {
+			a.engine.AddInteraction(behavior.UserID, behavior.ItemID, rating)
+			added++
+		}
+	}
+
+	a.retrainer.add(added)
+
+	a.log.WithFields(logrus.Fields{
+		"items":     len(update.Items) + len(update.ProcessedItems),
+		"behaviors": added,
+	}).Debug("更新双塔模型")
+	return nil
+}
+
+// 获取推荐算法列表
+func (a *TwoTowerAdapter) GetAvailableAlgorithms(ctx context.Context) ([]AlgorithmType, error) {
+	return []AlgorithmType{AlgorithmDeepLearning}, nil
+}
+
+// 获取算法参数
+func (a *TwoTowerAdapter) GetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType) (map[string]interface{}, error) {
+	config := a.engine.GetConfig()
+	return map[string]interface{}{
+		"embedding_dim":    config.EmbeddingDim,
+		"hidden_dim":       config.HiddenDim,
+		"output_dim":       config.OutputDim,
+		"epochs":           config.Epochs,
+		"learning_rate":    config.LearningRate,
+		"regularization":   config.Regularization,
+		"negative_samples": config.NegativeSamples,
+		"temperature":      config.Temperature,
+	}, nil
+}
+
+// 设置算法参数
+func (a *TwoTowerAdapter) SetAlgorithmParameters(ctx context.Context, algorithm AlgorithmType, parameters map[string]interface{}) error {
+	config := *a.engine.GetConfig()
+
+	for name, value := range parameters {
+		switch name {
+		case "embedding_dim", "hidden_dim", "output_dim", "epochs", "negative_samples":
+			v, ok := toInt(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			switch name {
+			case "embedding_dim":
+				config.EmbeddingDim = v
+			case "hidden_dim":
+				config.HiddenDim = v
+			case "output_dim":
+				config.OutputDim = v
+			case "epochs":
+				config.Epochs = v
+			default:
+				config.NegativeSamples = v
+			}
+		case "learning_rate", "temperature":
+			v, ok := toFloat64(value)
+			if !ok || v <= 0 {
+				return invalidParameterError(name, value)
+			}
+			if name == "learning_rate" {
+				config.LearningRate = v
+			} else {
+				config.Temperature = v
+			}
+		case "regularization":
+			v, ok := toFloat64(value)
+			if !ok || v < 0 {
+				return invalidParameterError(name, value)
+			}
+			config.Regularization = v
+		default:
+			return &RecommendationError{Message: fmt.Sprintf("未知的算法参数: %s", name)}
+		}
+	}
+
+	// 网络结构变化会清空已训练的模型，需要立即重新训练
+	current := a.engine.GetConfig()
+	retrain := config.EmbeddingDim != current.EmbeddingDim || config.HiddenDim != current.HiddenDim ||
+		config.OutputDim != current.OutputDim || config.FeatureDim != current.FeatureDim
+	a.engine.SetConfig(&config)
+	if config.OutputDim != current.OutputDim {
+		a.resetEmbeddingIndex()
+	}
+	if retrain {
+		a.retrainer.retrain()
+	}
+	return nil
+}
+
+// 获取推荐统计信息
+func (a *TwoTowerAdapter) GetRecommendationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
+	stats := a.engine.GetStats()
+	stats["user_interaction_count"] = len(a.engine.GetUserInteractions(userID))
+	return stats, nil
+}
+
+// 记录用户反馈，用户向量由行为历史实时计算，反馈计入后台重新训练的新增行为数
+func (a *TwoTowerAdapter) RecordFeedback(ctx context.Context, userID string, itemID string, feedback interface{}) error {
+	rating, err := feedbackToRating(feedback)
+	if err != nil {
+		return err
+	}
+	if rating <= 0 {
+		return nil
+	}
+
+	a.engine.AddInteraction(userID, itemID, rating)
+	a.retrainer.add(1)
+	return nil
+}
+
+// 关闭推荐引擎，等待后台训练完成
+func (a *TwoTowerAdapter) Close() error {
+	a.retrainer.wait()
+	a.log.Info("关闭双塔模型推荐引擎")
+	return nil
+}
+
+// 训练模型并导出物品向量
+func (a *TwoTowerAdapter) train() {
+	a.engine.Train()
+	a.exportEmbeddings()
+}
+
+// 物品向量维度变化后清空召回索引，否则新向量会因维度不匹配无法写入
+func (a *TwoTowerAdapter) resetEmbeddingIndex() {
+	a.mu.RLock()
+	index := a.embeddingIndex
+	a.mu.RUnlock()
+
+	if index == nil {
+		return
+	}
+	index.Reset()
+	a.log.Info("双塔输出向量维度变更，清空召回索引")
+}
+
+// 导出物品向量到召回索引
+func (a *TwoTowerAdapter) exportEmbeddings() {
+	a.mu.RLock()
+	index := a.embeddingIndex
+	a.mu.RUnlock()
+
+	if index == nil {
+		return
+	}
+
+	exported, err := a.engine.ExportEmbeddings(index)
+	if err != nil {
+		a.log.WithError(err).WithField("exported", exported).Warn("导出双塔物品向量失败")
+		return
+	}
+	a.log.WithField("exported", exported).Info("导出双塔物品向量到召回索引")
+}